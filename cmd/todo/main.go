@@ -16,7 +16,9 @@ import (
 
 // @Title 			ToDo list app
 // @Version 		1.0
-// @Description 	Todo list API service
+// @Description 	Todo list API service.
+// @Description 	Errors are returned as RFC 7807 `application/problem+json` documents (see `Problem`).
+// @Description 	The `code` field holds a stable machine-readable error code (see `ErrorCode`), `request_id` matches the `X-Request-ID` response header
 // @BasePath 		/api/v1/
 // @Host			localhost:8080
 
@@ -37,12 +39,18 @@ func main() {
 	appConf := configapplication.MustLoadConfig(confPath)
 
 	docs.SwaggerInfo.Host = fmt.Sprintf("%s:%d", appConf.Api.Hostname, appConf.Api.Port)
+	if appConf.Api.CertFile != "" {
+		docs.SwaggerInfo.Schemes = []string{"https"}
+	}
 
 	loggingApp := applogging.New(applogging.EnvMode(appConf.EnvMode))
 
 	logging := loggingApp.Logging.With("module", "main")
 
-	const apiBasePath = "/api/v1/"
+	const (
+		apiBasePath     = "/api/v1/"
+		shutdownTimeout = 5 * time.Second
+	)
 
 	mainApp := app.New(
 		loggingApp.Logging,
@@ -58,7 +66,8 @@ func main() {
 	<-quit
 	logging.Info("Shutdown Server ...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Servers get the shutdown timeout after readiness has been failing for the drain delay
+	ctx, cancel := context.WithTimeout(context.Background(), appConf.Health.DrainDelay+shutdownTimeout)
 	defer cancel()
 	mainApp.MustStop(ctx)
 
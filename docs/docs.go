@@ -19,6 +19,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/healthz": {
+            "get": {
+                "description": "Served at the server root, outside the API base path",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GeneralResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/login": {
             "post": {
                 "security": [
@@ -26,6 +46,11 @@ const docTemplate = `{
                         "BasicAuth": []
                     }
                 ],
+                "description": "Credentials are accepted as HTTP Basic auth header,\nJSON body or urlencoded form body with ` + "`" + `email` + "`" + ` and ` + "`" + `password` + "`" + ` fields.\nBasic auth header takes precedence over the body",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -33,6 +58,16 @@ const docTemplate = `{
                     "Auth"
                 ],
                 "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Credentials (JSON or form body)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/LoginRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -40,16 +75,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/LoginResponse"
                         }
                     },
+                    "400": {
+                        "description": "bad_request, validation_failed",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "unauthorized, bad_credentials",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "423": {
+                        "description": "account_locked",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "internal_error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "backend_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "backend_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     }
                 }
@@ -76,16 +135,60 @@ const docTemplate = `{
                             "$ref": "#/definitions/GeneralResponse"
                         }
                     },
+                    "400": {
+                        "description": "invalid_authorization_header",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "unauthorized, invalid_token",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "internal_error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "backend_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "backend_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Served at the server root, outside the API base path.\nFails while a dependency is not ready or the service drains before shutdown",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "dependency not ready or draining",
+                        "schema": {
+                            "$ref": "#/definitions/ReadinessResponse"
                         }
                     }
                 }
@@ -98,6 +201,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Returns a page of tasks. Pass ` + "`" + `next_cursor` + "`" + ` of the previous page as ` + "`" + `cursor` + "`" + `\nwith the same filter and sort parameters to get the next page",
                 "produces": [
                     "application/json"
                 ],
@@ -105,6 +209,50 @@ const docTemplate = `{
                     "TodoList"
                 ],
                 "summary": "Get tasks list",
+                "parameters": [
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "title",
+                            "-title",
+                            "is_done",
+                            "-is_done"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Sort field, '-' prefix for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by completion",
+                        "name": "is_done",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by case-insensitive title substring",
+                        "name": "title",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -112,16 +260,34 @@ const docTemplate = `{
                             "$ref": "#/definitions/GetTaskListResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     }
                 }
@@ -132,6 +298,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Retries carrying the same Idempotency-Key and body get the stored response instead of creating another task.\nReusing the key with another body returns 422, retrying while the first request is running returns 409",
                 "produces": [
                     "application/json"
                 ],
@@ -140,13 +307,19 @@ const docTemplate = `{
                 ],
                 "summary": "Create new task",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client generated unique request key, up to 255 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "New task fields",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/TaskItemChanges"
+                            "$ref": "#/definitions/TaskItemCreate"
                         }
                     }
                 ],
@@ -155,24 +328,132 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/GetTaskByIDResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Task version"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true if the response is a stored one"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Case-insensitive and diacritic-insensitive search. Every query word must match\na title word, whole words rank above prefixes and substrings.\nSnippet is HTML-escaped title with matched words wrapped in ` + "`" + `\u003cmark\u003e` + "`" + ` tags",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TodoList"
+                ],
+                "summary": "Search tasks by title",
+                "parameters": [
+                    {
+                        "maxLength": 256,
+                        "type": "string",
+                        "description": "Search words",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Results limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SearchTasksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     }
                 }
@@ -185,6 +466,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Response carries task version in ETag header. With matching If-None-Match header 304 is returned without body",
                 "produces": [
                     "application/json"
                 ],
@@ -199,6 +481,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ETag known to the client",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -206,47 +494,75 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/GetTaskByIDResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Task version"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Task version"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Title and is_done are required, omitted optional fields are cleared. With If-Match header the task is replaced only if its current ETag is listed, otherwise 412 is returned",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "TodoList"
                 ],
-                "summary": "Delete task by ID",
+                "summary": "Replace task by ID",
                 "parameters": [
                     {
                         "type": "integer",
@@ -254,54 +570,94 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected task ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New task fields",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TaskItemReplace"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/GetTaskByIDResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New task version"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     }
                 }
             },
-            "patch": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "With If-Match header the task is deleted only if its current ETag is listed, otherwise 412 is returned",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "TodoList"
                 ],
-                "summary": "Change task fields by ID",
+                "summary": "Delete task by ID",
                 "parameters": [
                     {
                         "type": "integer",
@@ -311,13 +667,10 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Fields changes",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/TaskItemChanges"
-                        }
+                        "type": "string",
+                        "description": "Expected task ETag",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -330,51 +683,843 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/GeneralResponse"
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "GeneralResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "$ref": "#/definitions/GeneralResponseStatus"
-                }
-            }
-        },
-        "GeneralResponseStatus": {
-            "type": "string",
-            "enum": [
-                "ok",
-                "error"
-            ],
-            "x-enum-varnames": [
-                "StatusOK",
-                "StatusError"
-            ]
-        },
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Body is TaskItemChanges for application/json, RFC 7396 merge patch of TaskItem for application/merge-patch+json\nor RFC 6902 JSON Patch for application/json-patch+json. JSON Patch supports add, replace and test on task fields\nand remove on optional ones. Due date can be cleared only with merge patch null or JSON Patch remove.\nWith If-Match header the task is changed only if its current ETag is listed, otherwise 412 is returned",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TodoList"
+                ],
+                "summary": "Change task fields by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected task ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TaskItemChanges"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetTaskByIDResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New task version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/subtasks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Subtasks are returned in creation order with their checklists",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subtasks"
+                ],
+                "summary": "Get task subtasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetSubtasksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "When subtasks auto-completion is enabled, adding an open subtask reopens a done task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subtasks"
+                ],
+                "summary": "Add subtask to task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subtask",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SubtaskCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetSubtaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/subtasks/{subtask_id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "When subtasks auto-completion is enabled, completing the last open subtask completes the task\nand reopening a subtask reopens it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subtasks"
+                ],
+                "summary": "Change subtask fields",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subtask ID",
+                        "name": "subtask_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subtask fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SubtaskChanges"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetSubtaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks:batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Operations run in parallel, so one batch must not touch a task twice.\nEvery operation gets its own status code and error.\nWith ` + "`" + `atomic` + "`" + ` set, a failure stops the batch and reverts applied operations:\ndeleted tasks are created again under new IDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TodoList"
+                ],
+                "summary": "Create, update and delete tasks in one request",
+                "parameters": [
+                    {
+                        "description": "Operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All operations succeeded",
+                        "schema": {
+                            "$ref": "#/definitions/BatchResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Some operations failed, see results",
+                        "schema": {
+                            "$ref": "#/definitions/BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Exchange refresh token for a new token pair",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "bad_request, validation_failed",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_refresh_token",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "backend_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "backend_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "BatchOperation": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2024-05-01T18:00:00Z"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_done": {
+                    "type": "boolean"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high"
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1
+                }
+            }
+        },
+        "BatchOperationResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/Problem"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "rolled_back": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "task": {
+                    "$ref": "#/definitions/TaskItem"
+                }
+            }
+        },
+        "BatchRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "atomic": {
+                    "type": "boolean"
+                },
+                "operations": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/BatchOperation"
+                    }
+                }
+            }
+        },
+        "BatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/BatchOperationResult"
+                    }
+                },
+                "rolled_back": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                }
+            }
+        },
+        "ChecklistItem": {
+            "type": "object",
+            "properties": {
+                "is_done": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1,
+                    "example": "Check expiry date"
+                }
+            }
+        },
+        "DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "READY"
+                },
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                }
+            }
+        },
+        "ErrorCode": {
+            "type": "string",
+            "enum": [
+                "bad_request",
+                "validation_failed",
+                "unauthorized",
+                "invalid_authorization_header",
+                "invalid_token",
+                "bad_credentials",
+                "account_locked",
+                "invalid_refresh_token",
+                "not_found",
+                "task_not_found",
+                "subtask_not_found",
+                "too_many_subtasks",
+                "version_mismatch",
+                "invalid_patch",
+                "unsupported_patch",
+                "patch_test_failed",
+                "unsupported_media_type",
+                "invalid_cursor",
+                "invalid_search_query",
+                "batch_too_large",
+                "invalid_operation",
+                "batch_aborted",
+                "rolled_back",
+                "invalid_idempotency_key",
+                "idempotency_key_reused",
+                "idempotency_key_in_use",
+                "invalid_request_timeout",
+                "internal_error",
+                "backend_unavailable",
+                "request_timeout",
+                "backend_timeout"
+            ],
+            "x-enum-comments": {
+                "ErrorCodeAccountLocked": "Account is locked",
+                "ErrorCodeBackendTimeout": "Backend service did not answer in time",
+                "ErrorCodeBackendUnavailable": "Backend service is unavailable, retry later",
+                "ErrorCodeBadCredentials": "Login or password is incorrect",
+                "ErrorCodeBadRequest": "Request is malformed",
+                "ErrorCodeBatchAborted": "Atomic batch operation was not executed because another one failed",
+                "ErrorCodeBatchTooLarge": "Batch has more operations than allowed",
+                "ErrorCodeIdempotencyKeyInUse": "Request with the same Idempotency-Key is still in progress",
+                "ErrorCodeIdempotencyKeyReused": "Idempotency-Key was already used with another request body",
+                "ErrorCodeInternal": "Unexpected server error",
+                "ErrorCodeInvalidAuthHeader": "Authorization header is not a bearer token",
+                "ErrorCodeInvalidCursor": "Page cursor is malformed or was issued for other list parameters",
+                "ErrorCodeInvalidIdempotencyKey": "Idempotency-Key header is too long or has non printable characters",
+                "ErrorCodeInvalidOperation": "Batch operation fields are inconsistent",
+                "ErrorCodeInvalidPatch": "Patch document is malformed",
+                "ErrorCodeInvalidRefreshToken": "Refresh token is invalid, expired or already used",
+                "ErrorCodeInvalidRequestTimeout": "Request-Timeout header is not a positive duration",
+                "ErrorCodeInvalidSearchQuery": "Search query has no words to match",
+                "ErrorCodeInvalidToken": "Bearer token is invalid, expired or revoked",
+                "ErrorCodeNotFound": "Resource is not found",
+                "ErrorCodePatchTestFailed": "JSON Patch test operation did not match the task",
+                "ErrorCodeRequestTimeout": "Request deadline was exceeded before the response was ready",
+                "ErrorCodeRolledBack": "Atomic batch operation was applied and then reverted",
+                "ErrorCodeSubtaskNotFound": "Subtask is not found",
+                "ErrorCodeTaskNotFound": "Task is not found or belongs to another user",
+                "ErrorCodeTooManySubtasks": "Task has the maximum number of subtasks",
+                "ErrorCodeUnauthorized": "Authentication is required",
+                "ErrorCodeUnsupportedMediaType": "Request body content type is not supported",
+                "ErrorCodeUnsupportedPatch": "Patch operation, path or change is not supported",
+                "ErrorCodeValidationFailed": "Request fields failed validation, see errors",
+                "ErrorCodeVersionMismatch": "Task was changed since the ETag given in If-Match was issued"
+            },
+            "x-enum-varnames": [
+                "ErrorCodeBadRequest",
+                "ErrorCodeValidationFailed",
+                "ErrorCodeUnauthorized",
+                "ErrorCodeInvalidAuthHeader",
+                "ErrorCodeInvalidToken",
+                "ErrorCodeBadCredentials",
+                "ErrorCodeAccountLocked",
+                "ErrorCodeInvalidRefreshToken",
+                "ErrorCodeNotFound",
+                "ErrorCodeTaskNotFound",
+                "ErrorCodeSubtaskNotFound",
+                "ErrorCodeTooManySubtasks",
+                "ErrorCodeVersionMismatch",
+                "ErrorCodeInvalidPatch",
+                "ErrorCodeUnsupportedPatch",
+                "ErrorCodePatchTestFailed",
+                "ErrorCodeUnsupportedMediaType",
+                "ErrorCodeInvalidCursor",
+                "ErrorCodeInvalidSearchQuery",
+                "ErrorCodeBatchTooLarge",
+                "ErrorCodeInvalidOperation",
+                "ErrorCodeBatchAborted",
+                "ErrorCodeRolledBack",
+                "ErrorCodeInvalidIdempotencyKey",
+                "ErrorCodeIdempotencyKeyReused",
+                "ErrorCodeIdempotencyKeyInUse",
+                "ErrorCodeInvalidRequestTimeout",
+                "ErrorCodeInternal",
+                "ErrorCodeBackendUnavailable",
+                "ErrorCodeRequestTimeout",
+                "ErrorCodeBackendTimeout"
+            ]
+        },
+        "FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "field is required"
+                }
+            }
+        },
+        "GeneralResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                }
+            }
+        },
+        "GeneralResponseStatus": {
+            "type": "string",
+            "enum": [
+                "ok",
+                "error"
+            ],
+            "x-enum-varnames": [
+                "StatusOK",
+                "StatusError"
+            ]
+        },
+        "GetSubtaskResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                },
+                "subtask": {
+                    "$ref": "#/definitions/Subtask"
+                }
+            }
+        },
+        "GetSubtasksResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                },
+                "subtasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Subtask"
+                    }
+                }
+            }
+        },
         "GetTaskByIDResponse": {
             "type": "object",
             "properties": {
@@ -389,6 +1534,10 @@ const docTemplate = `{
         "GetTaskListResponse": {
             "type": "object",
             "properties": {
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJxIjoiNGYxYyIsImlkIjo0Mn0"
+                },
                 "status": {
                     "$ref": "#/definitions/GeneralResponseStatus"
                 },
@@ -397,42 +1546,403 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/TaskItem"
                     }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1024
+                }
+            }
+        },
+        "LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254,
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "secret"
                 }
             }
         },
         "LoginResponse": {
             "type": "object",
             "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_expires_in": {
+                    "type": "integer",
+                    "example": 2592000
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
                 "status": {
                     "$ref": "#/definitions/GeneralResponseStatus"
                 },
                 "token": {
                     "type": "string"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ErrorCode"
+                        }
+                    ],
+                    "example": "task_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "task 42 not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/tasks/42"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "4b0c2e4f9a1d3c7e"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:todoapi:problem:task_not_found"
+                }
+            }
+        },
+        "ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/DependencyStatus"
+                    }
+                },
+                "draining": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                }
+            }
+        },
+        "RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "SearchTasksResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/TaskSearchResult"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/GeneralResponseStatus"
+                }
+            }
+        },
+        "Subtask": {
+            "type": "object",
+            "properties": {
+                "checklist": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ChecklistItem"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_done": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "SubtaskChanges": {
+            "type": "object",
+            "properties": {
+                "checklist": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/ChecklistItem"
+                    }
+                },
+                "is_done": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1,
+                    "example": "Buy milk"
+                }
+            }
+        },
+        "SubtaskCreate": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "checklist": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/ChecklistItem"
+                    }
+                },
+                "is_done": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1,
+                    "example": "Buy milk"
                 }
             }
         },
         "TaskItem": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2024-05-01T18:00:00Z"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "is_done": {
                     "type": "boolean"
                 },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high"
+                    ],
+                    "example": "normal"
+                },
+                "subtasks": {
+                    "$ref": "#/definitions/TaskProgress"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
         "TaskItemChanges": {
             "type": "object",
             "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2024-05-01T18:00:00Z"
+                },
                 "is_done": {
                     "type": "boolean"
                 },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high"
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1,
+                    "example": "Buy milk"
+                }
+            }
+        },
+        "TaskItemCreate": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2024-05-01T18:00:00Z"
+                },
+                "is_done": {
+                    "type": "boolean"
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high"
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1,
+                    "example": "Buy milk"
+                }
+            }
+        },
+        "TaskItemReplace": {
+            "type": "object",
+            "required": [
+                "is_done",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2024-05-01T18:00:00Z"
+                },
+                "is_done": {
+                    "type": "boolean"
+                },
+                "priority": {
+                    "type": "string",
+                    "enum": [
+                        "low",
+                        "normal",
+                        "high"
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 1,
+                    "example": "Buy milk"
+                }
+            }
+        },
+        "TaskProgress": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "TaskSearchResult": {
+            "type": "object",
+            "properties": {
+                "score": {
+                    "type": "number",
+                    "example": 1.1
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "Buy \u003cmark\u003emilk\u003c/mark\u003e"
+                },
+                "task": {
+                    "$ref": "#/definitions/TaskItem"
                 }
             }
         }
@@ -456,7 +1966,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api/v1/",
 	Schemes:          []string{},
 	Title:            "ToDo list app",
-	Description:      "Todo list API service",
+	Description:      "Todo list API service.\nErrors are returned as RFC 7807 `application/problem+json` documents (see `Problem`).\nThe `code` field holds a stable machine-readable error code (see `ErrorCode`), `request_id` matches the `X-Request-ID` response header",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
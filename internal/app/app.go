@@ -6,18 +6,34 @@ import (
 	"errors"
 	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
 	"log/slog"
+	"time"
 	"todoapiservice/internal/app/configapplication"
 	"todoapiservice/internal/app/grpcapplication"
 	"todoapiservice/internal/app/httpapplication"
+	"todoapiservice/internal/app/metricsapplication"
 	"todoapiservice/internal/http/handlers/authhandler"
+	"todoapiservice/internal/http/handlers/healthhandler"
+	"todoapiservice/internal/http/handlers/subtaskshandler"
 	"todoapiservice/internal/http/handlers/todoitemshandler"
+	"todoapiservice/internal/http/middlewares/deadlinemiddleware"
 	"todoapiservice/internal/http/middlewares/jwtmiddleware"
+	"todoapiservice/internal/http/middlewares/metricsmiddleware"
+	"todoapiservice/internal/lib/metrics"
 	"todoapiservice/internal/services/authprovider"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/idempotency"
+	"todoapiservice/internal/services/jwtverifier"
+	"todoapiservice/internal/services/readiness"
+	"todoapiservice/internal/services/taskdetails"
+	"todoapiservice/internal/services/todobatch"
+	"todoapiservice/internal/services/todopatch"
 	"todoapiservice/internal/services/todoprovider"
 )
 
 var (
 	ErrAppFailedStopServices = errors.New("app failed to stop services")
+	ErrAppNoCursorSecret     = errors.New("tasks cursor secret is not configured")
+	ErrAppNoRefreshSecret    = errors.New("auth refresh token secret is not configured")
 )
 
 type IGRPCClient interface {
@@ -30,11 +46,22 @@ type IHTTPServer interface {
 	Stop(ctx context.Context) error
 }
 
+type IAuthService interface {
+	Login(ctx context.Context, email string, password string) (*coredto.User, error)
+	Logout(ctx context.Context, user coredto.User) error
+	CheckSecret(ctx context.Context, secret string) (*coredto.User, error)
+	IssueTokens(ctx context.Context, user coredto.User) (*coredto.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*coredto.TokenPair, error)
+}
+
 type MainApp struct {
 	logger      *slog.Logger
 	confApp     *configapplication.AppConfig
 	grpcApp     IGRPCClient
 	httpApp     IHTTPServer
+	metricsApp  IHTTPServer
+	metrics     *metrics.Metrics
+	readiness   *readiness.Checker
 	apiBasePath string
 }
 
@@ -44,14 +71,27 @@ func New(
 	apiBasePath string,
 ) *MainApp {
 
+	appMetrics := metrics.New()
+
 	gRPCApp := grpcapplication.New(
 		logger,
+		appConf.Grpc,
+		appMetrics,
+	)
+
+	readinessChecker := readiness.New(
+		logger,
+		appConf.Health,
+		readiness.Dependency{Name: "grpc_connection", Check: gRPCApp.CheckConnection},
+		readiness.Dependency{Name: "todo_backend", Check: gRPCApp.CheckBackend},
 	)
 
 	return &MainApp{
 		logger:      logger,
 		confApp:     appConf,
 		grpcApp:     gRPCApp,
+		metrics:     appMetrics,
+		readiness:   readinessChecker,
 		apiBasePath: apiBasePath,
 	}
 }
@@ -64,32 +104,102 @@ func (rApp *MainApp) MustRun() {
 		panic(err)
 	}
 
-	authProvider := authprovider.New(rApp.logger, *client)
-	todoProvider := todoprovider.New(rApp.logger, *client)
+	// Refresh tokens must stay valid across restarts and replicas
+	if rApp.confApp.Auth.Tokens.RefreshSecret == "" {
+		panic(ErrAppNoRefreshSecret)
+	}
+	backendAuthProvider := authprovider.New(rApp.logger, *client, rApp.confApp.Auth.Tokens)
+
+	var authProvider IAuthService = backendAuthProvider
+	if rApp.confApp.Auth.Cache.Size > 0 {
+		cachedAuthProvider := authprovider.NewCached(backendAuthProvider, rApp.confApp.Auth.Cache)
+		rApp.metrics.RegisterAuthCache(func() metrics.AuthCacheStats {
+			return metrics.AuthCacheStats(cachedAuthProvider.Stats())
+		})
+		authProvider = cachedAuthProvider
+	}
+
+	// Cursors must stay valid across restarts and replicas
+	if rApp.confApp.Tasks.CursorSecret == "" {
+		panic(ErrAppNoCursorSecret)
+	}
+	todoProvider := todoprovider.New(rApp.logger, *client, taskdetails.NewMemoryStore(), rApp.confApp.Tasks)
+
+	secretChecker, err := jwtverifier.NewSecretChecker(rApp.logger, rApp.confApp.Auth, authProvider)
+	if err != nil {
+		panic(err)
+	}
+
+	var authenticator authhandler.IAuthenticator = authProvider
+	if verifier, ok := secretChecker.(*jwtverifier.Verifier); ok {
+		// Locally verified tokens skip the backend blacklist, so logout revokes them in the gateway
+		authenticator = jwtverifier.NewRevokingAuthenticator(authProvider, verifier)
+	}
+
+	authHandle := authhandler.New(rApp.logger, authenticator, authProvider)
+	authMiddleware := jwtmiddleware.New(rApp.logger, secretChecker)
+	batchExecutor := todobatch.New(
+		rApp.logger,
+		todoProvider,
+		todoProvider,
+		todoProvider,
+		todoProvider,
+		todoProvider,
+		rApp.confApp.Tasks,
+	)
 
-	authHandle := authhandler.New(rApp.logger, authProvider)
-	authMiddleware := jwtmiddleware.New(rApp.logger, authProvider)
 	todoItemHandler := todoitemshandler.New(
 		rApp.logger,
 		todoProvider,
 		todoProvider,
 		todoProvider,
 		todoProvider,
+		todopatch.New(todoProvider, todoProvider),
+		todoProvider,
+		batchExecutor,
+		idempotency.NewMemoryStore(rApp.confApp.Tasks.Idempotency),
+	)
+
+	subtaskHandler := subtaskshandler.New(
+		rApp.logger,
+		todoProvider,
+		todoProvider,
+		todoProvider,
 	)
 
 	httpApp := httpapplication.New(
 		rApp.logger,
 		rApp.apiBasePath,
+		rApp.confApp.Api.ApiTLSConfig,
+		todoItemHandler,
+		todoItemHandler,
 		todoItemHandler,
 		todoItemHandler,
 		todoItemHandler,
 		todoItemHandler,
+		subtaskHandler,
 		authHandle,
+		healthhandler.New(rApp.readiness),
+		metricsmiddleware.New(rApp.metrics),
+		deadlinemiddleware.New(rApp.confApp.Api.Timeout),
 		authMiddleware,
 	)
 
 	rApp.httpApp = httpApp
 
+	metricsConf := rApp.confApp.Metrics
+	if metricsConf.Port == 0 {
+		httpApp.Handle(metricsConf.Path, rApp.metrics.Handler())
+	} else {
+		metricsApp := metricsapplication.New(rApp.logger, metricsConf.Path, rApp.metrics.Handler())
+		rApp.metricsApp = metricsApp
+
+		go func() {
+			// Run logs the error itself: losing metrics must not take the API down
+			_ = metricsApp.Run(metricsConf.Hostname, metricsConf.Port)
+		}()
+	}
+
 	err = httpApp.Run(rApp.confApp.Api.Hostname, rApp.confApp.Api.Port)
 	if err != nil {
 		panic(err)
@@ -97,10 +207,26 @@ func (rApp *MainApp) MustRun() {
 }
 
 func (rApp *MainApp) MustStop(ctx context.Context) {
+	// Failing readiness lets load balancers drain traffic before the server stops
+	rApp.readiness.SetDraining()
+	if delay := rApp.confApp.Health.DrainDelay; delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
 	errHttp := rApp.httpApp.Stop(ctx)
 	errGrpc := rApp.grpcApp.Stop()
 
-	if errHttp != nil || errGrpc != nil {
-		panic(errors.Join(ErrAppFailedStopServices, errHttp, errGrpc))
+	var errMetrics error
+	if rApp.metricsApp != nil {
+		errMetrics = rApp.metricsApp.Stop(ctx)
+	}
+
+	if errHttp != nil || errGrpc != nil || errMetrics != nil {
+		panic(errors.Join(ErrAppFailedStopServices, errHttp, errGrpc, errMetrics))
 	}
 }
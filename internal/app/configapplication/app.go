@@ -5,20 +5,162 @@ import (
 	"errors"
 	"github.com/ilyakaznacheev/cleanenv"
 	"os"
+	"time"
 )
 
+type GrpcServiceAuthConfig struct {
+	ClientID      string        `yaml:"client-id" env-description:"" env:"CLIENT_ID"`
+	ClientSecret  string        `yaml:"client-secret" env-description:"" env:"CLIENT_SECRET"`
+	TokenFile     string        `yaml:"token-file" env-description:"" env:"TOKEN_FILE"`
+	TokenTTL      time.Duration `yaml:"token-ttl" env-description:"" env:"TOKEN_TTL" env-default:"15m"`
+	RefreshBefore time.Duration `yaml:"refresh-before" env-description:"" env:"REFRESH_BEFORE" env-default:"1m"`
+	FetchTimeout  time.Duration `yaml:"fetch-timeout" env-description:"" env:"FETCH_TIMEOUT" env-default:"5s"`
+
+	FailureBackoff    time.Duration `yaml:"failure-backoff" env-description:"" env:"FAILURE_BACKOFF" env-default:"1s"`
+	MaxFailureBackoff time.Duration `yaml:"max-failure-backoff" env-description:"" env:"MAX_FAILURE_BACKOFF" env-default:"30s"`
+}
+
+type GrpcTLSConfig struct {
+	TLSMode        string        `yaml:"tls-mode" env-description:"insecure, tls or mtls" env:"TLS_MODE" env-default:"insecure"`
+	CAFile         string        `yaml:"ca-file" env-description:"" env:"CA_FILE"`
+	CertFile       string        `yaml:"cert-file" env-description:"" env:"CERT_FILE"`
+	KeyFile        string        `yaml:"key-file" env-description:"" env:"KEY_FILE"`
+	ServerName     string        `yaml:"server-name" env-description:"" env:"SERVER_NAME"`
+	ReloadInterval time.Duration `yaml:"tls-reload-interval" env-description:"" env:"TLS_RELOAD_INTERVAL" env-default:"10s"`
+}
+
+type GrpcRetryConfig struct {
+	MaxAttempts    int           `yaml:"max-attempts" env-description:"1 disables retries" env:"MAX_ATTEMPTS" env-default:"3"`
+	InitialBackoff time.Duration `yaml:"initial-backoff" env-description:"" env:"INITIAL_BACKOFF" env-default:"100ms"`
+	MaxBackoff     time.Duration `yaml:"max-backoff" env-description:"" env:"MAX_BACKOFF" env-default:"2s"`
+	Methods        []string      `yaml:"methods" env-description:"idempotent methods to retry" env:"METHODS" env-default:"GetTaskByID,ListTasks,CheckSecret"`
+}
+
+type GrpcBreakerConfig struct {
+	FailureThreshold int           `yaml:"failure-threshold" env-description:"0 disables circuit breaker" env:"FAILURE_THRESHOLD" env-default:"5"`
+	OpenTimeout      time.Duration `yaml:"open-timeout" env-description:"" env:"OPEN_TIMEOUT" env-default:"10s"`
+}
+
+type GrpcBalancingConfig struct {
+	Policy             string        `yaml:"policy" env-description:"round_robin or pick_first" env:"POLICY" env-default:"pick_first"`
+	HealthCheck        bool          `yaml:"health-check" env-description:"" env:"HEALTH_CHECK" env-default:"true"`
+	HealthService      string        `yaml:"health-service" env-description:"" env:"HEALTH_SERVICE"`
+	EjectionFailures   int           `yaml:"ejection-failures" env-description:"0 disables outlier ejection" env:"EJECTION_FAILURES" env-default:"5"`
+	EjectionTime       time.Duration `yaml:"ejection-time" env-description:"" env:"EJECTION_TIME" env-default:"30s"`
+	MaxEjectionPercent int           `yaml:"max-ejection-percent" env-description:"" env:"MAX_EJECTION_PERCENT" env-default:"50"`
+}
+
+type GrpcConfig struct {
+	Hostname string `yaml:"hostname" env-description:"" env:"HOSTNAME" env-default:"localhost"`
+	Port     int    `yaml:"port" env-description:"" env:"PORT" env-default:"9090"`
+
+	Targets   []string            `yaml:"targets" env-description:"backend addresses, hostname and port are used if empty" env:"TARGETS"`
+	Balancing GrpcBalancingConfig `yaml:"balancing" env-prefix:"BALANCING_"`
+
+	GrpcTLSConfig `yaml:",inline"`
+
+	ServiceAuth GrpcServiceAuthConfig `yaml:"service-auth" env-prefix:"SERVICE_AUTH_"`
+
+	Timeout        time.Duration            `yaml:"timeout" env-description:"0 disables default call deadline" env:"TIMEOUT" env-default:"5s"`
+	MethodTimeouts map[string]time.Duration `yaml:"method-timeouts" env-description:"" env:"METHOD_TIMEOUTS"`
+
+	Retry GrpcRetryConfig `yaml:"retry" env-prefix:"RETRY_"`
+
+	Breaker GrpcBreakerConfig `yaml:"breaker" env-prefix:"BREAKER_"`
+}
+
+type ApiTLSConfig struct {
+	CertFile       string        `yaml:"cert-file" env-description:"" env:"CERT_FILE"`
+	KeyFile        string        `yaml:"key-file" env-description:"" env:"KEY_FILE"`
+	ClientCAFile   string        `yaml:"client-ca-file" env-description:"" env:"CLIENT_CA_FILE"`
+	ReloadInterval time.Duration `yaml:"tls-reload-interval" env-description:"" env:"TLS_RELOAD_INTERVAL" env-default:"10s"`
+}
+
+type ApiTimeoutConfig struct {
+	Default time.Duration            `yaml:"default" env-description:"0 disables default request deadline" env:"DEFAULT" env-default:"10s"`
+	Max     time.Duration            `yaml:"max" env-description:"client Request-Timeout cap, 0 ignores the header" env:"MAX" env-default:"30s"`
+	Routes  map[string]time.Duration `yaml:"routes" env-description:"" env:"ROUTES"`
+}
+
+type ApiConfig struct {
+	Hostname string `yaml:"hostname" env-description:"" env:"HOSTNAME" env-default:"localhost"`
+	Port     int    `yaml:"port" env-description:"" env:"PORT" env-default:"8080"`
+
+	ApiTLSConfig `yaml:",inline"`
+
+	Timeout ApiTimeoutConfig `yaml:"timeout" env-prefix:"TIMEOUT_"`
+}
+
+type AuthCacheConfig struct {
+	Size        int           `yaml:"size" env-description:"0 disables cache" env:"SIZE" env-default:"0"`
+	TTL         time.Duration `yaml:"ttl" env-description:"" env:"TTL" env-default:"30s"`
+	NegativeTTL time.Duration `yaml:"negative-ttl" env-description:"" env:"NEGATIVE_TTL" env-default:"5s"`
+}
+
+type AuthTokensConfig struct {
+	AccessTokenTTL  time.Duration `yaml:"access-token-ttl" env-description:"" env:"ACCESS_TTL" env-default:"15m"`
+	RefreshTokenTTL time.Duration `yaml:"refresh-token-ttl" env-description:"" env:"REFRESH_TTL" env-default:"720h"`
+	RefreshSecret   string        `yaml:"refresh-secret" env-description:"required" env:"REFRESH_SECRET"`
+}
+
+type AuthConfig struct {
+	VerifyMode    string        `yaml:"verify-mode" env-description:"remote, local or local-fallback" env:"VERIFY_MODE" env-default:"remote"`
+	Algorithms    []string      `yaml:"algorithms" env-description:"" env:"ALGORITHMS" env-default:"HS256,RS256,EdDSA"`
+	HMACSecret    string        `yaml:"hmac-secret" env-description:"" env:"HMAC_SECRET"`
+	PublicKeyFile string        `yaml:"public-key-file" env-description:"" env:"PUBLIC_KEY_FILE"`
+	JWKSFile      string        `yaml:"jwks-file" env-description:"" env:"JWKS_FILE"`
+	Issuer        string        `yaml:"issuer" env-description:"" env:"ISSUER"`
+	Audience      string        `yaml:"audience" env-description:"" env:"AUDIENCE"`
+	Leeway        time.Duration `yaml:"leeway" env-description:"" env:"LEEWAY" env-default:"30s"`
+	UserIDClaim   string        `yaml:"user-id-claim" env-description:"" env:"USER_ID_CLAIM" env-default:"sub"`
+	EmailClaim    string        `yaml:"email-claim" env-description:"" env:"EMAIL_CLAIM" env-default:"email"`
+
+	Cache AuthCacheConfig `yaml:"cache" env-prefix:"CACHE_"`
+
+	Tokens AuthTokensConfig `yaml:"tokens" env-prefix:"TOKENS_"`
+}
+
+type IdempotencyConfig struct {
+	TTL     time.Duration `yaml:"ttl" env-description:"" env:"TTL" env-default:"24h"`
+	MaxKeys int           `yaml:"max-keys" env-description:"" env:"MAX_KEYS" env-default:"10000"`
+}
+
+type TasksConfig struct {
+	BatchMaxOperations   int  `yaml:"batch-max-operations" env-description:"" env:"BATCH_MAX_OPERATIONS" env-default:"100"`
+	BatchConcurrency     int  `yaml:"batch-concurrency" env-description:"" env:"BATCH_CONCURRENCY" env-default:"8"`
+	SubtasksAutoComplete bool `yaml:"subtasks-auto-complete" env-description:"" env:"SUBTASKS_AUTO_COMPLETE" env-default:"false"`
+
+	CursorSecret string `yaml:"cursor-secret" env-description:"required" env:"CURSOR_SECRET"`
+
+	Idempotency IdempotencyConfig `yaml:"idempotency" env-prefix:"IDEMPOTENCY_"`
+}
+
+type HealthConfig struct {
+	CacheTTL     time.Duration `yaml:"cache-ttl" env-description:"0 probes on every request" env:"CACHE_TTL" env-default:"2s"`
+	ProbeTimeout time.Duration `yaml:"probe-timeout" env-description:"" env:"PROBE_TIMEOUT" env-default:"1s"`
+	DrainDelay   time.Duration `yaml:"drain-delay" env-description:"longer than load balancer readiness probe period" env:"DRAIN_DELAY" env-default:"10s"`
+}
+
+type MetricsConfig struct {
+	Path     string `yaml:"path" env-description:"" env:"PATH" env-default:"/metrics"`
+	Hostname string `yaml:"hostname" env-description:"empty listens on all interfaces" env:"HOSTNAME" env-default:""`
+	Port     int    `yaml:"port" env-description:"0 serves metrics on the API port" env:"PORT" env-default:"9100"`
+}
+
 type AppConfig struct {
 	EnvMode string `yaml:"env-mode" env-description:"" env:"ENV_MODE" env-default:"prod"`
 
-	Grpc struct {
-		Hostname string `yaml:"hostname" env-description:"" env:"HOSTNAME" env-default:"localhost"`
-		Port     int    `yaml:"port" env-description:"" env:"PORT" env-default:"9090"`
-	} `yaml:"grpc-client" env-prefix:"GRPC_"`
+	Grpc GrpcConfig `yaml:"grpc-client" env-prefix:"GRPC_"`
+
+	Api ApiConfig `yaml:"api" env-prefix:"API_"`
+
+	Auth AuthConfig `yaml:"auth" env-prefix:"AUTH_"`
+
+	Tasks TasksConfig `yaml:"tasks" env-prefix:"TASKS_"`
+
+	Health HealthConfig `yaml:"health" env-prefix:"HEALTH_"`
 
-	Api struct {
-		Hostname string `yaml:"hostname" env-description:"" env:"HOSTNAME" env-default:"localhost"`
-		Port     int    `yaml:"port" env-description:"" env:"PORT" env-default:"8080"`
-	} `yaml:"api" env-prefix:"API_"`
+	Metrics MetricsConfig `yaml:"metrics" env-prefix:"METRICS_"`
 }
 
 // MustLoadConfig Returns app configuration. Panic if failed
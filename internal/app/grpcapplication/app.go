@@ -3,14 +3,16 @@ package grpcapplication
 import (
 	"context"
 	"errors"
-	"fmt"
 	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"log/slog"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/lib/authcontext"
 )
 
 var (
@@ -19,50 +21,149 @@ var (
 	ErrGRPCNotRunning = errors.New("gRPC server is not running")
 )
 
+type IMetricsRecorder interface {
+	ObserveGRPCCall(method string, code string, duration time.Duration)
+}
+
 type GRPCApplication struct {
-	logger *slog.Logger
-	conn   *grpc.ClientConn
+	logger  *slog.Logger
+	conf    configapplication.GrpcConfig
+	metrics IMetricsRecorder
+	conn    *grpc.ClientConn
+	tokens  *TokenSource
+	breaker *circuitBreaker
+}
+
+const (
+	authorizationMetadataKey = "authorization"
+	// ServiceAuthorizationMetadataKey carries the gateway service credential
+	ServiceAuthorizationMetadataKey = "x-service-authorization"
+	// ServiceCredentialRejectedReason is the ErrorInfo reason the backend attaches
+	// when it rejects the gateway service credential
+	ServiceCredentialRejectedReason = "SERVICE_CREDENTIAL_REJECTED"
+)
+
+// newUnaryInterceptor Returns interceptor forwarding the caller bearer token from the request context to the backend.
+// If tokens is not nil the gateway service credential is attached too and the call is retried once
+// with a refreshed credential when the backend rejects it
+func newUnaryInterceptor(tokens func() *TokenSource) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string, req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if token, ok := authcontext.BearerToken(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, authorizationMetadataKey, "Bearer "+token)
+		}
+
+		tokenSource := tokens()
+		if tokenSource == nil || isServiceAuthSkipped(ctx) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		credential, err := tokenSource.Token(ctx)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		err = invoker(withServiceToken(ctx, credential), method, req, reply, cc, opts...)
+
+		// Refresh only when the service credential itself was rejected: user token check failures
+		// must not reach the backend login endpoint
+		if isServiceCredentialRejected(err) {
+			refreshed, refreshErr := tokenSource.Refresh(ctx, credential)
+			if refreshErr != nil {
+				return err
+			}
+			err = invoker(withServiceToken(ctx, refreshed), method, req, reply, cc, opts...)
+		}
+
+		return err
+	}
 }
 
-func unaryInterceptor(
-	ctx context.Context,
-	method string, req, reply any,
-	cc *grpc.ClientConn,
-	invoker grpc.UnaryInvoker,
-	opts ...grpc.CallOption,
-) error {
-	//TODO: unaryInterceptor not implement
-	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+"1234")
-	err := invoker(authCtx, method, req, reply, cc, opts...)
-
-	// If we got an unauthenticated response from the gRPC service, refresh the token
-	if status.Code(err) == codes.Unauthenticated {
-		// if err = jwt.refreshBearerToken(); err != nil {
-		//     return err
-		// }
-		updatedAuthCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+"1234")
-		err = invoker(updatedAuthCtx, method, req, reply, cc, opts...)
+// isServiceCredentialRejected Reports whether err is codes.Unauthenticated carrying
+// the ServiceCredentialRejectedReason error info
+func isServiceCredentialRejected(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st == nil || st.Code() != codes.Unauthenticated {
+		return false
 	}
 
-	return err
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == ServiceCredentialRejectedReason {
+			return true
+		}
+	}
+	return false
 }
 
-func New(logger *slog.Logger) *GRPCApplication {
-	log := logger.With("module", "grpcapplication")
-	log.With("method", "New").Error("gRPC unaryInterceptor not implement!")
+func withServiceToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, ServiceAuthorizationMetadataKey, "Bearer "+token)
+}
+
+// New Returns gRPC client application. Calls are not recorded if metrics is nil
+func New(logger *slog.Logger, conf configapplication.GrpcConfig, metrics IMetricsRecorder) *GRPCApplication {
 	return &GRPCApplication{
-		logger: log,
+		logger:  logger.With("module", "grpcapplication"),
+		conf:    conf,
+		metrics: metrics,
+	}
+}
+
+// interceptors Returns client interceptors, outermost first: metrics and the circuit breaker see
+// a call once after all retries, every retry attempt gets its own deadline and credentials
+func (app *GRPCApplication) interceptors() []grpc.UnaryClientInterceptor {
+	var interceptors []grpc.UnaryClientInterceptor
+
+	if app.metrics != nil {
+		interceptors = append(interceptors, newMetricsInterceptor(app.metrics))
+	}
+
+	if app.conf.Breaker.FailureThreshold > 0 {
+		app.breaker = newCircuitBreaker(app.logger, app.conf.Breaker)
+		interceptors = append(interceptors, app.breaker.interceptor())
 	}
+
+	return append(interceptors,
+		newRetryInterceptor(app.conf.Retry),
+		newDeadlineInterceptor(app.conf.Timeout, app.conf.MethodTimeouts),
+		newUnaryInterceptor(func() *TokenSource {
+			return app.tokens
+		}),
+	)
 }
 
 func (app *GRPCApplication) Start(host string, port int) (*todoprotobufv1.ToDoServiceClient, error) {
 	log := app.logger.With("method", "Start")
 	var opts []grpc.DialOption
 
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	opts = append(opts, grpc.WithUnaryInterceptor(unaryInterceptor))
+	target, resolverOpts, err := dialTarget(host, port, app.conf.Targets)
+	if err != nil {
+		log.Error("failed to configure gRPC targets", slog.Any("err", err))
+		return nil, errors.Join(ErrGRPCStartError, err)
+	}
 
-	conn, err := grpc.NewClient(fmt.Sprintf("%s:%d", host, port), opts...)
+	serviceConf, err := serviceConfig(app.conf.Balancing)
+	if err != nil {
+		log.Error("failed to configure gRPC load balancing", slog.Any("err", err))
+		return nil, errors.Join(ErrGRPCStartError, err)
+	}
+
+	creds, err := transportCredentials(app.logger, app.conf.GrpcTLSConfig)
+	if err != nil {
+		log.Error("failed to configure gRPC transport credentials", slog.Any("err", err))
+		return nil, errors.Join(ErrGRPCStartError, err)
+	}
+
+	opts = append(opts, resolverOpts...)
+	opts = append(opts, grpc.WithTransportCredentials(creds))
+	opts = append(opts, grpc.WithDisableServiceConfig(), grpc.WithDefaultServiceConfig(serviceConf))
+	opts = append(opts, grpc.WithChainUnaryInterceptor(app.interceptors()...))
+
+	conn, err := grpc.NewClient(target, opts...)
 
 	if err != nil {
 		log.Error("failed to create gRPC client", slog.Any("err", err))
@@ -72,6 +173,8 @@ func (app *GRPCApplication) Start(host string, port int) (*todoprotobufv1.ToDoSe
 	app.conn = conn
 
 	client := todoprotobufv1.NewToDoServiceClient(conn)
+	app.tokens = newServiceTokenSource(app.logger, app.conf.ServiceAuth, client)
+
 	return &client, nil
 }
 
@@ -0,0 +1,94 @@
+package grpcapplication
+
+import (
+	"context"
+	"testing"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/lib/authcontext"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnaryInterceptor_ForwardsBearerToken(t *testing.T) {
+	mock := mocks.New(false)
+	ctx := authcontext.WithBearerToken(context.Background(), "1:user1")
+
+	var reply todoprotobufv1.CheckSecretResponce
+	err := newUnaryInterceptor(noTokenSource)(
+		ctx,
+		todoprotobufv1.ToDoService_CheckSecret_FullMethodName,
+		&todoprotobufv1.CheckSecretRequest{Secret: "1:user1"},
+		&reply,
+		nil,
+		mock.Invoke,
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), reply.GetUserId())
+	require.Equal(t,
+		[]string{"Bearer 1:user1"},
+		mock.OutgoingMetadata("CheckSecret").Get(authorizationMetadataKey),
+	)
+}
+
+func TestUnaryInterceptor_NoTokenInContext(t *testing.T) {
+	mock := mocks.New(false)
+
+	var reply todoprotobufv1.LoginResponce
+	err := newUnaryInterceptor(noTokenSource)(
+		context.Background(),
+		todoprotobufv1.ToDoService_Login_FullMethodName,
+		&todoprotobufv1.LoginRequest{Email: "user1", Password: "pass"},
+		&reply,
+		nil,
+		mock.Invoke,
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, "1:user1", reply.GetToken())
+	require.Empty(t, mock.OutgoingMetadata("Login").Get(authorizationMetadataKey))
+}
+
+func TestUnaryInterceptor_TokenPerRequest(t *testing.T) {
+	mock := mocks.New(false)
+
+	testData := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "First user",
+			token: "1:user1",
+		},
+		{
+			name:  "Second user",
+			token: "2:user2",
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			ctx := authcontext.WithBearerToken(context.Background(), data.token)
+
+			var reply todoprotobufv1.LogoutResponce
+			_ = newUnaryInterceptor(noTokenSource)(
+				ctx,
+				todoprotobufv1.ToDoService_Logout_FullMethodName,
+				&todoprotobufv1.LogoutRequest{Token: data.token},
+				&reply,
+				nil,
+				mock.Invoke,
+			)
+
+			require.Equal(t,
+				[]string{"Bearer " + data.token},
+				mock.OutgoingMetadata("Logout").Get(authorizationMetadataKey),
+			)
+		})
+	}
+}
+
+func noTokenSource() *TokenSource {
+	return nil
+}
@@ -0,0 +1,292 @@
+package grpcapplication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/serviceconfig"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	PolicyRoundRobin = "round_robin"
+	PolicyPickFirst  = "pick_first"
+
+	// ejectingRoundRobinName is round_robin with outlier ejection registered by this package
+	ejectingRoundRobinName = "todoapi_round_robin"
+	staticResolverScheme   = "static"
+)
+
+var (
+	ErrGRPCUnknownPolicy = errors.New("unknown gRPC load balancing policy")
+	ErrGRPCInvalidTarget = errors.New("invalid gRPC backend target")
+)
+
+func init() {
+	balancer.Register(ejectingBuilder{})
+}
+
+// outlierConfig is ejecting round_robin service config
+type outlierConfig struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	EjectionTime        time.Duration `json:"ejectionTime"`
+	MaxEjectionPercent  int           `json:"maxEjectionPercent"`
+}
+
+// outlierTracker counts consecutive backend failures per SubConn and ejects backends
+// failing more than the threshold for EjectionTime
+type outlierTracker struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	conf    outlierConfig
+	states  map[balancer.SubConn]*outlierState
+	current int
+}
+
+type outlierState struct {
+	failures     int
+	ejectedUntil time.Time
+}
+
+func newOutlierTracker() *outlierTracker {
+	return &outlierTracker{
+		now:    time.Now,
+		states: make(map[balancer.SubConn]*outlierState),
+	}
+}
+
+func (t *outlierTracker) setConfig(conf outlierConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.conf = conf
+}
+
+// reset Forgets SubConns which are not ready anymore. A reconnected backend starts clean
+func (t *outlierTracker) reset(ready []balancer.SubConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	states := make(map[balancer.SubConn]*outlierState, len(ready))
+	for _, sc := range ready {
+		if state, ok := t.states[sc]; ok {
+			states[sc] = state
+		} else {
+			states[sc] = &outlierState{}
+		}
+	}
+	t.states = states
+	t.current = len(ready)
+}
+
+// available Returns SubConns which are not ejected. All of them if every one is ejected
+func (t *outlierTracker) available(subConns []balancer.SubConn) []balancer.SubConn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	result := make([]balancer.SubConn, 0, len(subConns))
+	for _, sc := range subConns {
+		if state, ok := t.states[sc]; !ok || !now.Before(state.ejectedUntil) {
+			result = append(result, sc)
+		}
+	}
+
+	if len(result) == 0 {
+		return subConns
+	}
+	return result
+}
+
+// record Counts the call result. Ejects the backend unless too many are ejected already
+func (t *outlierTracker) record(sc balancer.SubConn, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.states[sc]
+	if !ok {
+		return
+	}
+
+	if !failed {
+		state.failures = 0
+		return
+	}
+
+	state.failures++
+	if t.conf.ConsecutiveFailures <= 0 || state.failures < t.conf.ConsecutiveFailures {
+		return
+	}
+
+	now := t.now()
+	ejected := 0
+	for _, other := range t.states {
+		if now.Before(other.ejectedUntil) {
+			ejected++
+		}
+	}
+
+	if (ejected+1)*100 <= t.current*t.conf.MaxEjectionPercent {
+		state.failures = 0
+		state.ejectedUntil = now.Add(t.conf.EjectionTime)
+	}
+}
+
+// ejectingPickerBuilder builds round robin pickers over ready SubConns skipping ejected ones
+type ejectingPickerBuilder struct {
+	tracker *outlierTracker
+}
+
+func (b *ejectingPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		b.tracker.reset(nil)
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	subConns := make([]balancer.SubConn, 0, len(info.ReadySCs))
+	for sc := range info.ReadySCs {
+		subConns = append(subConns, sc)
+	}
+	b.tracker.reset(subConns)
+
+	return &ejectingPicker{
+		tracker:  b.tracker,
+		subConns: subConns,
+		next:     rand.Uint32(),
+	}
+}
+
+type ejectingPicker struct {
+	tracker  *outlierTracker
+	subConns []balancer.SubConn
+	next     uint32
+}
+
+func (p *ejectingPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	available := p.tracker.available(p.subConns)
+	sc := available[int(atomic.AddUint32(&p.next, 1)%uint32(len(available)))]
+
+	return balancer.PickResult{
+		SubConn: sc,
+		Done: func(info balancer.DoneInfo) {
+			// Deadlines are partly set by clients through Request-Timeout,
+			// so only Unavailable says the backend itself is failing
+			p.tracker.record(sc, status.Code(info.Err) == codes.Unavailable)
+		},
+	}, nil
+}
+
+// ejectingBuilder builds base round robin balancer with outlier tracking configured from service config
+type ejectingBuilder struct{}
+
+func (ejectingBuilder) Name() string {
+	return ejectingRoundRobinName
+}
+
+func (ejectingBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	tracker := newOutlierTracker()
+	pickerBuilder := &ejectingPickerBuilder{tracker: tracker}
+
+	return &ejectingBalancer{
+		Balancer: base.NewBalancerBuilder(
+			ejectingRoundRobinName,
+			pickerBuilder,
+			base.Config{HealthCheck: true},
+		).Build(cc, opts),
+		tracker: tracker,
+	}
+}
+
+func (ejectingBuilder) ParseConfig(raw json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	var conf outlierConfig
+	if err := json.Unmarshal(raw, &conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+type ejectingBalancer struct {
+	balancer.Balancer
+	tracker *outlierTracker
+}
+
+func (b *ejectingBalancer) UpdateClientConnState(state balancer.ClientConnState) error {
+	if conf, ok := state.BalancerConfig.(*outlierConfig); ok {
+		b.tracker.setConfig(*conf)
+	}
+	return b.Balancer.UpdateClientConnState(state)
+}
+
+func (b *ejectingBalancer) ExitIdle() {
+	if exiter, ok := b.Balancer.(balancer.ExitIdler); ok {
+		exiter.ExitIdle()
+	}
+}
+
+// serviceConfig Returns default service config JSON selecting the load balancing policy.
+// Health checking and outlier ejection apply to round_robin, pick_first sticks to the first reachable backend
+func serviceConfig(conf configapplication.GrpcBalancingConfig) (string, error) {
+	sc := map[string]any{}
+
+	switch conf.Policy {
+	case "", PolicyPickFirst:
+		sc["loadBalancingConfig"] = []any{map[string]any{PolicyPickFirst: map[string]any{}}}
+	case PolicyRoundRobin:
+		sc["loadBalancingConfig"] = []any{map[string]any{ejectingRoundRobinName: outlierConfig{
+			ConsecutiveFailures: conf.EjectionFailures,
+			EjectionTime:        conf.EjectionTime,
+			MaxEjectionPercent:  conf.MaxEjectionPercent,
+		}}}
+		if conf.HealthCheck {
+			sc["healthCheckConfig"] = map[string]any{"serviceName": conf.HealthService}
+		}
+	default:
+		return "", fmt.Errorf("%w: %q", ErrGRPCUnknownPolicy, conf.Policy)
+	}
+
+	js, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+// dialTarget Returns client target and options resolving it: the static list of backend addresses
+// if configured, otherwise host resolved by DNS
+func dialTarget(host string, port int, targets []string) (string, []grpc.DialOption, error) {
+	if len(targets) == 0 {
+		return fmt.Sprintf("%s:%d", host, port), nil, nil
+	}
+
+	addresses := make([]resolver.Address, 0, len(targets))
+	for _, target := range targets {
+		host, _, err := net.SplitHostPort(target)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %q", ErrGRPCInvalidTarget, target)
+		}
+		// Every backend is verified against its own host, not the shared target name
+		addresses = append(addresses, resolver.Address{Addr: target, ServerName: host})
+	}
+
+	r := manual.NewBuilderWithScheme(staticResolverScheme)
+	r.InitialState(resolver.State{Addresses: addresses})
+
+	return staticResolverScheme + ":///backends", []grpc.DialOption{grpc.WithResolvers(r)}, nil
+}
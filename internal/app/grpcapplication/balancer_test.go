@@ -0,0 +1,301 @@
+package grpcapplication
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// backendServer answers CheckSecret with its own ID as user ID
+type backendServer struct {
+	todoprotobufv1.UnimplementedToDoServiceServer
+
+	id      uint64
+	failing atomic.Bool
+	slow    atomic.Bool
+	calls   atomic.Int64
+
+	addr   string
+	srv    *grpc.Server
+	health *health.Server
+}
+
+func (s *backendServer) CheckSecret(
+	ctx context.Context,
+	_ *todoprotobufv1.CheckSecretRequest,
+) (*todoprotobufv1.CheckSecretResponce, error) {
+	s.calls.Add(1)
+	if s.slow.Load() {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if s.failing.Load() {
+		return nil, status.Error(codes.Unavailable, "backend is failing")
+	}
+	return &todoprotobufv1.CheckSecretResponce{UserId: s.id}, nil
+}
+
+// startBackends Starts count in-process gRPC servers with health service
+func startBackends(t *testing.T, count int) []*backendServer {
+	t.Helper()
+
+	backends := make([]*backendServer, 0, count)
+	for i := range count {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		backend := &backendServer{
+			id:     uint64(i + 1),
+			addr:   lis.Addr().String(),
+			srv:    grpc.NewServer(),
+			health: health.NewServer(),
+		}
+		todoprotobufv1.RegisterToDoServiceServer(backend.srv, backend)
+		healthpb.RegisterHealthServer(backend.srv, backend.health)
+
+		go func() {
+			_ = backend.srv.Serve(lis)
+		}()
+		t.Cleanup(backend.srv.Stop)
+
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+func backendAddrs(backends []*backendServer) []string {
+	addrs := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		addrs = append(addrs, backend.addr)
+	}
+	return addrs
+}
+
+// balancingConf Returns config without retries and circuit breaker, so every call reaches one backend
+func balancingConf(backends []*backendServer, balancing configapplication.GrpcBalancingConfig) configapplication.GrpcConfig {
+	return configapplication.GrpcConfig{
+		Targets:   backendAddrs(backends),
+		Balancing: balancing,
+		Retry:     configapplication.GrpcRetryConfig{MaxAttempts: 1},
+	}
+}
+
+// callBackends Makes n calls and returns number of successful calls answered by every backend ID
+func callBackends(client todoprotobufv1.ToDoServiceClient, n int) map[uint64]int {
+	answered := make(map[uint64]int)
+	for range n {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		resp, err := client.CheckSecret(ctx, &todoprotobufv1.CheckSecretRequest{Secret: "ping"})
+		cancel()
+
+		if err == nil {
+			answered[resp.GetUserId()]++
+		}
+	}
+	return answered
+}
+
+func TestBalancer_RoundRobin(t *testing.T) {
+	backends := startBackends(t, 3)
+	client := startClient(t, balancingConf(backends, configapplication.GrpcBalancingConfig{
+		Policy:      PolicyRoundRobin,
+		HealthCheck: true,
+	}), 0)
+
+	require.Eventually(t, func() bool {
+		return len(callBackends(client, 6)) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+
+	answered := callBackends(client, 30)
+	require.Equal(t, map[uint64]int{1: 10, 2: 10, 3: 10}, answered)
+}
+
+func TestBalancer_PickFirst(t *testing.T) {
+	backends := startBackends(t, 3)
+	client := startClient(t, balancingConf(backends, configapplication.GrpcBalancingConfig{
+		Policy: PolicyPickFirst,
+	}), 0)
+
+	answered := callBackends(client, 10)
+	require.Equal(t, map[uint64]int{1: 10}, answered)
+
+	// Next backend takes over when the first one goes away
+	backends[0].srv.Stop()
+
+	require.Eventually(t, func() bool {
+		answered := callBackends(client, 1)
+		return answered[2] == 1
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestBalancer_HealthCheck(t *testing.T) {
+	backends := startBackends(t, 3)
+	client := startClient(t, balancingConf(backends, configapplication.GrpcBalancingConfig{
+		Policy:      PolicyRoundRobin,
+		HealthCheck: true,
+	}), 0)
+
+	require.Eventually(t, func() bool {
+		return len(callBackends(client, 6)) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+
+	backends[1].health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	require.Eventually(t, func() bool {
+		_, ok := callBackends(client, 6)[2]
+		return !ok
+	}, 5*time.Second, 50*time.Millisecond)
+
+	answered := callBackends(client, 20)
+	require.Equal(t, 20, answered[1]+answered[3])
+
+	backends[1].health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	require.Eventually(t, func() bool {
+		return len(callBackends(client, 6)) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestBalancer_BackendRestart(t *testing.T) {
+	backends := startBackends(t, 2)
+	client := startClient(t, balancingConf(backends, configapplication.GrpcBalancingConfig{
+		Policy:      PolicyRoundRobin,
+		HealthCheck: true,
+	}), 0)
+
+	require.Eventually(t, func() bool {
+		return len(callBackends(client, 4)) == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	backends[0].srv.Stop()
+
+	// Calls already in flight may fail, afterwards the remaining backend serves all of them
+	require.Eventually(t, func() bool {
+		return callBackends(client, 10)[2] == 10
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestBalancer_OutlierEjection(t *testing.T) {
+	backends := startBackends(t, 3)
+	client := startClient(t, balancingConf(backends, configapplication.GrpcBalancingConfig{
+		Policy:             PolicyRoundRobin,
+		EjectionFailures:   2,
+		EjectionTime:       time.Minute,
+		MaxEjectionPercent: 50,
+	}), 0)
+
+	require.Eventually(t, func() bool {
+		return len(callBackends(client, 6)) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Backend is connected and healthy but fails calls
+	backends[0].failing.Store(true)
+	backends[0].calls.Store(0)
+
+	callBackends(client, 9)
+	require.Equal(t, int64(2), backends[0].calls.Load())
+
+	answered := callBackends(client, 20)
+	require.Equal(t, map[uint64]int{2: 10, 3: 10}, answered)
+	require.Equal(t, int64(2), backends[0].calls.Load())
+
+	// Not more than half of the backends is ejected
+	backends[1].failing.Store(true)
+	backends[1].calls.Store(0)
+
+	callBackends(client, 20)
+	require.Equal(t, int64(10), backends[1].calls.Load())
+}
+
+func TestBalancer_OutlierEjection_IgnoresDeadlines(t *testing.T) {
+	backends := startBackends(t, 2)
+	client := startClient(t, balancingConf(backends, configapplication.GrpcBalancingConfig{
+		Policy:             PolicyRoundRobin,
+		EjectionFailures:   2,
+		EjectionTime:       time.Minute,
+		MaxEjectionPercent: 50,
+	}), 0)
+
+	require.Eventually(t, func() bool {
+		return len(callBackends(client, 4)) == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Tiny client deadlines expire on every backend, they must not eject a healthy one
+	backends[0].slow.Store(true)
+	backends[1].slow.Store(true)
+	for range 8 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := client.CheckSecret(ctx, &todoprotobufv1.CheckSecretRequest{Secret: "ping"})
+		cancel()
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	}
+	backends[0].slow.Store(false)
+	backends[1].slow.Store(false)
+
+	require.Equal(t, map[uint64]int{1: 5, 2: 5}, callBackends(client, 10))
+}
+
+func TestServiceConfig(t *testing.T) {
+	testData := []struct {
+		name     string
+		conf     configapplication.GrpcBalancingConfig
+		expected string
+		err      error
+	}{
+		{
+			name:     "Default policy",
+			conf:     configapplication.GrpcBalancingConfig{},
+			expected: `{"loadBalancingConfig":[{"pick_first":{}}]}`,
+		},
+		{
+			name: "Round robin with health check",
+			conf: configapplication.GrpcBalancingConfig{
+				Policy:             PolicyRoundRobin,
+				HealthCheck:        true,
+				HealthService:      "todo",
+				EjectionFailures:   5,
+				EjectionTime:       time.Second,
+				MaxEjectionPercent: 50,
+			},
+			expected: fmt.Sprintf(
+				`{"healthCheckConfig":{"serviceName":"todo"},"loadBalancingConfig":[{"%s":`+
+					`{"consecutiveFailures":5,"ejectionTime":1000000000,"maxEjectionPercent":50}}]}`,
+				ejectingRoundRobinName,
+			),
+		},
+		{
+			name: "Unknown policy",
+			conf: configapplication.GrpcBalancingConfig{Policy: "least_request"},
+			err:  ErrGRPCUnknownPolicy,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			sc, err := serviceConfig(data.conf)
+			if data.err != nil {
+				require.ErrorIs(t, err, data.err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, data.expected, sc)
+		})
+	}
+}
+
+func TestDialTarget_InvalidTarget(t *testing.T) {
+	_, _, err := dialTarget("localhost", 9090, []string{"127.0.0.1"})
+	require.ErrorIs(t, err, ErrGRPCInvalidTarget)
+}
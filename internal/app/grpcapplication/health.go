@@ -0,0 +1,63 @@
+package grpcapplication
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrGRPCNotReady   = errors.New("gRPC connection is not ready")
+	ErrGRPCNotServing = errors.New("backend is not serving")
+)
+
+// CheckConnection Reports whether the client connection is READY.
+// Idle connection is woken up and waited for until ctx is done
+func (app *GRPCApplication) CheckConnection(ctx context.Context) (string, error) {
+	if app.conn == nil {
+		return "", ErrGRPCNotRunning
+	}
+
+	state := app.conn.GetState()
+	if state == connectivity.Idle {
+		app.conn.Connect()
+	}
+
+	for state == connectivity.Idle || state == connectivity.Connecting {
+		if !app.conn.WaitForStateChange(ctx, state) {
+			break
+		}
+		state = app.conn.GetState()
+	}
+
+	if state != connectivity.Ready {
+		return state.String(), ErrGRPCNotReady
+	}
+	return state.String(), nil
+}
+
+// CheckBackend Asks the backend health by the standard gRPC health protocol.
+// Backend without health service is considered serving as it has answered the call
+func (app *GRPCApplication) CheckBackend(ctx context.Context) (string, error) {
+	if app.conn == nil {
+		return "", ErrGRPCNotRunning
+	}
+
+	resp, err := healthpb.NewHealthClient(app.conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: app.conf.Balancing.HealthService,
+	})
+
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		return "health service is not implemented", nil
+	case err != nil:
+		return status.Code(err).String(), err
+	case resp.GetStatus() != healthpb.HealthCheckResponse_SERVING:
+		return resp.GetStatus().String(), ErrGRPCNotServing
+	}
+	return resp.GetStatus().String(), nil
+}
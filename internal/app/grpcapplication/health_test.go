@@ -0,0 +1,82 @@
+package grpcapplication
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func startHealthApp(t *testing.T, conf configapplication.GrpcConfig, port int) *GRPCApplication {
+	t.Helper()
+
+	app := New(slog.Default(), conf, nil)
+	_, err := app.Start("127.0.0.1", port)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = app.Stop()
+	})
+
+	return app
+}
+
+func TestGRPCApplication_CheckConnection(t *testing.T) {
+	backends := startBackends(t, 1)
+	app := startHealthApp(t, balancingConf(backends, configapplication.GrpcBalancingConfig{}), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Idle connection is established by the check
+	detail, err := app.CheckConnection(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "READY", detail)
+
+	backends[0].srv.Stop()
+
+	require.Eventually(t, func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := app.CheckConnection(ctx)
+		return err != nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestGRPCApplication_CheckBackend(t *testing.T) {
+	backends := startBackends(t, 1)
+	app := startHealthApp(t, balancingConf(backends, configapplication.GrpcBalancingConfig{}), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	detail, err := app.CheckBackend(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "SERVING", detail)
+
+	backends[0].health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	detail, err = app.CheckBackend(ctx)
+	require.ErrorIs(t, err, ErrGRPCNotServing)
+	require.Equal(t, "NOT_SERVING", detail)
+
+	// Backend without health service
+	other := startHealthApp(t, configapplication.GrpcConfig{}, startTestServer(t, nil))
+
+	_, err = other.CheckBackend(ctx)
+	require.NoError(t, err)
+}
+
+func TestGRPCApplication_ChecksNotRunning(t *testing.T) {
+	app := New(slog.Default(), configapplication.GrpcConfig{}, nil)
+
+	_, err := app.CheckConnection(context.Background())
+	require.ErrorIs(t, err, ErrGRPCNotRunning)
+
+	_, err = app.CheckBackend(context.Background())
+	require.ErrorIs(t, err, ErrGRPCNotRunning)
+}
@@ -4,24 +4,92 @@ import (
 	"context"
 	"fmt"
 	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"sort"
 	"strings"
+	"sync"
 )
 
+type mockTask struct {
+	userID uint64
+	title  string
+	isDone bool
+}
+
 type ToDoGrpcMock struct {
 	isDown    bool
+	mu        *sync.Mutex
 	blackList map[string]struct{}
+	outgoing  map[string]metadata.MD
+	calls     map[string]int
+	tasks     map[uint64]*mockTask
+	lastID    *uint64
 }
 
 func New(isDown bool) *ToDoGrpcMock {
 	return &ToDoGrpcMock{
 		isDown:    isDown,
+		mu:        &sync.Mutex{},
 		blackList: make(map[string]struct{}, 2),
+		outgoing:  make(map[string]metadata.MD),
+		calls:     make(map[string]int),
+		tasks:     make(map[uint64]*mockTask),
+		lastID:    new(uint64),
 	}
 }
 
+// AddTask Stores a task for userID bypassing the RPC layer. Returns the task ID
+func (p ToDoGrpcMock) AddTask(userID uint64, title string, isDone bool) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	*p.lastID++
+	p.tasks[*p.lastID] = &mockTask{userID: userID, title: title, isDone: isDone}
+	return *p.lastID
+}
+
+// SetDown Switches the mock between answering normally and Unavailable.
+// Must not be called concurrently with RPCs
+func (p *ToDoGrpcMock) SetDown(isDown bool) {
+	p.isDown = isDown
+}
+
+// OutgoingMetadata Returns metadata attached to the last call of method
+func (p ToDoGrpcMock) OutgoingMetadata(method string) metadata.MD {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.outgoing[method]
+}
+
+// Calls Returns number of method calls since the last ResetCalls
+func (p ToDoGrpcMock) Calls(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[method]
+}
+
+// ResetCalls Resets call counters
+func (p ToDoGrpcMock) ResetCalls() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	clear(p.calls)
+}
+
+func (p ToDoGrpcMock) track(ctx context.Context, method string) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.outgoing[method] = md
+	p.calls[method]++
+}
+
+var errServiceDown = status.Error(codes.Unavailable, "service is down")
+
 func decodeToken(token string) ([]string, error) {
 	tokenData := strings.Split(token, ":")
 
@@ -35,8 +103,9 @@ func (p ToDoGrpcMock) Login(
 	ctx context.Context,
 	in *todoprotobufv1.LoginRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.LoginResponce, error) {
+	p.track(ctx, "Login")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
 
 	if in.GetEmail() == "user1" && in.GetPassword() == "pass" {
@@ -45,6 +114,20 @@ func (p ToDoGrpcMock) Login(
 		}, nil
 
 	}
+
+	// Special accounts to emulate backend failure modes
+	switch in.GetEmail() {
+	case "locked":
+		st, _ := status.New(codes.FailedPrecondition, "Account is locked").
+			WithDetails(&errdetails.ErrorInfo{Reason: "ACCOUNT_LOCKED"})
+		return nil, st.Err()
+	case "unverified":
+		return nil, status.Error(codes.FailedPrecondition, "Email is not verified")
+	case "slow":
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
 	return nil, status.Error(codes.PermissionDenied, "Username or password incorrect")
 }
 
@@ -52,8 +135,9 @@ func (p ToDoGrpcMock) Logout(
 	ctx context.Context,
 	in *todoprotobufv1.LogoutRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.LogoutResponce, error) {
+	p.track(ctx, "Logout")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
 	tokenData, err := decodeToken(in.GetToken())
 
@@ -61,6 +145,9 @@ func (p ToDoGrpcMock) Logout(
 		return nil, status.Error(codes.FailedPrecondition, "Bad token")
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if _, ok := p.blackList[tokenData[0]]; !ok && tokenData[0] == "1" && tokenData[1] == "user1" {
 		p.blackList[tokenData[0]] = struct{}{}
 		return &todoprotobufv1.LogoutResponce{
@@ -78,8 +165,9 @@ func (p ToDoGrpcMock) CheckSecret(
 	ctx context.Context,
 	in *todoprotobufv1.CheckSecretRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.CheckSecretResponce, error) {
+	p.track(ctx, "CheckSecret")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
 
 	tokenData, err := decodeToken(in.GetSecret())
@@ -87,6 +175,9 @@ func (p ToDoGrpcMock) CheckSecret(
 		return nil, status.Error(codes.FailedPrecondition, "Bad token")
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if tokenData[0] == "1" {
 		if _, ok := p.blackList[tokenData[0]]; !ok && tokenData[1] == "user1" {
 			return &todoprotobufv1.CheckSecretResponce{
@@ -103,53 +194,166 @@ func (p ToDoGrpcMock) CreateTask(
 	ctx context.Context,
 	in *todoprotobufv1.CreateTaskRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.CreateTaskResponce, error) {
+	p.track(ctx, "CreateTask")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
-	//TODO implement me
-	panic("implement me")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	*p.lastID++
+	p.tasks[*p.lastID] = &mockTask{userID: in.GetUserId(), title: in.GetTitle()}
+
+	return &todoprotobufv1.CreateTaskResponce{
+		TaskId: *p.lastID,
+	}, nil
 }
 
 func (p ToDoGrpcMock) ListTasks(
 	ctx context.Context,
 	in *todoprotobufv1.ListTasksRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.ListTasksResponce, error) {
+	p.track(ctx, "ListTasks")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
-	//TODO implement me
-	panic("implement me")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tasks := make([]*todoprotobufv1.GetTaskByIdResponce, 0)
+	for id, task := range p.tasks {
+		if task.userID == in.GetUserId() {
+			tasks = append(tasks, &todoprotobufv1.GetTaskByIdResponce{
+				TaskId: id,
+				Title:  task.title,
+				IsDone: task.isDone,
+			})
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].TaskId < tasks[j].TaskId })
+
+	return &todoprotobufv1.ListTasksResponce{
+		Tasks: tasks,
+	}, nil
 }
 
 func (p ToDoGrpcMock) GetTaskByID(
 	ctx context.Context,
 	in *todoprotobufv1.TaskByIdRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.GetTaskByIdResponce, error) {
+	p.track(ctx, "GetTaskByID")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
-	//TODO implement me
-	panic("implement me")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	task, ok := p.tasks[in.GetTaskId()]
+	if !ok || task.userID != in.GetUserId() {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+
+	return &todoprotobufv1.GetTaskByIdResponce{
+		TaskId: in.GetTaskId(),
+		Title:  task.title,
+		IsDone: task.isDone,
+	}, nil
 }
 
 func (p ToDoGrpcMock) UpdateTaskByID(
 	ctx context.Context,
 	in *todoprotobufv1.UpdateTaskByIdRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.ChangedTaskByIdResponce, error) {
+	p.track(ctx, "UpdateTaskByID")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
-	//TODO implement me
-	panic("implement me")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	task, ok := p.tasks[in.GetTaskId()]
+	if !ok || task.userID != in.GetUserId() {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+
+	if in.Title != nil {
+		task.title = in.GetTitle()
+	}
+	if in.IsDone != nil {
+		task.isDone = in.GetIsDone()
+	}
+
+	return &todoprotobufv1.ChangedTaskByIdResponce{
+		TaskId:    in.GetTaskId(),
+		IsSuccess: true,
+	}, nil
 }
 
 func (p ToDoGrpcMock) DeleteTaskByID(
 	ctx context.Context,
 	in *todoprotobufv1.TaskByIdRequest,
 	opts ...grpc.CallOption) (*todoprotobufv1.ChangedTaskByIdResponce, error) {
+	p.track(ctx, "DeleteTaskByID")
 	if p.isDown {
-		return nil, fmt.Errorf("service is down")
+		return nil, errServiceDown
 	}
-	//TODO implement me
-	panic("implement me")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	task, ok := p.tasks[in.GetTaskId()]
+	if !ok || task.userID != in.GetUserId() {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+	delete(p.tasks, in.GetTaskId())
+
+	return &todoprotobufv1.ChangedTaskByIdResponce{
+		TaskId:    in.GetTaskId(),
+		IsSuccess: true,
+	}, nil
+}
+
+// Invoke Dispatches a raw unary call to the mock. Compatible with grpc.UnaryInvoker
+func (p ToDoGrpcMock) Invoke(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	_ *grpc.ClientConn,
+	opts ...grpc.CallOption) error {
+	var (
+		resp proto.Message
+		err  error
+	)
+
+	switch method {
+	case todoprotobufv1.ToDoService_Login_FullMethodName:
+		resp, err = p.Login(ctx, req.(*todoprotobufv1.LoginRequest), opts...)
+	case todoprotobufv1.ToDoService_Logout_FullMethodName:
+		resp, err = p.Logout(ctx, req.(*todoprotobufv1.LogoutRequest), opts...)
+	case todoprotobufv1.ToDoService_CheckSecret_FullMethodName:
+		resp, err = p.CheckSecret(ctx, req.(*todoprotobufv1.CheckSecretRequest), opts...)
+	case todoprotobufv1.ToDoService_CreateTask_FullMethodName:
+		resp, err = p.CreateTask(ctx, req.(*todoprotobufv1.CreateTaskRequest), opts...)
+	case todoprotobufv1.ToDoService_ListTasks_FullMethodName:
+		resp, err = p.ListTasks(ctx, req.(*todoprotobufv1.ListTasksRequest), opts...)
+	case todoprotobufv1.ToDoService_GetTaskByID_FullMethodName:
+		resp, err = p.GetTaskByID(ctx, req.(*todoprotobufv1.TaskByIdRequest), opts...)
+	case todoprotobufv1.ToDoService_UpdateTaskByID_FullMethodName:
+		resp, err = p.UpdateTaskByID(ctx, req.(*todoprotobufv1.UpdateTaskByIdRequest), opts...)
+	case todoprotobufv1.ToDoService_DeleteTaskByID_FullMethodName:
+		resp, err = p.DeleteTaskByID(ctx, req.(*todoprotobufv1.TaskByIdRequest), opts...)
+	default:
+		return status.Errorf(codes.Unimplemented, "method %s not implemented", method)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	proto.Merge(reply.(proto.Message), resp)
+	return nil
 }
@@ -0,0 +1,227 @@
+package grpcapplication
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"sync"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/lib/retryafter"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// methodName Returns short method name of the full gRPC method: /package.Service/Method -> Method
+func methodName(fullMethod string) string {
+	return path.Base(fullMethod)
+}
+
+// isBackendFailure Reports whether err means the backend did not handle the call
+func isBackendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// newDeadlineInterceptor Returns interceptor limiting every call attempt by the method timeout
+// or the default one. A sooner caller deadline is kept
+func newDeadlineInterceptor(timeout time.Duration, methodTimeouts map[string]time.Duration) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string, req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		callTimeout := timeout
+		if methodTimeout, ok := methodTimeouts[methodName(method)]; ok {
+			callTimeout = methodTimeout
+		}
+
+		if callTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, callTimeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// backoff Returns jittered exponential delay before the retry following attempt.
+// The delay is between half and full of the exponential value
+func backoff(conf configapplication.GrpcRetryConfig, attempt int) time.Duration {
+	delay := conf.InitialBackoff
+	for i := 1; i < attempt && delay < conf.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, conf.MaxBackoff)
+
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + rand.N(delay/2)
+}
+
+// newRetryInterceptor Returns interceptor retrying idempotent methods when the backend is unavailable
+// or the attempt deadline is exceeded while the caller still waits
+func newRetryInterceptor(conf configapplication.GrpcRetryConfig) grpc.UnaryClientInterceptor {
+	methods := make(map[string]struct{}, len(conf.Methods))
+	for _, method := range conf.Methods {
+		methods[method] = struct{}{}
+	}
+
+	return func(
+		ctx context.Context,
+		method string, req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if _, ok := methods[methodName(method)]; !ok || conf.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !isBackendFailure(err) || ctx.Err() != nil || attempt >= conf.MaxAttempts {
+				return err
+			}
+
+			timer := time.NewTimer(backoff(conf, attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// circuitBreaker opens after FailureThreshold consecutive backend failures and fast-fails calls
+// for OpenTimeout. Then a single probe call is let through: success closes the breaker,
+// failure opens it again
+type circuitBreaker struct {
+	logger      *slog.Logger
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(logger *slog.Logger, conf configapplication.GrpcBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		logger:      logger.With("component", "circuitbreaker"),
+		threshold:   conf.FailureThreshold,
+		openTimeout: conf.OpenTimeout,
+		now:         time.Now,
+	}
+}
+
+// allow Reports whether a call may proceed. Otherwise returns time left until the next probe
+func (b *circuitBreaker) allow() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return 0, true
+	}
+
+	now := b.now()
+	if now.Before(b.openUntil) {
+		return b.openUntil.Sub(now), false
+	}
+	if b.probing {
+		return 0, false
+	}
+
+	b.probing = true
+	return 0, true
+}
+
+// release Ends the probe without counting its result
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// record Counts the call result
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if !failed {
+		if b.failures >= b.threshold {
+			b.logger.Info("circuit breaker closed")
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			b.logger.Warn("circuit breaker opened", slog.Int("failures", b.failures))
+		}
+		b.openUntil = b.now().Add(b.openTimeout)
+	}
+}
+
+// interceptor Returns interceptor failing calls with codes.Unavailable and retry delay while the breaker is open
+func (b *circuitBreaker) interceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string, req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if wait, ok := b.allow(); !ok {
+			return retryafter.Unavailable("circuit breaker is open", wait)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		// Calls abandoned by the caller say nothing about the backend
+		if isBackendFailure(err) && ctx.Err() != nil {
+			b.release()
+			return err
+		}
+
+		b.record(isBackendFailure(err))
+		return err
+	}
+}
+
+// newMetricsInterceptor Returns interceptor recording every call once with its final status code
+func newMetricsInterceptor(recorder IMetricsRecorder) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string, req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		started := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		recorder.ObserveGRPCCall(methodName(method), status.Code(err).String(), time.Since(started))
+		return err
+	}
+}
@@ -0,0 +1,298 @@
+package grpcapplication
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/lib/retryafter"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testRetryConf = configapplication.GrpcRetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     4 * time.Millisecond,
+	Methods:        []string{"GetTaskByID", "ListTasks", "CheckSecret"},
+}
+
+func getTask(ctx context.Context, interceptor grpc.UnaryClientInterceptor, invoker grpc.UnaryInvoker) error {
+	var reply todoprotobufv1.GetTaskByIdResponce
+	return interceptor(
+		ctx,
+		todoprotobufv1.ToDoService_GetTaskByID_FullMethodName,
+		&todoprotobufv1.TaskByIdRequest{TaskId: 1, UserId: 1},
+		&reply,
+		nil,
+		invoker,
+	)
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	mock := mocks.New(true)
+	interceptor := newRetryInterceptor(testRetryConf)
+
+	testData := []struct {
+		name   string
+		method string
+		req    any
+		reply  any
+		calls  int
+	}{
+		{
+			name:   "Idempotent method is retried",
+			method: todoprotobufv1.ToDoService_GetTaskByID_FullMethodName,
+			req:    &todoprotobufv1.TaskByIdRequest{TaskId: 1, UserId: 1},
+			reply:  &todoprotobufv1.GetTaskByIdResponce{},
+			calls:  3,
+		},
+		{
+			name:   "Not idempotent method is not retried",
+			method: todoprotobufv1.ToDoService_CreateTask_FullMethodName,
+			req:    &todoprotobufv1.CreateTaskRequest{Title: "Milk", UserId: 1},
+			reply:  &todoprotobufv1.CreateTaskResponce{},
+			calls:  1,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			mock.ResetCalls()
+
+			err := interceptor(context.Background(), data.method, data.req, data.reply, nil, mock.Invoke)
+
+			require.Equal(t, codes.Unavailable, status.Code(err))
+			require.Equal(t, data.calls, mock.Calls(methodName(data.method)))
+		})
+	}
+}
+
+func TestRetryInterceptor_NoRetryOnResult(t *testing.T) {
+	mock := mocks.New(false)
+
+	err := getTask(context.Background(), newRetryInterceptor(testRetryConf), mock.Invoke)
+
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, 1, mock.Calls("GetTaskByID"))
+}
+
+func TestRetryInterceptor_RecoversAfterFailure(t *testing.T) {
+	mock := mocks.New(true)
+	taskID := mock.AddTask(1, "Milk", false)
+
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		err := mock.Invoke(ctx, method, req, reply, cc, opts...)
+		mock.SetDown(false)
+		return err
+	}
+
+	var reply todoprotobufv1.GetTaskByIdResponce
+	err := newRetryInterceptor(testRetryConf)(
+		context.Background(),
+		todoprotobufv1.ToDoService_GetTaskByID_FullMethodName,
+		&todoprotobufv1.TaskByIdRequest{TaskId: taskID, UserId: 1},
+		&reply,
+		nil,
+		invoker,
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, "Milk", reply.GetTitle())
+	require.Equal(t, 2, mock.Calls("GetTaskByID"))
+}
+
+func TestBackoff(t *testing.T) {
+	conf := configapplication.GrpcRetryConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	testData := []struct {
+		attempt int
+		full    time.Duration
+	}{
+		{attempt: 1, full: 100 * time.Millisecond},
+		{attempt: 2, full: 200 * time.Millisecond},
+		{attempt: 4, full: 800 * time.Millisecond},
+		{attempt: 10, full: time.Second},
+	}
+
+	for _, data := range testData {
+		for range 20 {
+			delay := backoff(conf, data.attempt)
+			require.GreaterOrEqual(t, delay, data.full/2)
+			require.LessOrEqual(t, delay, data.full)
+		}
+	}
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	interceptor := newDeadlineInterceptor(time.Second, map[string]time.Duration{
+		"ListTasks": 10 * time.Second,
+	})
+
+	testData := []struct {
+		name     string
+		ctx      func() (context.Context, context.CancelFunc)
+		method   string
+		expected time.Duration
+	}{
+		{
+			name:     "Default timeout",
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			method:   todoprotobufv1.ToDoService_GetTaskByID_FullMethodName,
+			expected: time.Second,
+		},
+		{
+			name:     "Method timeout",
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			method:   todoprotobufv1.ToDoService_ListTasks_FullMethodName,
+			expected: 10 * time.Second,
+		},
+		{
+			name: "Sooner caller deadline is kept",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 100*time.Millisecond)
+			},
+			method:   todoprotobufv1.ToDoService_ListTasks_FullMethodName,
+			expected: 100 * time.Millisecond,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			ctx, cancel := data.ctx()
+			defer cancel()
+
+			var left time.Duration
+			err := interceptor(ctx, data.method, nil, nil, nil,
+				func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+					deadline, ok := ctx.Deadline()
+					require.True(t, ok)
+					left = time.Until(deadline)
+					return nil
+				})
+
+			require.NoError(t, err)
+			require.InDelta(t, data.expected, left, float64(50*time.Millisecond))
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	mock := mocks.New(true)
+	mock.AddTask(1, "Milk", false)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker(slog.Default(), configapplication.GrpcBreakerConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      10 * time.Second,
+	})
+	breaker.now = func() time.Time { return now }
+	interceptor := breaker.interceptor()
+
+	for range 3 {
+		require.Equal(t, codes.Unavailable, status.Code(getTask(context.Background(), interceptor, mock.Invoke)))
+	}
+	require.Equal(t, 3, mock.Calls("GetTaskByID"))
+
+	// Open breaker fails fast with retry delay
+	now = now.Add(4 * time.Second)
+	err := getTask(context.Background(), interceptor, mock.Invoke)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 3, mock.Calls("GetTaskByID"))
+
+	delay, ok := retryafter.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, 6*time.Second, delay)
+
+	// Failed probe opens the breaker again
+	now = now.Add(6 * time.Second)
+	require.Equal(t, codes.Unavailable, status.Code(getTask(context.Background(), interceptor, mock.Invoke)))
+	require.Equal(t, 4, mock.Calls("GetTaskByID"))
+
+	_, ok = retryafter.FromError(getTask(context.Background(), interceptor, mock.Invoke))
+	require.True(t, ok)
+	require.Equal(t, 4, mock.Calls("GetTaskByID"))
+
+	// Successful probe closes the breaker
+	mock.SetDown(false)
+	now = now.Add(10 * time.Second)
+	require.NoError(t, getTask(context.Background(), interceptor, mock.Invoke))
+	require.NoError(t, getTask(context.Background(), interceptor, mock.Invoke))
+	require.Equal(t, 6, mock.Calls("GetTaskByID"))
+}
+
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+	breaker := newCircuitBreaker(slog.Default(), configapplication.GrpcBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Millisecond,
+	})
+
+	breaker.record(true)
+	time.Sleep(2 * time.Millisecond)
+
+	_, ok := breaker.allow()
+	require.True(t, ok)
+
+	// Other calls wait for the probe result
+	_, ok = breaker.allow()
+	require.False(t, ok)
+
+	breaker.release()
+	_, ok = breaker.allow()
+	require.True(t, ok)
+}
+
+func TestCircuitBreaker_IgnoresCanceledCalls(t *testing.T) {
+	mock := mocks.New(false)
+	breaker := newCircuitBreaker(slog.Default(), configapplication.GrpcBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	_ = getTask(ctx, breaker.interceptor(), invoker)
+
+	require.Equal(t, codes.NotFound, status.Code(getTask(context.Background(), breaker.interceptor(), mock.Invoke)))
+}
+
+type metricsRecorderStub struct {
+	calls map[string]int
+}
+
+func (r *metricsRecorderStub) ObserveGRPCCall(method string, code string, _ time.Duration) {
+	r.calls[method+" "+code]++
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	recorder := &metricsRecorderStub{calls: map[string]int{}}
+	interceptor := newMetricsInterceptor(recorder)
+	retry := newRetryInterceptor(testRetryConf)
+
+	mock := mocks.New(true)
+	retried := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return retry(ctx, method, req, reply, cc, mock.Invoke, opts...)
+	}
+
+	// Retried call is recorded once with the final code
+	require.Equal(t, codes.Unavailable, status.Code(getTask(context.Background(), interceptor, retried)))
+	require.Equal(t, 3, mock.Calls("GetTaskByID"))
+
+	mock.SetDown(false)
+	require.Equal(t, codes.NotFound, status.Code(getTask(context.Background(), interceptor, retried)))
+
+	require.Equal(t, map[string]int{"GetTaskByID Unavailable": 1, "GetTaskByID NotFound": 1}, recorder.calls)
+}
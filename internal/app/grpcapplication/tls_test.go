@@ -0,0 +1,302 @@
+package grpcapplication
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/lib/tlsreload/tlstest"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type checkSecretServer struct {
+	todoprotobufv1.UnimplementedToDoServiceServer
+}
+
+func (checkSecretServer) CheckSecret(
+	_ context.Context,
+	in *todoprotobufv1.CheckSecretRequest,
+) (*todoprotobufv1.CheckSecretResponce, error) {
+	return &todoprotobufv1.CheckSecretResponce{
+		UserId: 1,
+		Email:  in.GetSecret(),
+	}, nil
+}
+
+// startTestServer Starts in-process gRPC server and returns its port
+func startTestServer(t *testing.T, tlsConf *tls.Config) int {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var opts []grpc.ServerOption
+	if tlsConf != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+
+	srv := grpc.NewServer(opts...)
+	todoprotobufv1.RegisterToDoServiceServer(srv, checkSecretServer{})
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+func startClient(t *testing.T, conf configapplication.GrpcConfig, port int) todoprotobufv1.ToDoServiceClient {
+	t.Helper()
+
+	app := New(slog.Default(), conf, nil)
+	client, err := app.Start("127.0.0.1", port)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = app.Stop()
+	})
+
+	return *client
+}
+
+func checkSecret(client todoprotobufv1.ToDoServiceClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := client.CheckSecret(ctx, &todoprotobufv1.CheckSecretRequest{Secret: "ping"})
+	return err
+}
+
+func TestGRPCApplication_TLS(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "client")
+
+	port := startTestServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.TLSCertificate(t, "backend")},
+	})
+
+	client := startClient(t, configapplication.GrpcConfig{
+		GrpcTLSConfig: configapplication.GrpcTLSConfig{
+			TLSMode: TLSModeTLS,
+			CAFile:  files.CAFile,
+		},
+	}, port)
+
+	require.NoError(t, checkSecret(client))
+}
+
+func TestGRPCApplication_MTLS(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "client")
+
+	port := startTestServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.TLSCertificate(t, "backend")},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.Pool(),
+	})
+
+	testData := []struct {
+		name    string
+		conf    configapplication.GrpcTLSConfig
+		success bool
+	}{
+		{
+			name: "Client certificate provided",
+			conf: configapplication.GrpcTLSConfig{
+				TLSMode:  TLSModeMTLS,
+				CAFile:   files.CAFile,
+				CertFile: files.CertFile,
+				KeyFile:  files.KeyFile,
+			},
+			success: true,
+		},
+		{
+			name: "No client certificate",
+			conf: configapplication.GrpcTLSConfig{
+				TLSMode: TLSModeTLS,
+				CAFile:  files.CAFile,
+			},
+			success: false,
+		},
+		{
+			name: "Plaintext",
+			conf: configapplication.GrpcTLSConfig{
+				TLSMode: TLSModeInsecure,
+			},
+			success: false,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			client := startClient(t, configapplication.GrpcConfig{GrpcTLSConfig: data.conf}, port)
+
+			err := checkSecret(client)
+			if data.success {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestGRPCApplication_TLSUntrustedServer(t *testing.T) {
+	serverCA := tlstest.NewCA(t, "server-ca")
+	otherCA := tlstest.NewCA(t, "other-ca")
+	files := otherCA.WriteFiles(t, t.TempDir(), "client")
+
+	port := startTestServer(t, &tls.Config{
+		Certificates: []tls.Certificate{serverCA.TLSCertificate(t, "backend")},
+	})
+
+	client := startClient(t, configapplication.GrpcConfig{
+		GrpcTLSConfig: configapplication.GrpcTLSConfig{
+			TLSMode: TLSModeTLS,
+			CAFile:  files.CAFile,
+		},
+	}, port)
+
+	require.Error(t, checkSecret(client))
+}
+
+func TestGRPCApplication_TLSServerNameOverride(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "client")
+
+	port := startTestServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.TLSCertificate(t, "backend.internal")},
+	})
+
+	testData := []struct {
+		name       string
+		serverName string
+		success    bool
+	}{
+		{
+			name:       "Matching name",
+			serverName: "backend.internal",
+			success:    true,
+		},
+		{
+			name:       "Mismatched name",
+			serverName: "other.internal",
+			success:    false,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			client := startClient(t, configapplication.GrpcConfig{
+				GrpcTLSConfig: configapplication.GrpcTLSConfig{
+					TLSMode:    TLSModeTLS,
+					CAFile:     files.CAFile,
+					ServerName: data.serverName,
+				},
+			}, port)
+
+			err := checkSecret(client)
+			if data.success {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestGRPCApplication_TLSPerTargetServerName(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "client")
+
+	certPEM, keyPEM := ca.IssueFor(t, "backend", "localhost")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	port := startTestServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	// The first target is down and its host is not in the certificate of the second one
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	downTarget := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	client := startClient(t, configapplication.GrpcConfig{
+		GrpcTLSConfig: configapplication.GrpcTLSConfig{
+			TLSMode: TLSModeTLS,
+			CAFile:  files.CAFile,
+		},
+		Targets: []string{downTarget, net.JoinHostPort("localhost", strconv.Itoa(port))},
+	}, 0)
+
+	require.Eventually(t, func() bool {
+		return checkSecret(client) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestGRPCApplication_TLSReloadCA(t *testing.T) {
+	serverCA := tlstest.NewCA(t, "server-ca")
+	otherCA := tlstest.NewCA(t, "other-ca")
+	files := otherCA.WriteFiles(t, t.TempDir(), "client")
+
+	port := startTestServer(t, &tls.Config{
+		Certificates: []tls.Certificate{serverCA.TLSCertificate(t, "backend")},
+	})
+
+	client := startClient(t, configapplication.GrpcConfig{
+		GrpcTLSConfig: configapplication.GrpcTLSConfig{
+			TLSMode: TLSModeTLS,
+			CAFile:  files.CAFile,
+		},
+	}, port)
+
+	require.Error(t, checkSecret(client))
+
+	tlstest.WriteFile(t, files.CAFile, serverCA.CertPEM)
+
+	require.Eventually(t, func() bool {
+		return checkSecret(client) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestGRPCApplication_TLSConfigErrors(t *testing.T) {
+	testData := []struct {
+		name string
+		conf configapplication.GrpcTLSConfig
+		err  error
+	}{
+		{
+			name: "Unknown mode",
+			conf: configapplication.GrpcTLSConfig{TLSMode: "ssl"},
+			err:  ErrGRPCTLSUnknownMode,
+		},
+		{
+			name: "Missing client key",
+			conf: configapplication.GrpcTLSConfig{TLSMode: TLSModeMTLS},
+			err:  ErrGRPCTLSNoClientKey,
+		},
+		{
+			name: "Missing CA file",
+			conf: configapplication.GrpcTLSConfig{TLSMode: TLSModeTLS, CAFile: "/nonexistent/ca.pem"},
+			err:  ErrGRPCTLSConfig,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			app := New(slog.Default(), configapplication.GrpcConfig{GrpcTLSConfig: data.conf}, nil)
+			client, err := app.Start("127.0.0.1", 1)
+
+			require.ErrorIs(t, err, ErrGRPCStartError)
+			require.ErrorIs(t, err, data.err)
+			require.Nil(t, client)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package grpcapplication
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+)
+
+var (
+	ErrServiceTokenFetch = errors.New("failed to obtain service token")
+	ErrServiceTokenEmpty = errors.New("service token is empty")
+)
+
+// serviceToken is a gateway service credential with its expiry time
+type serviceToken struct {
+	value  string
+	expiry time.Time
+}
+
+type tokenFetcher func(ctx context.Context) (serviceToken, error)
+
+type refreshCall struct {
+	done  chan struct{}
+	token serviceToken
+	err   error
+}
+
+// TokenSource caches the gateway service credential and refreshes it before expiry.
+// Concurrent refreshes are collapsed into a single fetch. After a failed fetch the error is
+// returned without a new fetch until the jittered exponential backoff passes
+type TokenSource struct {
+	logger        *slog.Logger
+	fetch         tokenFetcher
+	refreshBefore time.Duration
+	fetchTimeout  time.Duration
+	backoff       configapplication.GrpcRetryConfig
+	now           func() time.Time
+
+	mu       sync.Mutex
+	current  *serviceToken
+	inflight *refreshCall
+	failures int
+	lastErr  error
+	retryAt  time.Time
+}
+
+func newTokenSource(
+	logger *slog.Logger,
+	fetch tokenFetcher,
+	refreshBefore time.Duration,
+	fetchTimeout time.Duration,
+	failureBackoff time.Duration,
+	maxFailureBackoff time.Duration,
+) *TokenSource {
+	return &TokenSource{
+		logger:        logger.With("component", "tokensource"),
+		fetch:         fetch,
+		refreshBefore: refreshBefore,
+		fetchTimeout:  fetchTimeout,
+		backoff: configapplication.GrpcRetryConfig{
+			InitialBackoff: failureBackoff,
+			MaxBackoff:     maxFailureBackoff,
+		},
+		now: time.Now,
+	}
+}
+
+// newServiceTokenSource Returns token source configured by conf. Returns nil if service auth is disabled
+func newServiceTokenSource(
+	logger *slog.Logger,
+	conf configapplication.GrpcServiceAuthConfig,
+	client todoprotobufv1.ToDoServiceClient,
+) *TokenSource {
+	var fetch tokenFetcher
+
+	switch {
+	case conf.TokenFile != "":
+		fetch = fileTokenFetcher(conf.TokenFile, conf.TokenTTL)
+	case conf.ClientID != "":
+		fetch = loginTokenFetcher(client, conf.ClientID, conf.ClientSecret, conf.TokenTTL)
+	default:
+		return nil
+	}
+
+	return newTokenSource(logger, fetch, conf.RefreshBefore, conf.FetchTimeout, conf.FailureBackoff, conf.MaxFailureBackoff)
+}
+
+// Token Returns cached service token, refreshing it if it is about to expire
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	if s.current != nil {
+		now := s.now()
+		if now.Add(s.refreshBefore).Before(s.current.expiry) {
+			token := s.current.value
+			s.mu.Unlock()
+			return token, nil
+		}
+
+		if now.Before(s.current.expiry) {
+			// Still valid: refresh in background and keep serving the current token
+			token := s.current.value
+			if !s.backingOffLocked() {
+				s.startRefreshLocked(ctx)
+			}
+			s.mu.Unlock()
+			return token, nil
+		}
+	}
+	if s.backingOffLocked() {
+		err := s.lastErr
+		s.mu.Unlock()
+		return "", err
+	}
+	call := s.startRefreshLocked(ctx)
+	s.mu.Unlock()
+
+	return s.wait(ctx, call)
+}
+
+// Refresh Forces the service token refresh. The stale token is the one rejected by the backend:
+// if another request has already replaced it the current token is returned without a new fetch
+func (s *TokenSource) Refresh(ctx context.Context, stale string) (string, error) {
+	s.mu.Lock()
+	if s.current != nil && s.current.value != stale && s.now().Before(s.current.expiry) {
+		token := s.current.value
+		s.mu.Unlock()
+		return token, nil
+	}
+	if s.backingOffLocked() {
+		err := s.lastErr
+		s.mu.Unlock()
+		return "", err
+	}
+	call := s.startRefreshLocked(ctx)
+	s.mu.Unlock()
+
+	return s.wait(ctx, call)
+}
+
+// backingOffLocked Reports whether the last fetch failed and the next one is not due yet
+func (s *TokenSource) backingOffLocked() bool {
+	return s.lastErr != nil && s.now().Before(s.retryAt)
+}
+
+func (s *TokenSource) startRefreshLocked(ctx context.Context) *refreshCall {
+	if s.inflight != nil {
+		return s.inflight
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	s.inflight = call
+
+	// The fetch must outlive the request that triggered it: other requests may wait for it
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.fetchTimeout)
+
+	go func() {
+		defer cancel()
+		token, err := s.fetch(fetchCtx)
+
+		s.mu.Lock()
+		if err == nil {
+			s.current = &token
+			s.failures = 0
+			s.lastErr = nil
+		} else {
+			s.failures++
+			s.lastErr = err
+			delay := backoff(s.backoff, s.failures)
+			s.retryAt = s.now().Add(delay)
+			s.logger.Error("service token refresh error",
+				slog.Any("err", err),
+				slog.Int("failures", s.failures),
+				slog.Duration("retry_in", delay),
+			)
+		}
+		s.inflight = nil
+		s.mu.Unlock()
+
+		call.token, call.err = token, err
+		close(call.done)
+	}()
+
+	return call
+}
+
+func (s *TokenSource) wait(ctx context.Context, call *refreshCall) (string, error) {
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return "", call.err
+		}
+		return call.token.value, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func loginTokenFetcher(
+	client todoprotobufv1.ToDoServiceClient,
+	clientID string,
+	clientSecret string,
+	ttl time.Duration,
+) tokenFetcher {
+	return func(ctx context.Context) (serviceToken, error) {
+		resp, err := client.Login(withoutServiceAuth(ctx), &todoprotobufv1.LoginRequest{
+			Email:    clientID,
+			Password: clientSecret,
+		})
+
+		if err != nil {
+			return serviceToken{}, errors.Join(ErrServiceTokenFetch, err)
+		}
+
+		return newServiceToken(resp.GetToken(), ttl)
+	}
+}
+
+func fileTokenFetcher(path string, ttl time.Duration) tokenFetcher {
+	return func(_ context.Context) (serviceToken, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return serviceToken{}, errors.Join(ErrServiceTokenFetch, err)
+		}
+
+		return newServiceToken(strings.TrimSpace(string(data)), ttl)
+	}
+}
+
+func newServiceToken(value string, ttl time.Duration) (serviceToken, error) {
+	if value == "" {
+		return serviceToken{}, ErrServiceTokenEmpty
+	}
+
+	expiry, ok := jwtExpiry(value)
+	if !ok {
+		expiry = time.Now().Add(ttl)
+	}
+
+	return serviceToken{
+		value:  value,
+		expiry: expiry,
+	}, nil
+}
+
+// jwtExpiry Returns the exp claim of a JWT without verifying it. Used only to schedule refresh
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp *int64 `json:"exp"`
+	}
+
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(*claims.Exp, 0), true
+}
+
+type skipServiceAuthKey struct{}
+
+// withoutServiceAuth marks ctx so the interceptor does not attach the service token.
+// Used by the token fetcher itself to avoid recursion
+func withoutServiceAuth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipServiceAuthKey{}, true)
+}
+
+func isServiceAuthSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipServiceAuthKey{}).(bool)
+	return skip
+}
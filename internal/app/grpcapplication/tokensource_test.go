@@ -0,0 +1,311 @@
+package grpcapplication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/lib/authcontext"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func countingFetcher(counter *atomic.Int32, ttl time.Duration) tokenFetcher {
+	return func(_ context.Context) (serviceToken, error) {
+		n := counter.Add(1)
+		return serviceToken{
+			value:  "svc-" + string(rune('0'+n)),
+			expiry: time.Now().Add(ttl),
+		}, nil
+	}
+}
+
+func TestTokenSource_CachesToken(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	ctx := context.Background()
+
+	first, err := ts.Token(ctx)
+	require.NoError(t, err)
+
+	second, err := ts.Token(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, first, second)
+	require.Equal(t, int32(1), fetches.Load())
+}
+
+func TestTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	ctx := context.Background()
+
+	first, err := ts.Token(ctx)
+	require.NoError(t, err)
+
+	// Enter the refresh window: the current token is still served while refresh runs
+	ts.now = func() time.Time { return time.Now().Add(time.Hour - 30*time.Second) }
+
+	token, err := ts.Token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, first, token)
+
+	require.Eventually(t, func() bool {
+		return fetches.Load() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTokenSource_FetchesExpiredToken(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	ctx := context.Background()
+
+	first, err := ts.Token(ctx)
+	require.NoError(t, err)
+
+	ts.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	second, err := ts.Token(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+	require.Equal(t, int32(2), fetches.Load())
+}
+
+func TestTokenSource_RefreshSingleFlight(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+
+	fetch := func(ctx context.Context) (serviceToken, error) {
+		<-release
+		return countingFetcher(&fetches, time.Hour)(ctx)
+	}
+
+	ts := newTokenSource(slog.Default(), fetch, time.Minute, time.Second, time.Second, time.Minute)
+	ctx := context.Background()
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := ts.Refresh(ctx, "stale")
+			require.NoError(t, err)
+			results[i] = token
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), fetches.Load())
+	for _, token := range results {
+		require.Equal(t, results[0], token)
+	}
+}
+
+func TestTokenSource_RefreshSkipsAlreadyReplacedToken(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	ctx := context.Background()
+
+	current, err := ts.Token(ctx)
+	require.NoError(t, err)
+
+	token, err := ts.Refresh(ctx, "already-replaced")
+	require.NoError(t, err)
+	require.Equal(t, current, token)
+	require.Equal(t, int32(1), fetches.Load())
+}
+
+func TestTokenSource_FetchError(t *testing.T) {
+	fetchErr := errors.New("auth endpoint is down")
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), func(_ context.Context) (serviceToken, error) {
+		fetches.Add(1)
+		return serviceToken{}, fetchErr
+	}, time.Minute, time.Second, time.Second, time.Minute)
+	ctx := context.Background()
+
+	token, err := ts.Token(ctx)
+	require.ErrorIs(t, err, fetchErr)
+	require.Empty(t, token)
+
+	// The failure is returned without new fetches until the backoff passes
+	_, err = ts.Token(ctx)
+	require.ErrorIs(t, err, fetchErr)
+	_, err = ts.Refresh(ctx, "stale")
+	require.ErrorIs(t, err, fetchErr)
+	require.Equal(t, int32(1), fetches.Load())
+
+	ts.now = func() time.Time { return time.Now().Add(time.Second) }
+
+	_, err = ts.Token(ctx)
+	require.ErrorIs(t, err, fetchErr)
+	require.Equal(t, int32(2), fetches.Load())
+}
+
+func TestServiceTokenSource_Login(t *testing.T) {
+	mock := mocks.New(false)
+	ts := newServiceTokenSource(
+		slog.Default(),
+		configapplication.GrpcServiceAuthConfig{
+			ClientID:      "user1",
+			ClientSecret:  "pass",
+			TokenTTL:      time.Hour,
+			RefreshBefore: time.Minute,
+			FetchTimeout:  time.Second,
+		},
+		mock,
+	)
+
+	token, err := ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1:user1", token)
+}
+
+func TestServiceTokenSource_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("file-token\n"), 0o600))
+
+	ts := newServiceTokenSource(
+		slog.Default(),
+		configapplication.GrpcServiceAuthConfig{
+			TokenFile:     path,
+			TokenTTL:      time.Hour,
+			RefreshBefore: time.Minute,
+			FetchTimeout:  time.Second,
+		},
+		nil,
+	)
+
+	token, err := ts.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "file-token", token)
+}
+
+func TestServiceTokenSource_Disabled(t *testing.T) {
+	ts := newServiceTokenSource(slog.Default(), configapplication.GrpcServiceAuthConfig{}, nil)
+	require.Nil(t, ts)
+}
+
+func TestJWTExpiry(t *testing.T) {
+	// {"alg":"none"}.{"exp":1700000000}
+	token := "eyJhbGciOiJub25lIn0.eyJleHAiOjE3MDAwMDAwMDB9.sig"
+
+	expiry, ok := jwtExpiry(token)
+	require.True(t, ok)
+	require.Equal(t, int64(1700000000), expiry.Unix())
+
+	_, ok = jwtExpiry("1:user1")
+	require.False(t, ok)
+}
+
+func serviceCredentialRejected() error {
+	st, _ := status.New(codes.Unauthenticated, "service token expired").
+		WithDetails(&errdetails.ErrorInfo{Reason: ServiceCredentialRejectedReason})
+	return st.Err()
+}
+
+func TestUnaryInterceptor_RetriesOnceOnRejectedServiceCredential(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+
+	var seen [][]string
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		seen = append(seen, md.Get(ServiceAuthorizationMetadataKey))
+		return serviceCredentialRejected()
+	}
+
+	interceptor := newUnaryInterceptor(func() *TokenSource { return ts })
+	err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker)
+
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Len(t, seen, 2)
+	require.Equal(t, []string{"Bearer svc-1"}, seen[0])
+	require.Equal(t, []string{"Bearer svc-2"}, seen[1])
+	require.Equal(t, int32(2), fetches.Load())
+}
+
+func TestUnaryInterceptor_NoRefreshOnRejectedUserToken(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	mock := mocks.New(false)
+
+	ctx := authcontext.WithBearerToken(context.Background(), "bogus")
+
+	var reply todoprotobufv1.CheckSecretResponce
+	err := newUnaryInterceptor(func() *TokenSource { return ts })(
+		ctx,
+		todoprotobufv1.ToDoService_CheckSecret_FullMethodName,
+		&todoprotobufv1.CheckSecretRequest{Secret: "bogus"},
+		&reply,
+		nil,
+		mock.Invoke,
+	)
+
+	// An invalid user token is not retried and does not refresh the service credential
+	require.Error(t, err)
+	require.Equal(t, 1, mock.Calls("CheckSecret"))
+	require.Equal(t, int32(1), fetches.Load())
+}
+
+func TestUnaryInterceptor_ServiceAndUserTokens(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	mock := mocks.New(false)
+
+	ctx := authcontext.WithBearerToken(context.Background(), "1:user1")
+
+	var reply todoprotobufv1.CheckSecretResponce
+	err := newUnaryInterceptor(func() *TokenSource { return ts })(
+		ctx,
+		todoprotobufv1.ToDoService_CheckSecret_FullMethodName,
+		&todoprotobufv1.CheckSecretRequest{Secret: "1:user1"},
+		&reply,
+		nil,
+		mock.Invoke,
+	)
+
+	require.NoError(t, err)
+	md := mock.OutgoingMetadata("CheckSecret")
+	require.Equal(t, []string{"Bearer 1:user1"}, md.Get(authorizationMetadataKey))
+	require.Equal(t, []string{"Bearer svc-1"}, md.Get(ServiceAuthorizationMetadataKey))
+}
+
+func TestUnaryInterceptor_SkipsServiceAuthForTokenFetch(t *testing.T) {
+	var fetches atomic.Int32
+	ts := newTokenSource(slog.Default(), countingFetcher(&fetches, time.Hour), time.Minute, time.Second, time.Second, time.Minute)
+	mock := mocks.New(false)
+
+	var reply todoprotobufv1.LoginResponce
+	err := newUnaryInterceptor(func() *TokenSource { return ts })(
+		withoutServiceAuth(context.Background()),
+		todoprotobufv1.ToDoService_Login_FullMethodName,
+		&todoprotobufv1.LoginRequest{Email: "user1", Password: "pass"},
+		&reply,
+		nil,
+		mock.Invoke,
+	)
+
+	require.NoError(t, err)
+	require.Empty(t, mock.OutgoingMetadata("Login").Get(ServiceAuthorizationMetadataKey))
+	require.Equal(t, int32(0), fetches.Load())
+}
@@ -2,11 +2,17 @@ package httpapplication
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/http/middlewares/requestidmiddleware"
+	"todoapiservice/internal/lib/tlsreload"
 
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
@@ -18,6 +24,7 @@ var (
 	ErrHttpAppRunError  = errors.New("http app run error")
 	ErrHttpAppNotRun    = errors.New("http app not run")
 	ErrHttpAppStopError = errors.New("http app stop error")
+	ErrHttpAppTLSNoCert = errors.New("http app client CA requires server certificate")
 )
 
 type IMiddleware interface {
@@ -33,54 +40,108 @@ type IItemGetterHandler interface {
 	HandlerGetTaskByID(c *gin.Context)
 }
 
+type IItemSearchHandler interface {
+	HandlerSearchTasks(c *gin.Context)
+}
+
+type IItemBatchHandler interface {
+	HandlerBatchTasks(c *gin.Context)
+}
+
 type IItemUpdateHandler interface {
 	HandlerUpdateTaskByID(c *gin.Context)
+	HandlerReplaceTaskByID(c *gin.Context)
 }
 
 type IItemDeleteHandler interface {
 	HandlerDeleteTaskByID(c *gin.Context)
 }
 
+type ISubtaskHandler interface {
+	HandlerGetSubtasks(c *gin.Context)
+	HandlerCreateSubtask(c *gin.Context)
+	HandlerUpdateSubtask(c *gin.Context)
+}
+
+type IHealthHandler interface {
+	HandlerLiveness(c *gin.Context)
+	HandlerReadiness(c *gin.Context)
+}
+
 type IAuthHandler interface {
 	HandlerLogin(c *gin.Context)
 	HandlerLogout(c *gin.Context)
+	HandlerRefreshToken(c *gin.Context)
 }
 
 type HttpApp struct {
-	logger *slog.Logger
-	router *gin.Engine
-	srv    *http.Server
+	logger  *slog.Logger
+	router  *gin.Engine
+	tlsConf configapplication.ApiTLSConfig
+	srv     *http.Server
 }
 
 func New(
 	logger *slog.Logger,
 	apiBasePath string,
+	tlsConf configapplication.ApiTLSConfig,
 
 	itemCreateHandler IItemCreateHandler,
 	itemGetterHandler IItemGetterHandler,
+	itemSearchHandler IItemSearchHandler,
 	itemUpdateHandler IItemUpdateHandler,
 	itemDeleteHandler IItemDeleteHandler,
+	itemBatchHandler IItemBatchHandler,
+	subtaskHandler ISubtaskHandler,
 	authHandler IAuthHandler,
+	healthHandler IHealthHandler,
 
+	metricsMiddleware IMiddleware,
+	deadlineMiddleware IMiddleware,
 	authMiddleware IMiddleware,
 
 ) *HttpApp {
 
-	router := gin.Default()
+	router := gin.New()
+	// Metrics go first, so recovered panics and unmatched routes are counted too
+	router.Use(
+		metricsMiddleware.Middleware,
+		requestidmiddleware.Middleware,
+		gin.Logger(),
+		gin.CustomRecovery(func(c *gin.Context, _ any) {
+			handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		}),
+	)
+	router.NoRoute(func(c *gin.Context) {
+		handlers.SendProblem(c, http.StatusNotFound, httpdto.ErrorCodeNotFound, "route not found")
+	})
 
 	apiAuth := router.Group(apiBasePath)
 	apiNoAuth := router.Group(apiBasePath)
 
-	apiAuth.Use(authMiddleware.Middleware)
+	// Deadline goes first, so it limits the token check too
+	apiAuth.Use(deadlineMiddleware.Middleware, authMiddleware.Middleware)
+	apiNoAuth.Use(deadlineMiddleware.Middleware)
 
 	apiAuth.POST("/tasks", itemCreateHandler.HandlerCreateTask)
+	// Gin has no literal colon support: `:action` matches `:batch` suffix and the handler checks it
+	apiAuth.POST("/tasks:action", itemBatchHandler.HandlerBatchTasks)
 	apiAuth.GET("/tasks", itemGetterHandler.HandlerGetTaskList)
+	apiAuth.GET("/tasks/search", itemSearchHandler.HandlerSearchTasks)
 	apiAuth.GET("/tasks/:id", itemGetterHandler.HandlerGetTaskByID)
 	apiAuth.PATCH("/tasks/:id", itemUpdateHandler.HandlerUpdateTaskByID)
+	apiAuth.PUT("/tasks/:id", itemUpdateHandler.HandlerReplaceTaskByID)
 	apiAuth.DELETE("/tasks/:id", itemDeleteHandler.HandlerDeleteTaskByID)
+	apiAuth.GET("/tasks/:id/subtasks", subtaskHandler.HandlerGetSubtasks)
+	apiAuth.POST("/tasks/:id/subtasks", subtaskHandler.HandlerCreateSubtask)
+	apiAuth.PATCH("/tasks/:id/subtasks/:subtask_id", subtaskHandler.HandlerUpdateSubtask)
 	apiAuth.GET("/logout", authHandler.HandlerLogout)
 
 	apiNoAuth.POST("/login", authHandler.HandlerLogin)
+	apiNoAuth.POST("/token/refresh", authHandler.HandlerRefreshToken)
+
+	router.GET("/healthz", healthHandler.HandlerLiveness)
+	router.GET("/readyz", healthHandler.HandlerReadiness)
 
 	router.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/docs/index.html")
@@ -88,20 +149,87 @@ func New(
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return &HttpApp{
-		logger: logger.With(slog.String("module", "httpapplication")),
-		router: router,
+		logger:  logger.With(slog.String("module", "httpapplication")),
+		router:  router,
+		tlsConf: tlsConf,
+	}
+}
+
+// Handle Registers GET handler outside the API base path, e.g. metrics served on the API port
+func (app *HttpApp) Handle(path string, handler http.Handler) {
+	app.router.GET(path, gin.WrapH(handler))
+}
+
+// serverTLSConfig Returns TLS config with certificates reloaded on file change. Returns nil if TLS is disabled
+func (app *HttpApp) serverTLSConfig() (*tls.Config, error) {
+	if app.tlsConf.CertFile == "" && app.tlsConf.KeyFile == "" {
+		if app.tlsConf.ClientCAFile != "" {
+			return nil, ErrHttpAppTLSNoCert
+		}
+		return nil, nil
 	}
+
+	keyPair, err := tlsreload.NewKeyPair(
+		app.logger,
+		app.tlsConf.CertFile,
+		app.tlsConf.KeyFile,
+		app.tlsConf.ReloadInterval,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConf := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: keyPair.GetCertificate,
+	}
+
+	if app.tlsConf.ClientCAFile != "" {
+		caPool, err := tlsreload.NewCertPool(
+			app.logger,
+			app.tlsConf.ClientCAFile,
+			app.tlsConf.ReloadInterval,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConf.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			clientConf := tlsConf.Clone()
+			clientConf.GetConfigForClient = nil
+			clientConf.ClientCAs = caPool.Pool()
+			return clientConf, nil
+		}
+	}
+
+	return tlsConf, nil
 }
 
 func (app *HttpApp) Run(host string, port int) error {
 
+	tlsConf, err := app.serverTLSConfig()
+	if err != nil {
+		app.logger.Error("http app TLS config error", slog.Any("err", err))
+		return errors.Join(ErrHttpAppRunError, err)
+	}
+
 	srv := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", host, port),
-		Handler: app.router.Handler(),
+		Addr:      fmt.Sprintf("%s:%d", host, port),
+		Handler:   app.router.Handler(),
+		TLSConfig: tlsConf,
 	}
 
 	app.srv = srv
-	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+
+	if tlsConf != nil {
+		// Certificates are provided by TLSConfig.GetCertificate
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		app.logger.Error("http app run error", slog.Any("err", err))
 		return errors.Join(ErrHttpAppRunError, err)
 	}
@@ -0,0 +1,222 @@
+package httpapplication
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/lib/tlsreload/tlstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type stubHandlers struct{}
+
+func (stubHandlers) HandlerCreateTask(c *gin.Context)      { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerGetTaskList(c *gin.Context)     { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerGetTaskByID(c *gin.Context)     { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerSearchTasks(c *gin.Context)     { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerUpdateTaskByID(c *gin.Context)  { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerReplaceTaskByID(c *gin.Context) { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerDeleteTaskByID(c *gin.Context)  { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerBatchTasks(c *gin.Context)      { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerGetSubtasks(c *gin.Context)     { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerCreateSubtask(c *gin.Context)   { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerUpdateSubtask(c *gin.Context)   { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerLogin(c *gin.Context)           { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerLogout(c *gin.Context)          { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerRefreshToken(c *gin.Context)    { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerLiveness(c *gin.Context)        { c.Status(http.StatusOK) }
+func (stubHandlers) HandlerReadiness(c *gin.Context)       { c.Status(http.StatusOK) }
+func (stubHandlers) Middleware(c *gin.Context)             { c.Next() }
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+// runApp Starts the app and waits until it accepts connections
+func runApp(t *testing.T, tlsConf configapplication.ApiTLSConfig) int {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	h := stubHandlers{}
+	app := New(slog.Default(), "/api/v1/", tlsConf, h, h, h, h, h, h, h, h, h, h, h, h)
+
+	port := freePort(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run("127.0.0.1", port)
+	}()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, app.Stop(ctx))
+		require.NoError(t, <-runErr)
+	})
+
+	return port
+}
+
+func httpsGet(port int, roots *x509.CertPool, clientCert *tls.Certificate) (*x509.Certificate, error) {
+	tlsConf := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		tlsConf.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConf, DisableKeepAlives: true},
+		Timeout:   2 * time.Second,
+	}
+
+	resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/api/v1/login", port))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotFound {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.TLS.PeerCertificates[0], nil
+}
+
+func TestHttpApp_HTTPS(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "gateway")
+
+	port := runApp(t, configapplication.ApiTLSConfig{
+		CertFile: files.CertFile,
+		KeyFile:  files.KeyFile,
+	})
+
+	_, err := httpsGet(port, ca.Pool(), nil)
+	require.NoError(t, err)
+}
+
+func TestHttpApp_HTTPSReloadCertificate(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "gateway")
+
+	port := runApp(t, configapplication.ApiTLSConfig{
+		CertFile: files.CertFile,
+		KeyFile:  files.KeyFile,
+	})
+
+	first, err := httpsGet(port, ca.Pool(), nil)
+	require.NoError(t, err)
+
+	certPEM, keyPEM := ca.Issue(t, "gateway")
+	tlstest.WriteFile(t, files.CertFile, certPEM)
+	tlstest.WriteFile(t, files.KeyFile, keyPEM)
+
+	second, err := httpsGet(port, ca.Pool(), nil)
+	require.NoError(t, err)
+	require.NotEqual(t, first.SerialNumber, second.SerialNumber)
+}
+
+func TestHttpApp_MutualTLS(t *testing.T) {
+	serverCA := tlstest.NewCA(t, "server-ca")
+	clientCA := tlstest.NewCA(t, "client-ca")
+	otherCA := tlstest.NewCA(t, "other-ca")
+
+	dir := t.TempDir()
+	serverFiles := serverCA.WriteFiles(t, dir, "gateway")
+	clientFiles := clientCA.WriteFiles(t, dir, "client")
+
+	port := runApp(t, configapplication.ApiTLSConfig{
+		CertFile:     serverFiles.CertFile,
+		KeyFile:      serverFiles.KeyFile,
+		ClientCAFile: clientFiles.CAFile,
+	})
+
+	trustedCert := clientCA.TLSCertificate(t, "client")
+	untrustedCert := otherCA.TLSCertificate(t, "client")
+
+	testData := []struct {
+		name    string
+		cert    *tls.Certificate
+		success bool
+	}{
+		{
+			name:    "Trusted client certificate",
+			cert:    &trustedCert,
+			success: true,
+		},
+		{
+			name:    "Untrusted client certificate",
+			cert:    &untrustedCert,
+			success: false,
+		},
+		{
+			name:    "No client certificate",
+			cert:    nil,
+			success: false,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			_, err := httpsGet(port, serverCA.Pool(), data.cert)
+			if data.success {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	// Client CA bundle change is picked up without restart
+	tlstest.WriteFile(t, clientFiles.CAFile, otherCA.CertPEM)
+
+	_, err := httpsGet(port, serverCA.Pool(), &untrustedCert)
+	require.NoError(t, err)
+}
+
+func TestHttpApp_TLSConfigErrors(t *testing.T) {
+	testData := []struct {
+		name string
+		conf configapplication.ApiTLSConfig
+	}{
+		{
+			name: "Client CA without server certificate",
+			conf: configapplication.ApiTLSConfig{ClientCAFile: "/nonexistent/ca.pem"},
+		},
+		{
+			name: "Missing certificate files",
+			conf: configapplication.ApiTLSConfig{CertFile: "/nonexistent/cert.pem", KeyFile: "/nonexistent/key.pem"},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			h := stubHandlers{}
+			app := New(slog.Default(), "/api/v1/", data.conf, h, h, h, h, h, h, h, h, h, h, h, h)
+
+			err := app.Run("127.0.0.1", freePort(t))
+			require.ErrorIs(t, err, ErrHttpAppRunError)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package metricsapplication implements admin HTTP server exposing metrics on a separate port
+package metricsapplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+var (
+	ErrMetricsAppRunError  = errors.New("metrics app run error")
+	ErrMetricsAppNotRun    = errors.New("metrics app not run")
+	ErrMetricsAppStopError = errors.New("metrics app stop error")
+)
+
+type MetricsApp struct {
+	logger *slog.Logger
+	mux    *http.ServeMux
+	srv    *http.Server
+}
+
+func New(logger *slog.Logger, path string, handler http.Handler) *MetricsApp {
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+
+	return &MetricsApp{
+		logger: logger.With(slog.String("module", "metricsapplication")),
+		mux:    mux,
+	}
+}
+
+func (app *MetricsApp) Run(host string, port int) error {
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", host, port),
+		Handler: app.mux,
+	}
+
+	app.srv = srv
+
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		app.logger.Error("metrics app run error", slog.Any("err", err))
+		return errors.Join(ErrMetricsAppRunError, err)
+	}
+
+	return nil
+}
+
+func (app *MetricsApp) Stop(ctx context.Context) error {
+	if app.srv == nil {
+		return ErrMetricsAppNotRun
+	}
+
+	err := app.srv.Shutdown(ctx)
+	if err != nil {
+		app.logger.Error("metrics app stop error", slog.Any("err", err))
+		return errors.Join(ErrMetricsAppStopError, err)
+	}
+
+	return nil
+}
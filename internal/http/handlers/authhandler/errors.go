@@ -0,0 +1,23 @@
+package authhandler
+
+import (
+	"net/http"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/authprovider"
+)
+
+// errorMappings are problems sent for errors of the services this package calls
+var errorMappings = []handlers.ErrorMapping{
+	{Err: authprovider.ErrBadCredentials, Status: http.StatusUnauthorized, Code: httpdto.ErrorCodeBadCredentials, Detail: "login or password is incorrect"},
+	{Err: authprovider.ErrAccountLocked, Status: http.StatusLocked, Code: httpdto.ErrorCodeAccountLocked, Detail: "account is locked"},
+	{Err: authprovider.ErrRefreshTokenInvalid, Status: http.StatusUnauthorized, Code: httpdto.ErrorCodeInvalidRefreshToken, Detail: "refresh token is invalid or expired"},
+	{Err: authprovider.ErrBackendUnavailable, Status: http.StatusServiceUnavailable, Code: httpdto.ErrorCodeBackendUnavailable, Detail: "authentication service is unavailable"},
+	{Err: authprovider.ErrBackendTimeout, Status: http.StatusGatewayTimeout, Code: httpdto.ErrorCodeBackendTimeout, Detail: "authentication service did not respond in time"},
+	{Err: authprovider.ErrAuthInternal, Status: http.StatusInternalServerError, Code: httpdto.ErrorCodeInternal},
+	{Err: authprovider.ErrPermissionDenied, Status: http.StatusUnauthorized, Code: httpdto.ErrorCodeInvalidToken, Detail: "token is invalid or expired"},
+}
+
+func init() {
+	handlers.RegisterErrors(errorMappings...)
+}
@@ -5,12 +5,14 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"todoapiservice/internal/http/handlers"
 	"todoapiservice/internal/http/httpdto"
 	"todoapiservice/internal/services/coredto"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type IAuthenticator interface {
@@ -18,53 +20,140 @@ type IAuthenticator interface {
 	Logout(ctx context.Context, user coredto.User) error
 }
 
+type ITokenIssuer interface {
+	IssueTokens(ctx context.Context, user coredto.User) (*coredto.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*coredto.TokenPair, error)
+}
+
 type AuthHandler struct {
 	logging       *slog.Logger
 	authenticator IAuthenticator
+	tokenIssuer   ITokenIssuer
 }
 
 func New(
 	logging *slog.Logger,
 	authenticator IAuthenticator,
+	tokenIssuer ITokenIssuer,
 ) *AuthHandler {
 	return &AuthHandler{
 		logging:       logging.With("module", "authhandler"),
 		authenticator: authenticator,
+		tokenIssuer:   tokenIssuer,
+	}
+}
+
+func sendTokens(c *gin.Context, tokens *coredto.TokenPair) {
+	now := time.Now()
+
+	c.IndentedJSON(http.StatusOK, httpdto.LoginResponse{
+		GeneralResponse: httpdto.GeneralResponse{
+			Status: httpdto.StatusOK,
+		},
+		Token:            *tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(tokens.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshToken:     *tokens.RefreshToken,
+		RefreshExpiresIn: int64(tokens.RefreshExpiresAt.Sub(now).Seconds()),
+	})
+}
+
+// credentials Reads login credentials from Basic auth header or from JSON/form body.
+// Returns false if the response has already been sent
+func credentials(c *gin.Context) (string, string, bool) {
+	if email, pass, hasAuth := c.Request.BasicAuth(); hasAuth {
+		return email, pass, true
+	}
+
+	switch c.ContentType() {
+	case binding.MIMEJSON, binding.MIMEPOSTForm:
+		var request httpdto.LoginRequest
+		err := c.ShouldBind(&request)
+		if err != nil {
+			handlers.SendValidationErrorResponse(c, request, err)
+			return "", "", false
+		}
+		return request.Email, request.Password, true
 	}
+
+	c.Writer.Header().Set("WWW-Authenticate", "Basic realm=Restricted")
+	handlers.SendProblem(c, http.StatusUnauthorized, httpdto.ErrorCodeUnauthorized, "credentials are required")
+	return "", "", false
 }
 
 // HandlerLogin
 // @Summary 	User login
+// @Description Credentials are accepted as HTTP Basic auth header,
+// @Description JSON body or urlencoded form body with `email` and `password` fields.
+// @Description Basic auth header takes precedence over the body
 // @Router 		/login [POST]
 // @Tags 		Auth
+// @Accept		json,x-www-form-urlencoded
 // @Produce		json
 // @Security 	BasicAuth
+// @Param 		request body LoginRequest false "Credentials (JSON or form body)"
 // @Success 200 {object} LoginResponse
-// @Failure 401 {object} GeneralResponse
-// @Failure 500 {object} GeneralResponse
+// @Failure 400 {object} Problem "bad_request, validation_failed"
+// @Failure 401 {object} Problem "unauthorized, bad_credentials"
+// @Failure 423 {object} Problem "account_locked"
+// @Failure 500 {object} Problem "internal_error"
+// @Failure 503 {object} Problem "backend_unavailable"
+// @Failure 504 {object} Problem "backend_timeout"
 func (h *AuthHandler) HandlerLogin(c *gin.Context) {
 
-	email, pass, hasAuth := c.Request.BasicAuth()
+	email, pass, ok := credentials(c)
 
-	if !hasAuth {
-		c.Writer.Header().Set("WWW-Authenticate", "Basic realm=Restricted")
-		handlers.SendErrorResponse(c, http.StatusUnauthorized)
+	if !ok {
 		return
 	}
 
 	user, err := h.authenticator.Login(c.Request.Context(), email, pass)
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendServiceError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, httpdto.LoginResponse{
-		GeneralResponse: httpdto.GeneralResponse{
-			Status: httpdto.StatusOK,
-		},
-		Token: *user.JWT,
-	})
+	tokens, err := h.tokenIssuer.IssueTokens(c.Request.Context(), *user)
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	sendTokens(c, tokens)
+}
+
+// HandlerRefreshToken
+// @Summary 	Exchange refresh token for a new token pair
+// @Router 		/token/refresh [POST]
+// @Tags 		Auth
+// @Accept		json
+// @Produce		json
+// @Param 		request body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} Problem "bad_request, validation_failed"
+// @Failure 401 {object} Problem "invalid_refresh_token"
+// @Failure 500 {object} Problem "internal_error"
+// @Failure 503 {object} Problem "backend_unavailable"
+// @Failure 504 {object} Problem "backend_timeout"
+func (h *AuthHandler) HandlerRefreshToken(c *gin.Context) {
+	var request httpdto.RefreshTokenRequest
+
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, request, err)
+		return
+	}
+
+	tokens, err := h.tokenIssuer.Refresh(c.Request.Context(), request.RefreshToken)
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	sendTokens(c, tokens)
 }
 
 // HandlerLogout
@@ -74,8 +163,11 @@ func (h *AuthHandler) HandlerLogin(c *gin.Context) {
 // @Tags 		Auth
 // @Produce		json
 // @Success 200 {object} GeneralResponse
-// @Failure 401 {object} GeneralResponse
-// @Failure 500 {object} GeneralResponse
+// @Failure 400 {object} Problem "invalid_authorization_header"
+// @Failure 401 {object} Problem "unauthorized, invalid_token"
+// @Failure 500 {object} Problem "internal_error"
+// @Failure 503 {object} Problem "backend_unavailable"
+// @Failure 504 {object} Problem "backend_timeout"
 func (h *AuthHandler) HandlerLogout(c *gin.Context) {
 	token := c.GetString("jwtToken")
 
@@ -86,7 +178,7 @@ func (h *AuthHandler) HandlerLogout(c *gin.Context) {
 		})
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendServiceError(c, err)
 		return
 	}
 
@@ -0,0 +1,227 @@
+package authhandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/authprovider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(isDown bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	provider := authprovider.New(
+		slog.Default(),
+		mocks.New(isDown),
+		configapplication.AuthTokensConfig{
+			AccessTokenTTL:  time.Minute,
+			RefreshTokenTTL: time.Hour,
+		},
+	)
+	h := New(slog.Default(), provider, provider)
+
+	router := gin.New()
+	router.POST("/login", h.HandlerLogin)
+	return router
+}
+
+func TestAuthHandler_HandlerLogin(t *testing.T) {
+	router := newTestRouter(false)
+
+	testData := []struct {
+		name        string
+		contentType string
+		body        string
+		basicAuth   bool
+		code        int
+		fields      []string
+	}{
+		{
+			name:      "Basic auth",
+			basicAuth: true,
+			code:      http.StatusOK,
+		},
+		{
+			name:        "JSON body",
+			contentType: "application/json",
+			body:        `{"email":"user1","password":"pass"}`,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "Form body",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"email": {"user1"}, "password": {"pass"}}.Encode(),
+			code:        http.StatusOK,
+		},
+		{
+			name:        "Basic auth takes precedence",
+			contentType: "application/json",
+			body:        `{}`,
+			basicAuth:   true,
+			code:        http.StatusOK,
+		},
+		{
+			name:        "JSON missing fields",
+			contentType: "application/json",
+			body:        `{}`,
+			code:        http.StatusBadRequest,
+			fields:      []string{"email", "password"},
+		},
+		{
+			name:        "Form missing password",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"email": {"user1"}}.Encode(),
+			code:        http.StatusBadRequest,
+			fields:      []string{"password"},
+		},
+		{
+			name:        "Too long email",
+			contentType: "application/json",
+			body:        `{"email":"` + strings.Repeat("a", 255) + `","password":"pass"}`,
+			code:        http.StatusBadRequest,
+			fields:      []string{"email"},
+		},
+		{
+			name:        "Malformed JSON",
+			contentType: "application/json",
+			body:        `{"email":`,
+			code:        http.StatusBadRequest,
+		},
+		{
+			name: "No credentials",
+			code: http.StatusUnauthorized,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(data.body))
+			if data.contentType != "" {
+				req.Header.Set("Content-Type", data.contentType)
+			}
+			if data.basicAuth {
+				req.SetBasicAuth("user1", "pass")
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, data.code, w.Code)
+
+			switch data.code {
+			case http.StatusOK:
+				var resp httpdto.LoginResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, "1:user1", resp.Token)
+			case http.StatusBadRequest:
+				require.Equal(t, httpdto.ProblemContentType, w.Header().Get("Content-Type"))
+
+				var resp httpdto.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, http.StatusBadRequest, resp.Status)
+
+				var fields []string
+				for _, fe := range resp.Errors {
+					fields = append(fields, fe.Field)
+				}
+				require.Equal(t, data.fields, fields)
+
+				if len(data.fields) == 0 {
+					require.Equal(t, httpdto.ErrorCodeBadRequest, resp.Code)
+				} else {
+					require.Equal(t, httpdto.ErrorCodeValidationFailed, resp.Code)
+				}
+			case http.StatusUnauthorized:
+				require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthHandler_HandlerLogin_Errors(t *testing.T) {
+	testData := []struct {
+		name     string
+		isDown   bool
+		email    string
+		password string
+		timeout  time.Duration
+		code     int
+		errCode  httpdto.ErrorCode
+	}{
+		{
+			name:     "Bad password",
+			email:    "user1",
+			password: "invalid",
+			code:     http.StatusUnauthorized,
+			errCode:  httpdto.ErrorCodeBadCredentials,
+		},
+		{
+			name:     "Unknown user",
+			email:    "nobody",
+			password: "pass",
+			code:     http.StatusUnauthorized,
+			errCode:  httpdto.ErrorCodeBadCredentials,
+		},
+		{
+			name:     "Locked account",
+			email:    "locked",
+			password: "pass",
+			code:     http.StatusLocked,
+			errCode:  httpdto.ErrorCodeAccountLocked,
+		},
+		{
+			name:     "Backend unavailable",
+			isDown:   true,
+			email:    "user1",
+			password: "pass",
+			code:     http.StatusServiceUnavailable,
+			errCode:  httpdto.ErrorCodeBackendUnavailable,
+		},
+		{
+			name:     "Backend timeout",
+			email:    "slow",
+			password: "pass",
+			timeout:  50 * time.Millisecond,
+			code:     http.StatusGatewayTimeout,
+			errCode:  httpdto.ErrorCodeBackendTimeout,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router := newTestRouter(data.isDown)
+
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.SetBasicAuth(data.email, data.password)
+			if data.timeout > 0 {
+				ctx, cancel := context.WithTimeout(req.Context(), data.timeout)
+				defer cancel()
+				req = req.WithContext(ctx)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, data.code, w.Code)
+
+			var resp httpdto.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, data.code, resp.Status)
+			require.Equal(t, data.errCode, resp.Code)
+			require.Equal(t, httpdto.ProblemTypePrefix+string(data.errCode), resp.Type)
+			require.Equal(t, "/login", resp.Instance)
+		})
+	}
+}
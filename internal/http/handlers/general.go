@@ -2,15 +2,122 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
 	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/lib/requestid"
+	"todoapiservice/internal/lib/retryafter"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SendErrorResponse(c *gin.Context, code int) {
-	c.IndentedJSON(
-		code,
-		httpdto.GeneralResponse{
-			Status: httpdto.StatusError,
-		})
+// SendProblem Sends application/problem+json error response and aborts the handlers chain
+func SendProblem(c *gin.Context, status int, code httpdto.ErrorCode, detail string) {
+	sendProblem(c, newProblem(c, status, code, detail))
+}
+
+// SendErrorResponse Sends error response with the default error code for status
+func SendErrorResponse(c *gin.Context, status int) {
+	SendProblem(c, status, defaultErrorCode(status), "")
+}
+
+// ServiceProblem Returns problem matching service layer error
+func ServiceProblem(c *gin.Context, err error) httpdto.Problem {
+	status, code, detail := classifyError(err)
+	return newProblem(c, status, code, detail)
+}
+
+// SendServiceError Sends error response matching service layer error.
+// Unavailable backend responses carry Retry-After when the backend delay is known
+func SendServiceError(c *gin.Context, err error) {
+	problem := ServiceProblem(c, err)
+	if problem.Status == http.StatusServiceUnavailable {
+		if delay, ok := retryafter.FromError(err); ok {
+			c.Header(retryafter.HeaderName, retryafter.HeaderValue(delay))
+		}
+	}
+	sendProblem(c, problem)
+}
+
+func newProblem(c *gin.Context, status int, code httpdto.ErrorCode, detail string) httpdto.Problem {
+	return httpdto.Problem{
+		Type:      httpdto.ProblemTypePrefix + string(code),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: requestid.FromContext(c.Request.Context()),
+	}
+}
+
+func sendProblem(c *gin.Context, problem httpdto.Problem) {
+	c.Header("Content-Type", httpdto.ProblemContentType)
+	c.IndentedJSON(problem.Status, problem)
+	c.Abort()
+}
+
+func defaultErrorCode(status int) httpdto.ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return httpdto.ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return httpdto.ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		return httpdto.ErrorCodeNotFound
+	case http.StatusServiceUnavailable:
+		return httpdto.ErrorCodeBackendUnavailable
+	case http.StatusGatewayTimeout:
+		return httpdto.ErrorCodeBackendTimeout
+	default:
+		return httpdto.ErrorCodeInternal
+	}
+}
+
+// ErrorMapping Describes problem sent for a service layer error
+type ErrorMapping struct {
+	Err    error
+	Status int
+	Code   httpdto.ErrorCode
+	// Detail is a client safe problem detail, ignored if ExposeError is set
+	Detail string
+	// ExposeError sends the error message as the detail
+	ExposeError bool
+}
+
+var (
+	errorMappingsMu sync.RWMutex
+	errorMappings   []ErrorMapping
+)
+
+// RegisterErrors Adds problems for service layer errors, the first registered match wins.
+// Handler packages register errors of the services they call from init
+func RegisterErrors(mappings ...ErrorMapping) {
+	errorMappingsMu.Lock()
+	defer errorMappingsMu.Unlock()
+
+	errorMappings = append(errorMappings, mappings...)
+}
+
+// classifyError Maps service layer error to HTTP status, error code and client safe detail
+func classifyError(err error) (int, httpdto.ErrorCode, string) {
+	errorMappingsMu.RLock()
+	defer errorMappingsMu.RUnlock()
+
+	for _, mapping := range errorMappings {
+		if !errors.Is(err, mapping.Err) {
+			continue
+		}
+		if mapping.ExposeError {
+			return mapping.Status, mapping.Code, err.Error()
+		}
+		return mapping.Status, mapping.Code, mapping.Detail
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, httpdto.ErrorCodeRequestTimeout, "request deadline exceeded"
+	}
+	return http.StatusInternalServerError, httpdto.ErrorCodeInternal, ""
 }
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/lib/retryafter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errTestUnavailable = errors.New("test backend unavailable")
+	errTestTimeout     = errors.New("test backend timeout")
+)
+
+func init() {
+	RegisterErrors(
+		ErrorMapping{Err: errTestUnavailable, Status: http.StatusServiceUnavailable, Code: httpdto.ErrorCodeBackendUnavailable},
+		ErrorMapping{Err: errTestTimeout, Status: http.StatusGatewayTimeout, Code: httpdto.ErrorCodeBackendTimeout},
+	)
+}
+
+func TestSendServiceError_RetryAfter(t *testing.T) {
+	testData := []struct {
+		name       string
+		err        error
+		code       int
+		errCode    httpdto.ErrorCode
+		retryAfter string
+	}{
+		{
+			name:       "Open circuit breaker",
+			err:        errors.Join(errTestUnavailable, retryafter.Unavailable("circuit breaker is open", 1500*time.Millisecond)),
+			code:       http.StatusServiceUnavailable,
+			errCode:    httpdto.ErrorCodeBackendUnavailable,
+			retryAfter: "2",
+		},
+		{
+			name:    "Unavailable without delay",
+			err:     errors.Join(errTestUnavailable, status.Error(codes.Unavailable, "down")),
+			code:    http.StatusServiceUnavailable,
+			errCode: httpdto.ErrorCodeBackendUnavailable,
+		},
+		{
+			name:    "Timeout",
+			err:     errors.Join(errTestTimeout, status.Error(codes.DeadlineExceeded, "timeout")),
+			code:    http.StatusGatewayTimeout,
+			errCode: httpdto.ErrorCodeBackendTimeout,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+
+			SendServiceError(c, data.err)
+
+			require.Equal(t, data.code, w.Code)
+			require.Contains(t, w.Body.String(), string(data.errCode))
+			require.Equal(t, data.retryAfter, w.Header().Get(retryafter.HeaderName))
+		})
+	}
+}
+
+func TestClassifyError_Registered(t *testing.T) {
+	errExposed := errors.New("operation 2 is invalid")
+	RegisterErrors(ErrorMapping{Err: errExposed, Status: http.StatusBadRequest, Code: httpdto.ErrorCodeInvalidOperation, ExposeError: true})
+
+	status, code, detail := classifyError(errExposed)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, httpdto.ErrorCodeInvalidOperation, code)
+	require.Equal(t, errExposed.Error(), detail)
+
+	status, code, _ = classifyError(errors.Join(errors.New("unregistered"), context.DeadlineExceeded))
+	require.Equal(t, http.StatusGatewayTimeout, status)
+	require.Equal(t, httpdto.ErrorCodeRequestTimeout, code)
+
+	status, code, detail = classifyError(errors.New("unregistered"))
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, httpdto.ErrorCodeInternal, code)
+	require.Empty(t, detail)
+}
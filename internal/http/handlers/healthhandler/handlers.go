@@ -0,0 +1,80 @@
+// Package healthhandler implements liveness and readiness http handlers
+package healthhandler
+
+import (
+	"context"
+	"net/http"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IReadinessChecker interface {
+	Check(ctx context.Context) coredto.Readiness
+}
+
+type HealthHandlers struct {
+	readinessChecker IReadinessChecker
+}
+
+func New(readinessChecker IReadinessChecker) *HealthHandlers {
+	return &HealthHandlers{
+		readinessChecker: readinessChecker,
+	}
+}
+
+func dependencyStatus(ready bool) httpdto.GeneralResponseStatus {
+	if ready {
+		return httpdto.StatusOK
+	}
+	return httpdto.StatusError
+}
+
+// HandlerLiveness Answers 200 while the process serves HTTP
+// @Summary 	Liveness probe
+// @Description Served at the server root, outside the API base path
+// @Router 		/healthz [GET]
+// @Tags 		Health
+// @Produce		json
+// @Success 200 {object} GeneralResponse
+func (h *HealthHandlers) HandlerLiveness(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, httpdto.GeneralResponse{
+		Status: httpdto.StatusOK,
+	})
+}
+
+// HandlerReadiness Answers 200 if all dependencies are ready, 503 otherwise or while draining before shutdown
+// @Summary 	Readiness probe
+// @Description Served at the server root, outside the API base path.
+// @Description Fails while a dependency is not ready or the service drains before shutdown
+// @Router 		/readyz [GET]
+// @Tags 		Health
+// @Produce		json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse "dependency not ready or draining"
+func (h *HealthHandlers) HandlerReadiness(c *gin.Context) {
+	report := h.readinessChecker.Check(c.Request.Context())
+
+	dependencies := make(map[string]httpdto.DependencyStatus, len(report.Dependencies))
+	for _, dependency := range report.Dependencies {
+		dependencies[dependency.Name] = httpdto.DependencyStatus{
+			Status: dependencyStatus(dependency.Ready),
+			Detail: dependency.Detail,
+		}
+	}
+
+	code := http.StatusOK
+	if !report.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.IndentedJSON(code, httpdto.ReadinessResponse{
+		GeneralResponse: httpdto.GeneralResponse{
+			Status: dependencyStatus(report.Ready),
+		},
+		Draining:     report.Draining,
+		CheckedAt:    report.CheckedAt,
+		Dependencies: dependencies,
+	})
+}
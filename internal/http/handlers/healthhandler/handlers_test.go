@@ -0,0 +1,107 @@
+package healthhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type stubChecker struct {
+	report coredto.Readiness
+}
+
+func (c stubChecker) Check(context.Context) coredto.Readiness {
+	return c.report
+}
+
+func TestHealthHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checkedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testData := []struct {
+		name     string
+		report   coredto.Readiness
+		code     int
+		expected httpdto.ReadinessResponse
+	}{
+		{
+			name: "Ready",
+			report: coredto.Readiness{
+				Ready:     true,
+				CheckedAt: checkedAt,
+				Dependencies: []coredto.DependencyStatus{
+					{Name: "grpc_connection", Ready: true, Detail: "READY"},
+					{Name: "todo_backend", Ready: true, Detail: "SERVING"},
+				},
+			},
+			code: http.StatusOK,
+			expected: httpdto.ReadinessResponse{
+				GeneralResponse: httpdto.GeneralResponse{Status: httpdto.StatusOK},
+				CheckedAt:       checkedAt,
+				Dependencies: map[string]httpdto.DependencyStatus{
+					"grpc_connection": {Status: httpdto.StatusOK, Detail: "READY"},
+					"todo_backend":    {Status: httpdto.StatusOK, Detail: "SERVING"},
+				},
+			},
+		},
+		{
+			name: "Dependency failed",
+			report: coredto.Readiness{
+				CheckedAt: checkedAt,
+				Dependencies: []coredto.DependencyStatus{
+					{Name: "grpc_connection", Detail: "TRANSIENT_FAILURE"},
+				},
+			},
+			code: http.StatusServiceUnavailable,
+			expected: httpdto.ReadinessResponse{
+				GeneralResponse: httpdto.GeneralResponse{Status: httpdto.StatusError},
+				CheckedAt:       checkedAt,
+				Dependencies: map[string]httpdto.DependencyStatus{
+					"grpc_connection": {Status: httpdto.StatusError, Detail: "TRANSIENT_FAILURE"},
+				},
+			},
+		},
+		{
+			name:   "Draining",
+			report: coredto.Readiness{Draining: true, CheckedAt: checkedAt},
+			code:   http.StatusServiceUnavailable,
+			expected: httpdto.ReadinessResponse{
+				GeneralResponse: httpdto.GeneralResponse{Status: httpdto.StatusError},
+				Draining:        true,
+				CheckedAt:       checkedAt,
+				Dependencies:    map[string]httpdto.DependencyStatus{},
+			},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			h := New(stubChecker{report: data.report})
+			router := gin.New()
+			router.GET("/healthz", h.HandlerLiveness)
+			router.GET("/readyz", h.HandlerReadiness)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, data.code, w.Code)
+
+			var resp httpdto.ReadinessResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, data.expected, resp)
+
+			// Liveness does not depend on readiness
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
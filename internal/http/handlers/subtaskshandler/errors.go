@@ -0,0 +1,18 @@
+package subtaskshandler
+
+import (
+	"net/http"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/todoprovider"
+)
+
+// errorMappings are problems sent for errors of the services this package calls
+var errorMappings = []handlers.ErrorMapping{
+	{Err: todoprovider.ErrSubtaskNotFound, Status: http.StatusNotFound, Code: httpdto.ErrorCodeSubtaskNotFound, Detail: "subtask not found"},
+	{Err: todoprovider.ErrTooManySubtasks, Status: http.StatusUnprocessableEntity, Code: httpdto.ErrorCodeTooManySubtasks, ExposeError: true},
+}
+
+func init() {
+	handlers.RegisterErrors(errorMappings...)
+}
@@ -0,0 +1,241 @@
+// Package subtaskshandler implements task subtasks http handlers
+package subtaskshandler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/coredto"
+)
+
+type ISubtaskCreator interface {
+	CreateSubtask(ctx context.Context, subtask coredto.ToDoSubtask) (*coredto.ToDoSubtask, error)
+}
+
+type ISubtaskGetter interface {
+	GetSubtasks(ctx context.Context, owner coredto.User, parentID uint64) ([]coredto.ToDoSubtask, error)
+}
+
+type ISubtaskUpdater interface {
+	UpdateSubtask(ctx context.Context, subtask coredto.ToDoSubtask) (*coredto.ToDoSubtask, error)
+}
+
+type SubtaskHandlers struct {
+	logging        *slog.Logger
+	subtaskCreator ISubtaskCreator
+	subtaskGetter  ISubtaskGetter
+	subtaskUpdater ISubtaskUpdater
+}
+
+func New(
+	logging *slog.Logger,
+	subtaskCreator ISubtaskCreator,
+	subtaskGetter ISubtaskGetter,
+	subtaskUpdater ISubtaskUpdater,
+) *SubtaskHandlers {
+	return &SubtaskHandlers{
+		logging:        logging.With("module", "subtaskshandler"),
+		subtaskCreator: subtaskCreator,
+		subtaskGetter:  subtaskGetter,
+		subtaskUpdater: subtaskUpdater,
+	}
+}
+
+// parseID Returns uint64 path parameter. Sends bad request response on failure
+func parseID(c *gin.Context, param string, detail string) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 0)
+	if err != nil {
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, detail)
+		return 0, false
+	}
+	return id, true
+}
+
+func subtaskItem(subtask coredto.ToDoSubtask) httpdto.Subtask {
+	checklist := make([]httpdto.ChecklistItem, 0, len(subtask.Checklist))
+	for _, entry := range subtask.Checklist {
+		checklist = append(checklist, httpdto.ChecklistItem{Title: entry.Title, IsDone: entry.IsDone})
+	}
+
+	return httpdto.Subtask{
+		ID:        *subtask.SubtaskID,
+		Title:     *subtask.Title,
+		IsDone:    *subtask.IsDone,
+		Checklist: checklist,
+	}
+}
+
+func coreChecklist(checklist []httpdto.ChecklistItem) []coredto.ToDoChecklistItem {
+	if checklist == nil {
+		return nil
+	}
+
+	result := make([]coredto.ToDoChecklistItem, 0, len(checklist))
+	for _, entry := range checklist {
+		result = append(result, coredto.ToDoChecklistItem{Title: entry.Title, IsDone: entry.IsDone})
+	}
+	return result
+}
+
+func sendSubtask(c *gin.Context, subtask *coredto.ToDoSubtask) {
+	c.IndentedJSON(http.StatusOK, httpdto.GetSubtaskResponse{
+		GeneralResponse: httpdto.GeneralResponse{
+			Status: httpdto.StatusOK,
+		},
+		Subtask: subtaskItem(*subtask),
+	})
+}
+
+// HandlerGetSubtasks
+// @Security 	ApiKeyAuth
+// @Summary 	Get task subtasks
+// @Description Subtasks are returned in creation order with their checklists
+// @Router 		/tasks/{id}/subtasks [GET]
+// @Param 		id	path int true "Task ID"
+// @Tags 		Subtasks
+// @Produce		json
+//
+// @Success 200 			{object}	GetSubtasksResponse
+// @Failure 400,401,404,500,503,504 {object}	Problem
+func (h *SubtaskHandlers) HandlerGetSubtasks(c *gin.Context) {
+
+	userID := c.GetUint64("userID")
+	taskID, ok := parseID(c, "id", "invalid task id")
+	if !ok {
+		return
+	}
+
+	subtasks, err := h.subtaskGetter.GetSubtasks(
+		c.Request.Context(),
+		coredto.User{
+			UserID: &userID,
+		},
+		taskID,
+	)
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	result := make([]httpdto.Subtask, 0, len(subtasks))
+	for _, subtask := range subtasks {
+		result = append(result, subtaskItem(subtask))
+	}
+
+	c.IndentedJSON(http.StatusOK, httpdto.GetSubtasksResponse{
+		GeneralResponse: httpdto.GeneralResponse{
+			Status: httpdto.StatusOK,
+		},
+		Subtasks: result,
+	})
+}
+
+// HandlerCreateSubtask
+// @Security 	ApiKeyAuth
+// @Summary 	Add subtask to task
+// @Description When subtasks auto-completion is enabled, adding an open subtask reopens a done task
+// @Router 		/tasks/{id}/subtasks [POST]
+// @Param 		id	path int true "Task ID"
+// @Param 		request body SubtaskCreate true "Subtask"
+// @Tags 		Subtasks
+// @Produce		json
+//
+// @Success 200 				{object}	GetSubtaskResponse
+// @Failure 400,401,404,422,500,503,504 {object}	Problem
+func (h *SubtaskHandlers) HandlerCreateSubtask(c *gin.Context) {
+
+	userID := c.GetUint64("userID")
+	taskID, ok := parseID(c, "id", "invalid task id")
+	if !ok {
+		return
+	}
+
+	var request httpdto.SubtaskCreate
+
+	err := handlers.BindStrictJSON(c, &request)
+
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, request, err)
+		return
+	}
+
+	subtask, err := h.subtaskCreator.CreateSubtask(
+		c.Request.Context(),
+		coredto.ToDoSubtask{
+			ParentID: &taskID,
+			Owner: &coredto.User{
+				UserID: &userID,
+			},
+			Title:     request.Title,
+			IsDone:    request.IsDone,
+			Checklist: coreChecklist(request.Checklist),
+		})
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	sendSubtask(c, subtask)
+}
+
+// HandlerUpdateSubtask
+// @Security 	ApiKeyAuth
+// @Summary 	Change subtask fields
+// @Description When subtasks auto-completion is enabled, completing the last open subtask completes the task
+// @Description and reopening a subtask reopens it
+// @Router 		/tasks/{id}/subtasks/{subtask_id} [PATCH]
+// @Param 		id	path int true "Task ID"
+// @Param 		subtask_id	path int true "Subtask ID"
+// @Param 		request body SubtaskChanges true "Subtask fields to change"
+// @Tags 		Subtasks
+// @Produce		json
+//
+// @Success 200 			{object}	GetSubtaskResponse
+// @Failure 400,401,404,500,503,504 {object}	Problem
+func (h *SubtaskHandlers) HandlerUpdateSubtask(c *gin.Context) {
+
+	userID := c.GetUint64("userID")
+	taskID, ok := parseID(c, "id", "invalid task id")
+	if !ok {
+		return
+	}
+	subtaskID, ok := parseID(c, "subtask_id", "invalid subtask id")
+	if !ok {
+		return
+	}
+
+	var changes httpdto.SubtaskChanges
+
+	err := handlers.BindStrictJSON(c, &changes)
+
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, changes, err)
+		return
+	}
+
+	subtask, err := h.subtaskUpdater.UpdateSubtask(
+		c.Request.Context(),
+		coredto.ToDoSubtask{
+			SubtaskID: &subtaskID,
+			ParentID:  &taskID,
+			Owner: &coredto.User{
+				UserID: &userID,
+			},
+			Title:     changes.Title,
+			IsDone:    changes.IsDone,
+			Checklist: coreChecklist(changes.Checklist),
+		})
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	sendSubtask(c, subtask)
+}
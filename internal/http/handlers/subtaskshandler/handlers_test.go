@@ -0,0 +1,153 @@
+package subtaskshandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	// Registers problems of the task errors shared with the tasks handlers
+	_ "todoapiservice/internal/http/handlers/todoitemshandler"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/taskdetails"
+	"todoapiservice/internal/services/todoprovider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserID = uint64(1)
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	pr := mocks.New(false)
+	pr.AddTask(testUserID, "Buy milk", false)
+	pr.AddTask(2, "Other user task", false)
+
+	provider := todoprovider.New(
+		slog.Default(),
+		pr,
+		taskdetails.NewMemoryStore(),
+		configapplication.TasksConfig{SubtasksAutoComplete: true},
+	)
+	h := New(slog.Default(), provider, provider, provider)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", testUserID)
+	})
+	router.GET("/tasks/:id/subtasks", h.HandlerGetSubtasks)
+	router.POST("/tasks/:id/subtasks", h.HandlerCreateSubtask)
+	router.PATCH("/tasks/:id/subtasks/:subtask_id", h.HandlerUpdateSubtask)
+
+	return router
+}
+
+func doRequest(router *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSubtaskHandlers(t *testing.T) {
+	router := newTestRouter(t)
+
+	w := doRequest(router, http.MethodPost, "/tasks/1/subtasks",
+		`{"title":" Go to shop ","checklist":[{"title":"Take bag"},{"title":"Take card","is_done":true}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var created httpdto.GetSubtaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, httpdto.Subtask{
+		ID:    1,
+		Title: "Go to shop",
+		Checklist: []httpdto.ChecklistItem{
+			{Title: "Take bag"},
+			{Title: "Take card", IsDone: true},
+		},
+	}, created.Subtask)
+
+	w = doRequest(router, http.MethodPatch, "/tasks/1/subtasks/1", `{"is_done":true,"checklist":[]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(router, http.MethodGet, "/tasks/1/subtasks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list httpdto.GetSubtasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, []httpdto.Subtask{
+		{ID: 1, Title: "Go to shop", IsDone: true, Checklist: []httpdto.ChecklistItem{}},
+	}, list.Subtasks)
+}
+
+func TestSubtaskHandlers_Errors(t *testing.T) {
+	testData := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		code    int
+		errCode httpdto.ErrorCode
+	}{
+		{
+			name:    "Invalid task id",
+			method:  http.MethodGet,
+			path:    "/tasks/abc/subtasks",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeBadRequest,
+		},
+		{
+			name:    "Task of another user",
+			method:  http.MethodPost,
+			path:    "/tasks/2/subtasks",
+			body:    `{"title":"Steal"}`,
+			code:    http.StatusNotFound,
+			errCode: httpdto.ErrorCodeTaskNotFound,
+		},
+		{
+			name:    "Unknown subtask",
+			method:  http.MethodPatch,
+			path:    "/tasks/1/subtasks/42",
+			body:    `{"is_done":true}`,
+			code:    http.StatusNotFound,
+			errCode: httpdto.ErrorCodeSubtaskNotFound,
+		},
+		{
+			name:    "Empty changes",
+			method:  http.MethodPatch,
+			path:    "/tasks/1/subtasks/1",
+			body:    `{}`,
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeValidationFailed,
+		},
+		{
+			name:    "Blank checklist item",
+			method:  http.MethodPost,
+			path:    "/tasks/1/subtasks",
+			body:    `{"title":"Go to shop","checklist":[{"title":" "}]}`,
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeValidationFailed,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router := newTestRouter(t)
+
+			w := doRequest(router, data.method, data.path, data.body)
+			require.Equal(t, data.code, w.Code, w.Body.String())
+
+			var problem httpdto.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			require.Equal(t, data.errCode, problem.Code)
+		})
+	}
+}
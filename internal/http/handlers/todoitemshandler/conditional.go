@@ -0,0 +1,59 @@
+package todoitemshandler
+
+import (
+	"slices"
+	"todoapiservice/internal/lib/etag"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/todoprovider"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag Sets ETag header matching the item version
+func setETag(c *gin.Context, item *coredto.ToDoItem) {
+	if item.Version != nil {
+		c.Header("ETag", etag.Format(*item.Version))
+	}
+}
+
+// notModified Reports whether If-None-Match header matches the item version
+func notModified(c *gin.Context, item *coredto.ToDoItem) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" || item.Version == nil {
+		return false
+	}
+	return etag.Parse(header).MatchWeak(*item.Version)
+}
+
+// expectedVersion Returns the item version required by If-Match header.
+// Returns nil when the header is absent or is "*": the item only has to exist
+func (h *ToDoHandlers) expectedVersion(c *gin.Context, owner coredto.User, taskID uint64) (*string, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil, nil
+	}
+
+	list := etag.Parse(header)
+	if list.Any {
+		return nil, nil
+	}
+
+	versions := list.StrongValues()
+	switch len(versions) {
+	case 0:
+		// Weak tags never match If-Match
+		return nil, todoprovider.ErrVersionMismatch
+	case 1:
+		return &versions[0], nil
+	}
+
+	// Several tags are listed: the current one is checked again when the change is applied
+	current, err := h.itemGetter.GetByID(c.Request.Context(), owner, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(versions, *current.Version) {
+		return nil, todoprovider.ErrVersionMismatch
+	}
+	return current.Version, nil
+}
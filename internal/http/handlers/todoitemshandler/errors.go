@@ -0,0 +1,34 @@
+package todoitemshandler
+
+import (
+	"net/http"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/idempotency"
+	"todoapiservice/internal/services/todobatch"
+	"todoapiservice/internal/services/todopatch"
+	"todoapiservice/internal/services/todoprovider"
+)
+
+// errorMappings are problems sent for errors of the services this package calls
+var errorMappings = []handlers.ErrorMapping{
+	{Err: todoprovider.ErrInvalidCursor, Status: http.StatusBadRequest, Code: httpdto.ErrorCodeInvalidCursor, Detail: "page cursor is invalid for this query"},
+	{Err: todoprovider.ErrInvalidSearchQuery, Status: http.StatusBadRequest, Code: httpdto.ErrorCodeInvalidSearchQuery, Detail: "search query must contain letters or digits"},
+	{Err: todobatch.ErrBatchTooLarge, Status: http.StatusBadRequest, Code: httpdto.ErrorCodeBatchTooLarge, ExposeError: true},
+	{Err: todobatch.ErrInvalidOperation, Status: http.StatusBadRequest, Code: httpdto.ErrorCodeInvalidOperation, ExposeError: true},
+	{Err: todobatch.ErrBatchAborted, Status: http.StatusFailedDependency, Code: httpdto.ErrorCodeBatchAborted, Detail: "not executed: another operation failed"},
+	{Err: todobatch.ErrOperationRolledBack, Status: http.StatusFailedDependency, Code: httpdto.ErrorCodeRolledBack, Detail: "reverted: another operation failed"},
+	{Err: todoprovider.ErrVersionMismatch, Status: http.StatusPreconditionFailed, Code: httpdto.ErrorCodeVersionMismatch, Detail: "task was changed, fetch it again"},
+	{Err: idempotency.ErrKeyReused, Status: http.StatusUnprocessableEntity, Code: httpdto.ErrorCodeIdempotencyKeyReused, Detail: "idempotency key was used with another request"},
+	{Err: idempotency.ErrInProgress, Status: http.StatusConflict, Code: httpdto.ErrorCodeIdempotencyKeyInUse, Detail: "request with this idempotency key is in progress"},
+	{Err: todopatch.ErrInvalidPatch, Status: http.StatusBadRequest, Code: httpdto.ErrorCodeInvalidPatch, ExposeError: true},
+	{Err: todopatch.ErrUnsupportedPatch, Status: http.StatusUnprocessableEntity, Code: httpdto.ErrorCodeUnsupportedPatch, ExposeError: true},
+	{Err: todopatch.ErrPatchTestFailed, Status: http.StatusConflict, Code: httpdto.ErrorCodePatchTestFailed, ExposeError: true},
+	{Err: todoprovider.ErrBackendUnavailable, Status: http.StatusServiceUnavailable, Code: httpdto.ErrorCodeBackendUnavailable, Detail: "task service is unavailable"},
+	{Err: todoprovider.ErrBackendTimeout, Status: http.StatusGatewayTimeout, Code: httpdto.ErrorCodeBackendTimeout, Detail: "task service did not respond in time"},
+	{Err: todoprovider.ErrToDoNotFound, Status: http.StatusNotFound, Code: httpdto.ErrorCodeTaskNotFound, Detail: "task not found"},
+}
+
+func init() {
+	handlers.RegisterErrors(errorMappings...)
+}
@@ -3,19 +3,22 @@ package todoitemshandler
 
 import (
 	"context"
-	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 	"todoapiservice/internal/http/handlers"
 	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/lib/textsearch"
 	"todoapiservice/internal/services/coredto"
-	"todoapiservice/internal/services/todoprovider"
 )
 
 type IToDoCreator interface {
-	Create(ctx context.Context, owner coredto.User, title string) (*coredto.ToDoItem, error)
+	Create(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error)
 }
 
 type IToDoDeleter interface {
@@ -24,146 +27,269 @@ type IToDoDeleter interface {
 
 type IToDoGetter interface {
 	GetByID(ctx context.Context, owner coredto.User, itemID uint64) (*coredto.ToDoItem, error)
-	GetList(ctx context.Context, owner coredto.User) ([]coredto.ToDoItem, error)
+	GetList(ctx context.Context, owner coredto.User, query coredto.ToDoListQuery) (*coredto.ToDoListPage, error)
+}
+
+type IToDoSearcher interface {
+	Search(ctx context.Context, owner coredto.User, query coredto.ToDoSearchQuery) ([]coredto.ToDoSearchResult, error)
+}
+
+type IToDoBatchExecutor interface {
+	Execute(ctx context.Context, owner coredto.User, ops []coredto.ToDoOperation, atomic bool) (*coredto.ToDoBatchResult, error)
 }
 
 type IToDoUpdater interface {
 	Update(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error)
 }
 
+type IToDoPatcher interface {
+	Patch(ctx context.Context, owner coredto.User, itemID uint64, version *string, patch coredto.ToDoPatch) (*coredto.ToDoItem, error)
+}
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+	acceptPatch           = binding.MIMEJSON + ", " + mergePatchContentType + ", " + jsonPatchContentType
+)
+
 type ToDoHandlers struct {
-	logging     *slog.Logger
-	itemCreator IToDoCreator
-	itemGetter  IToDoGetter
-	itemUpdater IToDoUpdater
-	itemDeleter IToDoDeleter
+	logging      *slog.Logger
+	itemCreator  IToDoCreator
+	itemGetter   IToDoGetter
+	itemSearcher IToDoSearcher
+	itemUpdater  IToDoUpdater
+	itemPatcher  IToDoPatcher
+	itemDeleter  IToDoDeleter
+	batch        IToDoBatchExecutor
+	idempotency  IIdempotencyStore
 }
 
 func New(
 	logging *slog.Logger,
 	itemCreator IToDoCreator,
 	itemGetter IToDoGetter,
+	itemSearcher IToDoSearcher,
 	itemUpdater IToDoUpdater,
+	itemPatcher IToDoPatcher,
 	itemDeleter IToDoDeleter,
+	batch IToDoBatchExecutor,
+	idempotency IIdempotencyStore,
 ) *ToDoHandlers {
 	return &ToDoHandlers{
-		logging:     logging.With("module", "todoitemshandler"),
-		itemCreator: itemCreator,
-		itemGetter:  itemGetter,
-		itemUpdater: itemUpdater,
-		itemDeleter: itemDeleter,
+		logging:      logging.With("module", "todoitemshandler"),
+		itemCreator:  itemCreator,
+		itemGetter:   itemGetter,
+		itemSearcher: itemSearcher,
+		itemUpdater:  itemUpdater,
+		itemPatcher:  itemPatcher,
+		itemDeleter:  itemDeleter,
+		batch:        batch,
+		idempotency:  idempotency,
 	}
 }
 
 // HandlerCreateTask
 // @Security 	ApiKeyAuth
 // @Summary 	Create new task
+// @Description Retries carrying the same Idempotency-Key and body get the stored response instead of creating another task.
+// @Description Reusing the key with another body returns 422, retrying while the first request is running returns 409
 // @Router 		/tasks [POST]
-// @Param 		request body TaskItemChanges true "New task fields"
+// @Param 		Idempotency-Key header string false "Client generated unique request key, up to 255 characters"
+// @Param 		request body TaskItemCreate true "New task fields"
 // @Tags 		TodoList
 // @Produce		json
 //
-// @Success 200 		{object} 	GetTaskByIDResponse
-// @Failure 400,401,500 {object}	GeneralResponse
+// @Success 200 				{object} 	GetTaskByIDResponse
+// @Header  200 				{string}	ETag "Task version"
+// @Header  200 				{string}	Idempotent-Replayed "true if the response is a stored one"
+// @Failure 400,401,409,422,500,503,504 {object}	Problem
 func (h *ToDoHandlers) HandlerCreateTask(c *gin.Context) {
 
-	var changes httpdto.TaskItemChanges
-	err := c.BindJSON(&changes)
+	var request httpdto.TaskItemCreate
+	err := handlers.BindStrictJSON(c, &request)
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusBadRequest)
+		handlers.SendValidationErrorResponse(c, request, err)
 		return
 	}
 
 	userID := c.GetUint64("userID")
+	owner := coredto.User{
+		UserID: &userID,
+	}
 
-	newItem, err := h.itemCreator.Create(
+	h.runIdempotent(c, userID, request, func(c *gin.Context) {
+		newItem, err := h.itemCreator.Create(
+			c.Request.Context(),
+			withDetails(coredto.ToDoItem{
+				Owner:  &owner,
+				Title:  request.Title,
+				IsDone: request.IsDone,
+			}, request.TaskItemDetails),
+		)
+
+		if err != nil {
+			handlers.SendServiceError(c, err)
+			return
+		}
+
+		sendTask(c, newItem)
+	})
+}
+
+func listQuery(params httpdto.TaskListQuery) coredto.ToDoListQuery {
+	sortBy, descending := strings.CutPrefix(params.Sort, "-")
+
+	return coredto.ToDoListQuery{
+		Limit:         params.Limit,
+		Cursor:        params.Cursor,
+		SortBy:        coredto.ToDoSortField(sortBy),
+		Descending:    descending,
+		IsDone:        params.IsDone,
+		TitleContains: params.Title,
+	}
+}
+
+// HandlerGetTaskList
+// @Security 	ApiKeyAuth
+// @Summary 	Get tasks list
+// @Description Returns a page of tasks. Pass `next_cursor` of the previous page as `cursor`
+// @Description with the same filter and sort parameters to get the next page
+// @Router 		/tasks [GET]
+// @Param 		limit	query int		false	"Page size"	minimum(1) maximum(500) default(50)
+// @Param 		cursor	query string	false	"Opaque cursor from next_cursor"
+// @Param 		sort	query string	false	"Sort field, '-' prefix for descending order" Enums(id, -id, title, -title, is_done, -is_done) default(id)
+// @Param 		is_done	query bool		false	"Filter by completion"
+// @Param 		title	query string	false	"Filter by case-insensitive title substring"
+// @Tags 		TodoList
+// @Produce		json
+//
+// @Success 200 		{object} 	GetTaskListResponse
+// @Failure 400,401,500,503,504	{object}	Problem
+func (h *ToDoHandlers) HandlerGetTaskList(c *gin.Context) {
+	userID := c.GetUint64("userID")
+
+	var params httpdto.TaskListQuery
+	err := c.ShouldBindQuery(&params)
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, params, err)
+		return
+	}
+
+	page, err := h.itemGetter.GetList(
 		c.Request.Context(),
 		coredto.User{
 			UserID: &userID,
 		},
-		*changes.Title,
+		listQuery(params),
 	)
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendServiceError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, httpdto.GetTaskByIDResponse{
+	tasks := make([]httpdto.TaskItem, 0, len(page.Items))
+	for _, item := range page.Items {
+		tasks = append(tasks, taskItem(&item))
+	}
+
+	c.IndentedJSON(http.StatusOK, httpdto.GetTaskListResponse{
 		GeneralResponse: httpdto.GeneralResponse{
 			Status: httpdto.StatusOK,
 		},
-		Task: httpdto.TaskItem{
-			ID:    *newItem.ItemID,
-			Title: *newItem.Title,
-		},
+		Tasks:      tasks,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
 	})
 
 }
 
-// HandlerGetTaskList
+// snippetLength is a search result snippet limit in characters
+const snippetLength = 120
+
+// HandlerSearchTasks
 // @Security 	ApiKeyAuth
-// @Summary 	Get tasks list
-// @Router 		/tasks [GET]
+// @Summary 	Search tasks by title
+// @Description Case-insensitive and diacritic-insensitive search. Every query word must match
+// @Description a title word, whole words rank above prefixes and substrings.
+// @Description Snippet is HTML-escaped title with matched words wrapped in `<mark>` tags
+// @Router 		/tasks/search [GET]
+// @Param 		q		query string	true	"Search words"	maxlength(256)
+// @Param 		limit	query int		false	"Results limit"	minimum(1) maximum(100) default(20)
 // @Tags 		TodoList
 // @Produce		json
 //
-// @Success 200 	{object} 	GetTaskListResponse
-// @Failure 401,500	{object}	GeneralResponse
-func (h *ToDoHandlers) HandlerGetTaskList(c *gin.Context) {
+// @Success 200 		{object} 	SearchTasksResponse
+// @Failure 400,401,500,503,504	{object}	Problem
+func (h *ToDoHandlers) HandlerSearchTasks(c *gin.Context) {
 	userID := c.GetUint64("userID")
 
-	items, err := h.itemGetter.GetList(
+	var params httpdto.TaskSearchQuery
+	err := c.ShouldBindQuery(&params)
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, params, err)
+		return
+	}
+
+	found, err := h.itemSearcher.Search(
 		c.Request.Context(),
 		coredto.User{
 			UserID: &userID,
 		},
+		coredto.ToDoSearchQuery{
+			Text:  params.Q,
+			Limit: params.Limit,
+		},
 	)
 
 	if err != nil {
-		c.IndentedJSON(
-			http.StatusInternalServerError,
-			httpdto.GeneralResponse{
-				Status: httpdto.StatusError,
-			})
+		handlers.SendServiceError(c, err)
 		return
 	}
 
-	tasks := make([]httpdto.TaskItem, 0, len(items))
-	for _, item := range items {
-		tasks = append(tasks, httpdto.TaskItem{
-			ID:     *item.ItemID,
-			Title:  *item.Title,
-			IsDone: *item.IsDone,
+	results := make([]httpdto.TaskSearchResult, 0, len(found))
+	for _, result := range found {
+		spans := make([]textsearch.Span, 0, len(result.Highlights))
+		for _, span := range result.Highlights {
+			spans = append(spans, textsearch.Span{Start: span.Start, End: span.End})
+		}
+
+		results = append(results, httpdto.TaskSearchResult{
+			Task:    taskItem(&result.Item),
+			Score:   math.Round(result.Score*1000) / 1000,
+			Snippet: textsearch.Highlight(*result.Item.Title, spans, snippetLength),
 		})
 	}
 
-	c.IndentedJSON(http.StatusOK, httpdto.GetTaskListResponse{
+	c.IndentedJSON(http.StatusOK, httpdto.SearchTasksResponse{
 		GeneralResponse: httpdto.GeneralResponse{
 			Status: httpdto.StatusOK,
 		},
-		Tasks: tasks,
+		Results: results,
 	})
-
 }
 
 // HandlerGetTaskByID
 // @Security 	ApiKeyAuth
 // @Summary 	Get single task by ID
 // @Router 		/tasks/{id} [GET]
+// @Description Response carries task version in ETag header. With matching If-None-Match header 304 is returned without body
 // @Param 		id	path int true "Task ID"
+// @Param 		If-None-Match header string false "Task ETag known to the client"
 // @Tags 		TodoList
 // @Produce		json
 //
 // @Success 200 			{object}	GetTaskByIDResponse
-// @Failure 400,401,404,500 {object}	GeneralResponse
+// @Success 304
+// @Header  200,304 		{string}	ETag "Task version"
+// @Failure 400,401,404,500,503,504 {object}	Problem
 func (h *ToDoHandlers) HandlerGetTaskByID(c *gin.Context) {
 
 	userID := c.GetUint64("userID")
 	taskID, err := strconv.ParseUint(c.Param("id"), 10, 0)
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, "invalid task id")
 		return
 	}
 
@@ -176,7 +302,13 @@ func (h *ToDoHandlers) HandlerGetTaskByID(c *gin.Context) {
 	)
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	setETag(c, item)
+	if notModified(c, item) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -184,11 +316,7 @@ func (h *ToDoHandlers) HandlerGetTaskByID(c *gin.Context) {
 		GeneralResponse: httpdto.GeneralResponse{
 			Status: httpdto.StatusOK,
 		},
-		Task: httpdto.TaskItem{
-			ID:     *item.ItemID,
-			Title:  *item.Title,
-			IsDone: *item.IsDone,
-		},
+		Task: taskItem(item),
 	},
 	)
 }
@@ -196,94 +324,272 @@ func (h *ToDoHandlers) HandlerGetTaskByID(c *gin.Context) {
 // HandlerUpdateTaskByID
 // @Security 	ApiKeyAuth
 // @Summary 	Change task fields by ID
+// @Description Body is TaskItemChanges for application/json, RFC 7396 merge patch of TaskItem for application/merge-patch+json
+// @Description or RFC 6902 JSON Patch for application/json-patch+json. JSON Patch supports add, replace and test on task fields
+// @Description and remove on optional ones. Due date can be cleared only with merge patch null or JSON Patch remove.
+// @Description With If-Match header the task is changed only if its current ETag is listed, otherwise 412 is returned
 // @Router 		/tasks/{id} [PATCH]
 // @Param 		id	path int true "Task ID"
+// @Param 		If-Match header string false "Expected task ETag"
 // @Param 		request body TaskItemChanges true "Fields changes"
+// @Accept		json
+// @Accept		application/merge-patch+json
+// @Accept		application/json-patch+json
 // @Tags 		TodoList
 // @Produce		json
 //
-// @Success 200 			{object}	GeneralResponse
-// @Failure 400,401,404,500 {object}	GeneralResponse
+// @Success 200 						{object}	GetTaskByIDResponse
+// @Header  200 						{string}	ETag "New task version"
+// @Failure 400,401,404,409,412,415,422,500,503,504 {object}	Problem
 func (h *ToDoHandlers) HandlerUpdateTaskByID(c *gin.Context) {
 
 	userID := c.GetUint64("userID")
 	taskID, err := strconv.ParseUint(c.Param("id"), 10, 0)
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusBadRequest)
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, "invalid task id")
 		return
 	}
 
+	owner := coredto.User{
+		UserID: &userID,
+	}
+
+	var patchFormat coredto.ToDoPatchFormat
 	var changes httpdto.TaskItemChanges
 
-	err = c.BindJSON(&changes)
+	switch c.ContentType() {
+	case mergePatchContentType:
+		patchFormat = coredto.PatchMerge
+	case jsonPatchContentType:
+		patchFormat = coredto.PatchJSON
+	case "", binding.MIMEJSON:
+		err = handlers.BindStrictJSON(c, &changes)
+		if err != nil {
+			handlers.SendValidationErrorResponse(c, changes, err)
+			return
+		}
+	default:
+		c.Header("Accept-Patch", acceptPatch)
+		handlers.SendProblem(c, http.StatusUnsupportedMediaType, httpdto.ErrorCodeUnsupportedMediaType,
+			"supported content types: "+acceptPatch)
+		return
+	}
 
+	version, err := h.expectedVersion(c, owner, taskID)
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusBadRequest)
+		handlers.SendServiceError(c, err)
 		return
 	}
 
-	_, err = h.itemUpdater.Update(
-		c.Request.Context(),
-		coredto.ToDoItem{
-			Owner: &coredto.User{
-				UserID: &userID,
-			},
-			ItemID: &taskID,
-			Title:  changes.Title,
-			IsDone: changes.IsDone,
-		})
+	var item *coredto.ToDoItem
 
-	if err != nil {
-		if errors.Is(err, todoprovider.ErrToDoNotFound) {
-			handlers.SendErrorResponse(c, http.StatusNotFound)
+	if patchFormat != "" {
+		var document []byte
+		document, err = c.GetRawData()
+		if err != nil {
+			handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, "malformed request")
 			return
 		}
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+
+		item, err = h.itemPatcher.Patch(c.Request.Context(), owner, taskID, version, coredto.ToDoPatch{
+			Format:   patchFormat,
+			Document: document,
+		})
+	} else {
+		item, err = h.itemUpdater.Update(
+			c.Request.Context(),
+			withDetails(coredto.ToDoItem{
+				Owner:   &owner,
+				ItemID:  &taskID,
+				Title:   changes.Title,
+				IsDone:  changes.IsDone,
+				Version: version,
+			}, changes.TaskItemDetails))
+	}
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, httpdto.GeneralResponse{
-		Status: httpdto.StatusOK,
-	})
+	sendTask(c, item)
+}
+
+// HandlerReplaceTaskByID
+// @Security 	ApiKeyAuth
+// @Summary 	Replace task by ID
+// @Description Title and is_done are required, omitted optional fields are cleared. With If-Match header the task is replaced only if its current ETag is listed, otherwise 412 is returned
+// @Router 		/tasks/{id} [PUT]
+// @Param 		id	path int true "Task ID"
+// @Param 		If-Match header string false "Expected task ETag"
+// @Param 		request body TaskItemReplace true "New task fields"
+// @Tags 		TodoList
+// @Produce		json
+//
+// @Success 200 				{object}	GetTaskByIDResponse
+// @Header  200 				{string}	ETag "New task version"
+// @Failure 400,401,404,412,500,503,504 {object}	Problem
+func (h *ToDoHandlers) HandlerReplaceTaskByID(c *gin.Context) {
+
+	userID := c.GetUint64("userID")
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 0)
+
+	if err != nil {
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, "invalid task id")
+		return
+	}
+
+	var replacement httpdto.TaskItemReplace
+
+	err = handlers.BindStrictJSON(c, &replacement)
+
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, replacement, err)
+		return
+	}
+
+	owner := coredto.User{
+		UserID: &userID,
+	}
+
+	version, err := h.expectedVersion(c, owner, taskID)
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	item, err := h.itemUpdater.Update(
+		c.Request.Context(),
+		replaceDetails(withDetails(coredto.ToDoItem{
+			Owner:   &owner,
+			ItemID:  &taskID,
+			Title:   replacement.Title,
+			IsDone:  replacement.IsDone,
+			Version: version,
+		}, replacement.TaskItemDetails)))
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	sendTask(c, item)
+}
+
+// taskItem Converts item to the response representation
+func taskItem(item *coredto.ToDoItem) httpdto.TaskItem {
+	task := httpdto.TaskItem{
+		ID:        *item.ItemID,
+		Title:     *item.Title,
+		IsDone:    *item.IsDone,
+		DueDate:   item.DueDate,
+		Tags:      item.Tags,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+
+	if item.Description != nil {
+		task.Description = *item.Description
+	}
+	if item.Priority != nil {
+		task.Priority = string(*item.Priority)
+	}
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+	if item.Progress != nil {
+		task.Subtasks = &httpdto.TaskProgress{Done: item.Progress.Done, Total: item.Progress.Total}
+	}
+	return task
+}
+
+// withDetails Copies optional request fields to item changes
+func withDetails(item coredto.ToDoItem, details httpdto.TaskItemDetails) coredto.ToDoItem {
+	item.Description = details.Description
+	item.DueDate = details.DueDate
+	item.Tags = details.Tags
+
+	if details.Priority != nil {
+		priority := coredto.ToDoPriority(*details.Priority)
+		item.Priority = &priority
+	}
+	return item
+}
+
+// replaceDetails Clears optional fields omitted in full replacement
+func replaceDetails(item coredto.ToDoItem) coredto.ToDoItem {
+	if item.Description == nil {
+		item.Description = new(string)
+	}
+	if item.DueDate == nil {
+		item.DueDate = &time.Time{}
+	}
+	if item.Priority == nil {
+		priority := coredto.PriorityNormal
+		item.Priority = &priority
+	}
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+	return item
+}
+
+// sendTask Sends the task with its version in ETag header
+func sendTask(c *gin.Context, item *coredto.ToDoItem) {
+	setETag(c, item)
+	c.IndentedJSON(http.StatusOK, httpdto.GetTaskByIDResponse{
+		GeneralResponse: httpdto.GeneralResponse{
+			Status: httpdto.StatusOK,
+		},
+		Task: taskItem(item),
+	},
+	)
 }
 
 // HandlerDeleteTaskByID
 // @Security 	ApiKeyAuth
 // @Summary 	Delete task by ID
+// @Description With If-Match header the task is deleted only if its current ETag is listed, otherwise 412 is returned
 // @Router 		/tasks/{id} [DELETE]
 // @Param 		id	path int true "Task ID"
+// @Param 		If-Match header string false "Expected task ETag"
 // @Tags 		TodoList
 // @Produce		json
 //
-// @Success 200 			{object}	GeneralResponse
-// @Failure 400,401,404,500 {object}	GeneralResponse
+// @Success 200 				{object}	GeneralResponse
+// @Failure 400,401,404,412,500,503,504 {object}	Problem
 func (h *ToDoHandlers) HandlerDeleteTaskByID(c *gin.Context) {
 
 	userID := c.GetUint64("userID")
 	taskID, err := strconv.ParseUint(c.Param("id"), 10, 0)
 
 	if err != nil {
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, "invalid task id")
+		return
+	}
+
+	owner := coredto.User{
+		UserID: &userID,
+	}
+
+	version, err := h.expectedVersion(c, owner, taskID)
+	if err != nil {
+		handlers.SendServiceError(c, err)
 		return
 	}
 
 	err = h.itemDeleter.Delete(
 		c.Request.Context(),
 		coredto.ToDoItem{
-			Owner: &coredto.User{
-				UserID: &userID,
-			},
-			ItemID: &taskID,
+			Owner:   &owner,
+			ItemID:  &taskID,
+			Version: version,
 		},
 	)
 
 	if err != nil {
-		if errors.Is(err, todoprovider.ErrToDoNotFound) {
-			handlers.SendErrorResponse(c, http.StatusNotFound)
-			return
-		}
-		handlers.SendErrorResponse(c, http.StatusInternalServerError)
+		handlers.SendServiceError(c, err)
 		return
 	}
 
@@ -293,3 +599,103 @@ func (h *ToDoHandlers) HandlerDeleteTaskByID(c *gin.Context) {
 		},
 	)
 }
+
+// batchAction is the only supported `/tasks:<action>` custom method
+const batchAction = ":batch"
+
+func batchOperation(op httpdto.BatchOperation) coredto.ToDoOperation {
+	return coredto.ToDoOperation{
+		Kind: coredto.ToDoOperationKind(op.Op),
+		Item: withDetails(coredto.ToDoItem{
+			ItemID: op.ID,
+			Title:  op.Title,
+			IsDone: op.IsDone,
+		}, op.TaskItemDetails),
+	}
+}
+
+// HandlerBatchTasks
+// @Security 	ApiKeyAuth
+// @Summary 	Create, update and delete tasks in one request
+// @Description Operations run in parallel, so one batch must not touch a task twice.
+// @Description Every operation gets its own status code and error.
+// @Description With `atomic` set, a failure stops the batch and reverts applied operations:
+// @Description deleted tasks are created again under new IDs
+// @Router 		/tasks:batch [POST]
+// @Param 		request body BatchRequest true "Operations"
+// @Tags 		TodoList
+// @Accept		json
+// @Produce		json
+//
+// @Success 200 		{object} 	BatchResponse "All operations succeeded"
+// @Success 207 		{object} 	BatchResponse "Some operations failed, see results"
+// @Failure 400,401,404,500,503,504	{object}	Problem
+func (h *ToDoHandlers) HandlerBatchTasks(c *gin.Context) {
+	if c.Param("action") != batchAction {
+		handlers.SendProblem(c, http.StatusNotFound, httpdto.ErrorCodeNotFound, "route not found")
+		return
+	}
+
+	userID := c.GetUint64("userID")
+
+	var request httpdto.BatchRequest
+	err := handlers.BindStrictJSON(c, &request)
+	if err != nil {
+		handlers.SendValidationErrorResponse(c, request, err)
+		return
+	}
+
+	ops := make([]coredto.ToDoOperation, 0, len(request.Operations))
+	for _, op := range request.Operations {
+		ops = append(ops, batchOperation(op))
+	}
+
+	batchResult, err := h.batch.Execute(
+		c.Request.Context(),
+		coredto.User{
+			UserID: &userID,
+		},
+		ops,
+		request.Atomic,
+	)
+
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	code := http.StatusOK
+	results := make([]httpdto.BatchOperationResult, 0, len(batchResult.Results))
+	for i, result := range batchResult.Results {
+		opResult := httpdto.BatchOperationResult{
+			Index:      i,
+			Status:     http.StatusOK,
+			RolledBack: result.RolledBack,
+		}
+
+		if result.Err != nil {
+			problem := handlers.ServiceProblem(c, result.Err)
+			opResult.Status = problem.Status
+			opResult.Error = &problem
+			code = http.StatusMultiStatus
+		} else if result.Item != nil {
+			task := taskItem(result.Item)
+			opResult.Task = &task
+			if ops[i].Kind == coredto.OperationCreate {
+				opResult.Status = http.StatusCreated
+			}
+		} else {
+			opResult.Status = http.StatusNoContent
+		}
+
+		results = append(results, opResult)
+	}
+
+	c.IndentedJSON(code, httpdto.BatchResponse{
+		GeneralResponse: httpdto.GeneralResponse{
+			Status: httpdto.StatusOK,
+		},
+		Results:    results,
+		RolledBack: batchResult.RolledBack,
+	})
+}
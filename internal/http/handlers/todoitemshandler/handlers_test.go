@@ -0,0 +1,756 @@
+package todoitemshandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/idempotency"
+	"todoapiservice/internal/services/taskdetails"
+	"todoapiservice/internal/services/todobatch"
+	"todoapiservice/internal/services/todopatch"
+	"todoapiservice/internal/services/todoprovider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserID = uint64(1)
+
+func newTestRouter(t *testing.T) (*gin.Engine, *mocks.ToDoGrpcMock) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	pr := mocks.New(false)
+	provider := todoprovider.New(slog.Default(), pr, taskdetails.NewMemoryStore(), configapplication.TasksConfig{})
+	batch := todobatch.New(
+		slog.Default(),
+		provider, provider, provider, provider, provider,
+		configapplication.TasksConfig{BatchMaxOperations: 3, BatchConcurrency: 2},
+	)
+	store := idempotency.NewMemoryStore(configapplication.IdempotencyConfig{TTL: time.Minute, MaxKeys: 100})
+	patcher := todopatch.New(provider, provider)
+	h := New(slog.Default(), provider, provider, provider, provider, patcher, provider, batch, store)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", testUserID)
+	})
+	router.POST("/tasks", h.HandlerCreateTask)
+	router.GET("/tasks", h.HandlerGetTaskList)
+	router.GET("/tasks/search", h.HandlerSearchTasks)
+	router.GET("/tasks/:id", h.HandlerGetTaskByID)
+	router.PATCH("/tasks/:id", h.HandlerUpdateTaskByID)
+	router.PUT("/tasks/:id", h.HandlerReplaceTaskByID)
+	router.DELETE("/tasks/:id", h.HandlerDeleteTaskByID)
+	router.POST("/tasks:action", h.HandlerBatchTasks)
+
+	return router, pr
+}
+
+func doRequest(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) httpdto.Problem {
+	t.Helper()
+
+	require.Equal(t, httpdto.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem httpdto.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return problem
+}
+
+// withoutTimestamps Checks that task timestamps are set and strips them for comparison
+func withoutTimestamps(t *testing.T, task httpdto.TaskItem) httpdto.TaskItem {
+	t.Helper()
+
+	require.NotNil(t, task.CreatedAt)
+	require.NotNil(t, task.UpdatedAt)
+	task.CreatedAt, task.UpdatedAt = nil, nil
+	return task
+}
+
+func TestToDoHandlers_HandlerGetTaskList(t *testing.T) {
+	router, pr := newTestRouter(t)
+	pr.AddTask(testUserID, "Buy milk", false)
+	pr.AddTask(testUserID, "Write report", true)
+	pr.AddTask(testUserID, "Buy bread", false)
+
+	testData := []struct {
+		name       string
+		query      string
+		ids        []uint64
+		total      int
+		hasCursor  bool
+		code       int
+		errCode    httpdto.ErrorCode
+		errorField string
+	}{
+		{
+			name:  "No parameters",
+			query: "",
+			ids:   []uint64{1, 2, 3},
+			total: 3,
+			code:  http.StatusOK,
+		},
+		{
+			name:      "Limit",
+			query:     "?limit=2",
+			ids:       []uint64{1, 2},
+			total:     3,
+			hasCursor: true,
+			code:      http.StatusOK,
+		},
+		{
+			name:  "Sort and filter",
+			query: "?sort=-title&is_done=false&title=buy",
+			ids:   []uint64{1, 3},
+			total: 2,
+			code:  http.StatusOK,
+		},
+		{
+			name:       "Limit too big",
+			query:      "?limit=501",
+			code:       http.StatusBadRequest,
+			errCode:    httpdto.ErrorCodeValidationFailed,
+			errorField: "limit",
+		},
+		{
+			name:       "Unknown sort field",
+			query:      "?sort=owner",
+			code:       http.StatusBadRequest,
+			errCode:    httpdto.ErrorCodeValidationFailed,
+			errorField: "sort",
+		},
+		{
+			name:    "Malformed is_done",
+			query:   "?is_done=maybe",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeBadRequest,
+		},
+		{
+			name:    "Malformed cursor",
+			query:   "?cursor=abc",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeInvalidCursor,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			w := doRequest(router, httptest.NewRequest(http.MethodGet, "/tasks"+data.query, nil))
+
+			require.Equal(t, data.code, w.Code)
+
+			if data.code != http.StatusOK {
+				problem := decodeProblem(t, w)
+				require.Equal(t, data.errCode, problem.Code)
+				if data.errorField != "" {
+					require.Len(t, problem.Errors, 1)
+					require.Equal(t, data.errorField, problem.Errors[0].Field)
+				}
+				return
+			}
+
+			var resp httpdto.GetTaskListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			ids := make([]uint64, 0, len(resp.Tasks))
+			for _, task := range resp.Tasks {
+				ids = append(ids, task.ID)
+			}
+			require.Equal(t, data.ids, ids)
+			require.Equal(t, data.total, resp.Total)
+			require.Equal(t, data.hasCursor, resp.NextCursor != "")
+		})
+	}
+}
+
+func TestToDoHandlers_HandlerSearchTasks(t *testing.T) {
+	router, pr := newTestRouter(t)
+	pr.AddTask(testUserID, "Buy <b>milk</b>", false)
+	pr.AddTask(testUserID, "Make crème brûlée", false)
+
+	testData := []struct {
+		name     string
+		query    string
+		code     int
+		errCode  httpdto.ErrorCode
+		snippets []string
+	}{
+		{
+			name:     "Highlighted snippet",
+			query:    "?q=milk",
+			code:     http.StatusOK,
+			snippets: []string{"Buy &lt;b&gt;<mark>milk</mark>&lt;/b&gt;"},
+		},
+		{
+			name:     "Diacritics ignored",
+			query:    "?q=CREME",
+			code:     http.StatusOK,
+			snippets: []string{"Make <mark>crème</mark> brûlée"},
+		},
+		{
+			name:     "Nothing found",
+			query:    "?q=tea",
+			code:     http.StatusOK,
+			snippets: []string{},
+		},
+		{
+			name:    "Missing query",
+			query:   "",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeValidationFailed,
+		},
+		{
+			name:    "No words",
+			query:   "?q=%21%21",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeInvalidSearchQuery,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			w := doRequest(router, httptest.NewRequest(http.MethodGet, "/tasks/search"+data.query, nil))
+
+			require.Equal(t, data.code, w.Code)
+
+			if data.code != http.StatusOK {
+				require.Equal(t, data.errCode, decodeProblem(t, w).Code)
+				return
+			}
+
+			var resp httpdto.SearchTasksResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			snippets := make([]string, 0, len(resp.Results))
+			for _, result := range resp.Results {
+				snippets = append(snippets, result.Snippet)
+			}
+			require.Equal(t, data.snippets, snippets)
+		})
+	}
+}
+
+func TestToDoHandlers_HandlerBatchTasks(t *testing.T) {
+	testData := []struct {
+		name     string
+		path     string
+		body     string
+		code     int
+		errCode  httpdto.ErrorCode
+		statuses []int
+	}{
+		{
+			name: "All succeeded",
+			path: "/tasks:batch",
+			body: `{"operations":[{"op":"create","title":"New"},{"op":"update","id":1,"is_done":true},{"op":"delete","id":2}]}`,
+			code: http.StatusOK,
+			statuses: []int{
+				http.StatusCreated, http.StatusOK, http.StatusNoContent,
+			},
+		},
+		{
+			name: "Partial failure",
+			path: "/tasks:batch",
+			body: `{"operations":[{"op":"create","title":"New"},{"op":"update","id":42,"is_done":true},{"op":"delete"}]}`,
+			code: http.StatusMultiStatus,
+			statuses: []int{
+				http.StatusCreated, http.StatusNotFound, http.StatusBadRequest,
+			},
+		},
+		{
+			name: "Atomic failure",
+			path: "/tasks:batch",
+			body: `{"atomic":true,"operations":[{"op":"update","id":1,"title":"Changed"},{"op":"delete","id":42}]}`,
+			code: http.StatusMultiStatus,
+			statuses: []int{
+				http.StatusFailedDependency, http.StatusNotFound,
+			},
+		},
+		{
+			name:    "Unknown operation",
+			path:    "/tasks:batch",
+			body:    `{"operations":[{"op":"rename","id":1}]}`,
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeValidationFailed,
+		},
+		{
+			name:    "Empty batch",
+			path:    "/tasks:batch",
+			body:    `{"operations":[]}`,
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeValidationFailed,
+		},
+		{
+			name:    "Too many operations",
+			path:    "/tasks:batch",
+			body:    `{"operations":[{"op":"delete","id":1},{"op":"delete","id":2},{"op":"delete","id":3},{"op":"delete","id":4}]}`,
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeBatchTooLarge,
+		},
+		{
+			name:    "Unknown action",
+			path:    "/tasks:purge",
+			body:    `{"operations":[{"op":"delete","id":1}]}`,
+			code:    http.StatusNotFound,
+			errCode: httpdto.ErrorCodeNotFound,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router, pr := newTestRouter(t)
+			pr.AddTask(testUserID, "First", false)
+			pr.AddTask(testUserID, "Second", false)
+
+			req := httptest.NewRequest(http.MethodPost, data.path, strings.NewReader(data.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := doRequest(router, req)
+
+			require.Equal(t, data.code, w.Code)
+
+			if data.errCode != "" {
+				require.Equal(t, data.errCode, decodeProblem(t, w).Code)
+				return
+			}
+
+			var resp httpdto.BatchResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			statuses := make([]int, 0, len(resp.Results))
+			for i, result := range resp.Results {
+				require.Equal(t, i, result.Index)
+				statuses = append(statuses, result.Status)
+				if result.Status >= http.StatusBadRequest {
+					require.NotNil(t, result.Error)
+					require.Equal(t, result.Status, result.Error.Status)
+				}
+			}
+			require.Equal(t, data.statuses, statuses)
+		})
+	}
+}
+
+func TestToDoHandlers_HandlerUpdateTaskByID(t *testing.T) {
+	testData := []struct {
+		name    string
+		path    string
+		body    string
+		code    int
+		errCode httpdto.ErrorCode
+		task    httpdto.TaskItem
+	}{
+		{
+			name: "Partial change",
+			path: "/tasks/1",
+			body: `{"is_done":true}`,
+			code: http.StatusOK,
+			task: httpdto.TaskItem{ID: 1, Title: "Buy milk", IsDone: true, Priority: "normal", Tags: []string{}},
+		},
+		{
+			name: "Both fields",
+			path: "/tasks/1",
+			body: `{"title":"Buy oat milk","is_done":false}`,
+			code: http.StatusOK,
+			task: httpdto.TaskItem{ID: 1, Title: "Buy oat milk", IsDone: false, Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:    "Unknown task",
+			path:    "/tasks/42",
+			body:    `{"is_done":true}`,
+			code:    http.StatusNotFound,
+			errCode: httpdto.ErrorCodeTaskNotFound,
+		},
+		{
+			name:    "Invalid id",
+			path:    "/tasks/abc",
+			body:    `{"is_done":true}`,
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeBadRequest,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router, pr := newTestRouter(t)
+			pr.AddTask(testUserID, "Buy milk", false)
+
+			req := httptest.NewRequest(http.MethodPatch, data.path, strings.NewReader(data.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := doRequest(router, req)
+
+			require.Equal(t, data.code, w.Code)
+
+			if data.errCode != "" {
+				require.Equal(t, data.errCode, decodeProblem(t, w).Code)
+				return
+			}
+
+			var resp httpdto.GetTaskByIDResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, data.task, withoutTimestamps(t, resp.Task))
+		})
+	}
+}
+
+func getETag(t *testing.T, router *gin.Engine, path string) string {
+	t.Helper()
+
+	w := doRequest(router, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tag := w.Header().Get("ETag")
+	require.NotEmpty(t, tag)
+	return tag
+}
+
+func TestToDoHandlers_HandlerGetTaskByID_IfNoneMatch(t *testing.T) {
+	router, pr := newTestRouter(t)
+	pr.AddTask(testUserID, "Buy milk", false)
+
+	tag := getETag(t, router, "/tasks/1")
+	require.Equal(t, tag, getETag(t, router, "/tasks/1"))
+
+	testData := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{name: "Same tag", header: tag, code: http.StatusNotModified},
+		{name: "Weak tag", header: "W/" + tag, code: http.StatusNotModified},
+		{name: "Tag in list", header: `"other", ` + tag, code: http.StatusNotModified},
+		{name: "Any", header: "*", code: http.StatusNotModified},
+		{name: "Other tag", header: `"other"`, code: http.StatusOK},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+			req.Header.Set("If-None-Match", data.header)
+			w := doRequest(router, req)
+
+			require.Equal(t, data.code, w.Code)
+			require.Equal(t, tag, w.Header().Get("ETag"))
+			if data.code == http.StatusNotModified {
+				require.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestToDoHandlers_IfMatch(t *testing.T) {
+	testData := []struct {
+		name    string
+		method  string
+		body    string
+		ifMatch func(tag string) string
+		code    int
+	}{
+		{
+			name:    "Update with current tag",
+			method:  http.MethodPatch,
+			body:    `{"title":"Buy oat milk"}`,
+			ifMatch: func(tag string) string { return tag },
+			code:    http.StatusOK,
+		},
+		{
+			name:    "Update with current tag in list",
+			method:  http.MethodPatch,
+			body:    `{"title":"Buy oat milk"}`,
+			ifMatch: func(tag string) string { return `"stale", ` + tag },
+			code:    http.StatusOK,
+		},
+		{
+			name:    "Update with any",
+			method:  http.MethodPatch,
+			body:    `{"title":"Buy oat milk"}`,
+			ifMatch: func(string) string { return "*" },
+			code:    http.StatusOK,
+		},
+		{
+			name:    "Update with stale tag",
+			method:  http.MethodPatch,
+			body:    `{"title":"Buy oat milk"}`,
+			ifMatch: func(string) string { return `"stale"` },
+			code:    http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Update with weak tag",
+			method:  http.MethodPatch,
+			body:    `{"title":"Buy oat milk"}`,
+			ifMatch: func(tag string) string { return "W/" + tag },
+			code:    http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Delete with current tag",
+			method:  http.MethodDelete,
+			ifMatch: func(tag string) string { return tag },
+			code:    http.StatusOK,
+		},
+		{
+			name:    "Delete with stale tag",
+			method:  http.MethodDelete,
+			ifMatch: func(string) string { return `"stale", "other"` },
+			code:    http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router, pr := newTestRouter(t)
+			pr.AddTask(testUserID, "Buy milk", false)
+			tag := getETag(t, router, "/tasks/1")
+
+			req := httptest.NewRequest(data.method, "/tasks/1", strings.NewReader(data.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", data.ifMatch(tag))
+			w := doRequest(router, req)
+
+			require.Equal(t, data.code, w.Code)
+
+			if data.code == http.StatusPreconditionFailed {
+				require.Equal(t, httpdto.ErrorCodeVersionMismatch, decodeProblem(t, w).Code)
+				require.Equal(t, tag, getETag(t, router, "/tasks/1"))
+				return
+			}
+
+			if data.method == http.MethodPatch {
+				newTag := w.Header().Get("ETag")
+				require.NotEqual(t, tag, newTag)
+				require.Equal(t, newTag, getETag(t, router, "/tasks/1"))
+			}
+		})
+	}
+}
+
+func TestToDoHandlers_IfMatch_LostUpdate(t *testing.T) {
+	router, pr := newTestRouter(t)
+	pr.AddTask(testUserID, "Buy milk", false)
+	tag := getETag(t, router, "/tasks/1")
+
+	patch := func(body string) int {
+		req := httptest.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", tag)
+		return doRequest(router, req).Code
+	}
+
+	// Both clients read the same version, only the first change wins
+	require.Equal(t, http.StatusOK, patch(`{"title":"First client"}`))
+	require.Equal(t, http.StatusPreconditionFailed, patch(`{"title":"Second client"}`))
+}
+
+func TestToDoHandlers_HandlerCreateTask_IdempotencyKey(t *testing.T) {
+	router, pr := newTestRouter(t)
+
+	create := func(key string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		return doRequest(router, req)
+	}
+
+	countTasks := func() int {
+		w := doRequest(router, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+		var resp httpdto.GetTaskListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Total
+	}
+
+	first := create("key-1", `{"title":"Buy milk"}`)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	// Retry with the same body differently formatted
+	retry := create("key-1", `{ "title" : "Buy milk" }`)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	require.Equal(t, first.Body.String(), retry.Body.String())
+	require.Equal(t, first.Header().Get("ETag"), retry.Header().Get("ETag"))
+	require.Equal(t, 1, countTasks())
+
+	reused := create("key-1", `{"title":"Buy bread"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	require.Equal(t, httpdto.ErrorCodeIdempotencyKeyReused, decodeProblem(t, reused).Code)
+
+	invalid := create(strings.Repeat("k", 256), `{"title":"Buy milk"}`)
+	require.Equal(t, http.StatusBadRequest, invalid.Code)
+	require.Equal(t, httpdto.ErrorCodeInvalidIdempotencyKey, decodeProblem(t, invalid).Code)
+
+	require.Equal(t, http.StatusOK, create("key-2", `{"title":"Buy milk"}`).Code)
+	require.Equal(t, http.StatusOK, create("", `{"title":"Buy milk"}`).Code)
+	require.Equal(t, 3, countTasks())
+
+	// Failed request does not keep the key
+	pr.SetDown(true)
+	failed := create("key-3", `{"title":"Buy milk"}`)
+	require.Equal(t, http.StatusServiceUnavailable, failed.Code)
+
+	pr.SetDown(false)
+	require.Equal(t, http.StatusOK, create("key-3", `{"title":"Buy milk"}`).Code)
+	require.Equal(t, 4, countTasks())
+}
+
+func TestToDoHandlers_HandlerUpdateTaskByID_Patch(t *testing.T) {
+	testData := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		code        int
+		errCode     httpdto.ErrorCode
+		task        httpdto.TaskItem
+	}{
+		{
+			name:        "Replace",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			body:        `{"title":"Buy bread","is_done":true}`,
+			code:        http.StatusOK,
+			task:        httpdto.TaskItem{ID: 1, Title: "Buy bread", IsDone: true, Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:        "Replace without field",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			body:        `{"title":"Buy bread"}`,
+			code:        http.StatusBadRequest,
+			errCode:     httpdto.ErrorCodeValidationFailed,
+		},
+		{
+			name:        "Merge patch",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			body:        `{"is_done":true}`,
+			code:        http.StatusOK,
+			task:        httpdto.TaskItem{ID: 1, Title: "Buy milk", IsDone: true, Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:        "Merge patch removing title",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			body:        `{"title":null}`,
+			code:        http.StatusUnprocessableEntity,
+			errCode:     httpdto.ErrorCodeUnsupportedPatch,
+		},
+		{
+			name:        "JSON patch",
+			method:      http.MethodPatch,
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"test","path":"/title","value":"Buy milk"},{"op":"replace","path":"/title","value":"Buy bread"}]`,
+			code:        http.StatusOK,
+			task:        httpdto.TaskItem{ID: 1, Title: "Buy bread", IsDone: false, Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:        "JSON patch test failed",
+			method:      http.MethodPatch,
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"test","path":"/is_done","value":true}]`,
+			code:        http.StatusConflict,
+			errCode:     httpdto.ErrorCodePatchTestFailed,
+		},
+		{
+			name:        "JSON patch unsupported operation",
+			method:      http.MethodPatch,
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"copy","from":"/title","path":"/is_done"}]`,
+			code:        http.StatusUnprocessableEntity,
+			errCode:     httpdto.ErrorCodeUnsupportedPatch,
+		},
+		{
+			name:        "Malformed JSON patch",
+			method:      http.MethodPatch,
+			contentType: "application/json-patch+json",
+			body:        `{"op":"replace"}`,
+			code:        http.StatusBadRequest,
+			errCode:     httpdto.ErrorCodeInvalidPatch,
+		},
+		{
+			name:        "Unsupported content type",
+			method:      http.MethodPatch,
+			contentType: "text/plain",
+			body:        `title=x`,
+			code:        http.StatusUnsupportedMediaType,
+			errCode:     httpdto.ErrorCodeUnsupportedMediaType,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router, pr := newTestRouter(t)
+			pr.AddTask(testUserID, "Buy milk", false)
+
+			req := httptest.NewRequest(data.method, "/tasks/1", strings.NewReader(data.body))
+			req.Header.Set("Content-Type", data.contentType)
+			w := doRequest(router, req)
+
+			require.Equal(t, data.code, w.Code)
+
+			if data.errCode != "" {
+				require.Equal(t, data.errCode, decodeProblem(t, w).Code)
+				return
+			}
+
+			var resp httpdto.GetTaskByIDResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, data.task, withoutTimestamps(t, resp.Task))
+			require.Equal(t, w.Header().Get("ETag"), getETag(t, router, "/tasks/1"))
+		})
+	}
+}
+
+func TestToDoHandlers_TaskDetails(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	send := func(method string, path string, contentType string, body string) httpdto.TaskItem {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := doRequest(router, req)
+		require.Contains(t, []int{http.StatusOK, http.StatusCreated}, w.Code, w.Body.String())
+
+		var resp httpdto.GetTaskByIDResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return withoutTimestamps(t, resp.Task)
+	}
+
+	dueDate := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	task := send(http.MethodPost, "/tasks", "application/json",
+		`{"title":"Plan trip","description":"Book hotel","due_date":"2024-05-01T18:00:00Z","priority":"high","tags":[" travel "]}`)
+	require.Equal(t, httpdto.TaskItem{
+		ID:          1,
+		Title:       "Plan trip",
+		Description: "Book hotel",
+		DueDate:     &dueDate,
+		Priority:    "high",
+		Tags:        []string{"travel"},
+	}, task)
+
+	task = send(http.MethodPatch, "/tasks/1", "application/merge-patch+json", `{"due_date":null,"tags":["travel","2024"]}`)
+	require.Nil(t, task.DueDate)
+	require.Equal(t, "Book hotel", task.Description)
+	require.Equal(t, []string{"travel", "2024"}, task.Tags)
+
+	task = send(http.MethodPatch, "/tasks/1", "application/json-patch+json", `[{"op":"remove","path":"/description"}]`)
+	require.Equal(t, "", task.Description)
+	require.Equal(t, "high", task.Priority)
+
+	task = send(http.MethodPut, "/tasks/1", "application/json", `{"title":"Plan trip","is_done":true}`)
+	require.Equal(t, httpdto.TaskItem{ID: 1, Title: "Plan trip", IsDone: true, Priority: "normal", Tags: []string{}}, task)
+}
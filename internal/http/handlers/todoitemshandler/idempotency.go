@@ -0,0 +1,142 @@
+package todoitemshandler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength  = 255
+)
+
+// replayedHeaders are response headers stored along with the body
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+type IIdempotencyStore interface {
+	Begin(ctx context.Context, key string, fingerprint string) (*idempotency.Response, error)
+	Complete(ctx context.Context, key string, response idempotency.Response) error
+	Release(ctx context.Context, key string) error
+}
+
+// recordingWriter Copies the response body written by handler
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > idempotencyKeyMaxLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFingerprint Returns hash of the bound request, insensitive to JSON formatting
+func requestFingerprint(request any) string {
+	data, _ := json.Marshal(request)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// runIdempotent Runs handler once per user Idempotency-Key. Successful responses are stored
+// and replayed on retries, failed ones release the key so the request can be retried
+func (h *ToDoHandlers) runIdempotent(c *gin.Context, userID uint64, request any, handler gin.HandlerFunc) {
+	clientKey := c.GetHeader(idempotencyKeyHeader)
+	if clientKey == "" {
+		handler(c)
+		return
+	}
+
+	if !validIdempotencyKey(clientKey) {
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeInvalidIdempotencyKey,
+			"Idempotency-Key must be 1-255 printable ASCII characters")
+		return
+	}
+
+	log := h.logging.With("method", "runIdempotent")
+	ctx := context.WithoutCancel(c.Request.Context())
+	key := strconv.FormatUint(userID, 10) + ":" + clientKey
+
+	stored, err := h.idempotency.Begin(ctx, key, requestFingerprint(request))
+	if err != nil {
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	if stored != nil {
+		for name, value := range stored.Header {
+			c.Header(name, value)
+		}
+		c.Header(idempotentReplayedHeader, "true")
+		c.Status(stored.StatusCode)
+		_, _ = c.Writer.Write(stored.Body)
+		return
+	}
+
+	writer := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	completed := false
+	defer func() {
+		c.Writer = writer.ResponseWriter
+
+		if completed {
+			return
+		}
+		if err := h.idempotency.Release(ctx, key); err != nil {
+			log.Error("idempotency key release error", slog.Any("err", err))
+		}
+	}()
+
+	handler(c)
+
+	status := writer.Status()
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return
+	}
+
+	response := idempotency.Response{
+		StatusCode: status,
+		Header:     make(map[string]string, len(replayedHeaders)),
+		Body:       writer.body.Bytes(),
+	}
+	for _, name := range replayedHeaders {
+		if value := writer.Header().Get(name); value != "" {
+			response.Header[name] = value
+		}
+	}
+
+	if err := h.idempotency.Complete(ctx, key, response); err != nil {
+		log.Error("idempotency response store error", slog.Any("err", err))
+		return
+	}
+	completed = true
+}
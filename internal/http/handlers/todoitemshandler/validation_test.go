@@ -0,0 +1,200 @@
+package todoitemshandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todoapiservice/internal/http/httpdto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDoHandlers_Validation(t *testing.T) {
+	longTitle := strings.Repeat("я", 257)
+
+	testData := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		errors []httpdto.FieldError
+		task   *httpdto.TaskItem
+	}{
+		{
+			name:   "Create without title",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{}`,
+			errors: []httpdto.FieldError{{Field: "title", Message: "field is required"}},
+		},
+		{
+			name:   "Create with blank title",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"   "}`,
+			errors: []httpdto.FieldError{{Field: "title", Message: "must be at least 1 characters long"}},
+		},
+		{
+			name:   "Create with too long title",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"` + longTitle + `"}`,
+			errors: []httpdto.FieldError{{Field: "title", Message: "must be at most 256 characters long"}},
+		},
+		{
+			name:   "Create with control characters",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"Buy\nmilk"}`,
+			errors: []httpdto.FieldError{{Field: "title", Message: "must not contain control characters"}},
+		},
+		{
+			name:   "Create with unknown field",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"Buy milk","due":"tomorrow"}`,
+			errors: []httpdto.FieldError{{Field: "due", Message: "unknown field"}},
+		},
+		{
+			name:   "Create with wrong type",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"Buy milk","is_done":"yes"}`,
+			errors: []httpdto.FieldError{{Field: "is_done", Message: "must be a boolean"}},
+		},
+		{
+			name:   "Create trims title",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"  Buy bread \t","is_done":true}`,
+			task:   &httpdto.TaskItem{ID: 2, Title: "Buy bread", IsDone: true, Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:   "Create with longest title",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"` + longTitle[len("я"):] + `"}`,
+			task:   &httpdto.TaskItem{ID: 2, Title: longTitle[len("я"):], Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:   "Empty patch",
+			method: http.MethodPatch,
+			path:   "/tasks/1",
+			body:   `{}`,
+			errors: []httpdto.FieldError{
+				{Field: "title", Message: "at least one field must be set"},
+			},
+		},
+		{
+			name:   "Create with unknown priority",
+			method: http.MethodPost,
+			path:   "/tasks",
+			body:   `{"title":"Buy milk","priority":"urgent"}`,
+			errors: []httpdto.FieldError{{Field: "priority", Message: "must be one of: low normal high"}},
+		},
+		{
+			name:   "Patch with duplicate tags",
+			method: http.MethodPatch,
+			path:   "/tasks/1",
+			body:   `{"tags":["shop"," shop"]}`,
+			errors: []httpdto.FieldError{{Field: "tags", Message: "must not contain duplicates"}},
+		},
+		{
+			name:   "Patch with blank tag",
+			method: http.MethodPatch,
+			path:   "/tasks/1",
+			body:   `{"tags":[" "]}`,
+			errors: []httpdto.FieldError{{Field: "tags[0]", Message: "must be at least 1 characters long"}},
+		},
+		{
+			name:   "Patch with unknown field",
+			method: http.MethodPatch,
+			path:   "/tasks/1",
+			body:   `{"is_done":true,"id":5}`,
+			errors: []httpdto.FieldError{{Field: "id", Message: "unknown field"}},
+		},
+		{
+			name:   "Patch with blank title",
+			method: http.MethodPatch,
+			path:   "/tasks/1",
+			body:   `{"title":" "}`,
+			errors: []httpdto.FieldError{{Field: "title", Message: "must be at least 1 characters long"}},
+		},
+		{
+			name:   "Patch trims title",
+			method: http.MethodPatch,
+			path:   "/tasks/1",
+			body:   `{"title":" Buy bread "}`,
+			task:   &httpdto.TaskItem{ID: 1, Title: "Buy bread", Priority: "normal", Tags: []string{}},
+		},
+		{
+			name:   "Replace with control characters",
+			method: http.MethodPut,
+			path:   "/tasks/1",
+			body:   "{\"title\":\"Buy\\u0000milk\",\"is_done\":false}",
+			errors: []httpdto.FieldError{{Field: "title", Message: "must not contain control characters"}},
+		},
+		{
+			name:   "Batch operation title",
+			method: http.MethodPost,
+			path:   "/tasks:batch",
+			body:   `{"operations":[{"op":"delete","id":1},{"op":"create","title":"\t"}]}`,
+			errors: []httpdto.FieldError{{Field: "operations[1].title", Message: "must be at least 1 characters long"}},
+		},
+		{
+			name:   "Batch unknown field",
+			method: http.MethodPost,
+			path:   "/tasks:batch",
+			body:   `{"operations":[{"op":"delete","id":1,"force":true}]}`,
+			errors: []httpdto.FieldError{{Field: "force", Message: "unknown field"}},
+		},
+		{
+			name:   "Empty batch",
+			method: http.MethodPost,
+			path:   "/tasks:batch",
+			body:   `{"operations":[]}`,
+			errors: []httpdto.FieldError{{Field: "operations", Message: "must be at least 1 items"}},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router, pr := newTestRouter(t)
+			pr.AddTask(testUserID, "Buy milk", false)
+
+			req := httptest.NewRequest(data.method, data.path, strings.NewReader(data.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := doRequest(router, req)
+
+			if data.task != nil {
+				require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+				var resp httpdto.GetTaskByIDResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Equal(t, *data.task, withoutTimestamps(t, resp.Task))
+				return
+			}
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+
+			problem := decodeProblem(t, w)
+			require.Equal(t, httpdto.ErrorCodeValidationFailed, problem.Code)
+			require.Equal(t, data.errors, problem.Errors)
+		})
+	}
+}
+
+func TestToDoHandlers_Validation_Malformed(t *testing.T) {
+	for _, body := range []string{``, `{"title":`, `{"title":"a"} {}`, `[]`} {
+		router, _ := newTestRouter(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := doRequest(router, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code, body)
+		require.Equal(t, httpdto.ErrorCodeBadRequest, decodeProblem(t, w).Code, body)
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/coredto"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Normalizer is implemented by request DTOs adjusting values before validation, e.g. trimming spaces
+type Normalizer interface {
+	Normalize()
+}
+
+// decodeError is a JSON body decoding error bound to a field
+type decodeError struct {
+	field   string
+	message string
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.field, e.message)
+}
+
+func init() {
+	// Custom rules are registered once for gin default validator.
+	// Task field aliases keep request limits in one place with the patch ones
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("nocontrol", validateNoControl)
+		v.RegisterAlias("tasktitle", fmt.Sprintf("min=1,max=%d,nocontrol", coredto.MaxTitleLength))
+		v.RegisterAlias("taskdescription", fmt.Sprintf("max=%d", coredto.MaxDescriptionLength))
+		v.RegisterAlias("tasktags", fmt.Sprintf("max=%d", coredto.MaxTags))
+		v.RegisterAlias("tasktag", fmt.Sprintf("min=1,max=%d,nocontrol", coredto.MaxTagLength))
+	}
+}
+
+// validateNoControl Rejects strings with control characters, line breaks included
+func validateNoControl(fl validator.FieldLevel) bool {
+	return strings.IndexFunc(fl.Field().String(), unicode.IsControl) < 0
+}
+
+// BindStrictJSON Decodes JSON body into obj rejecting unknown fields, normalizes and validates it
+func BindStrictJSON(c *gin.Context, obj any) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(obj); err != nil {
+		return jsonDecodeError(err)
+	}
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after JSON body")
+	}
+
+	if normalizer, ok := obj.(Normalizer); ok {
+		normalizer.Normalize()
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+func jsonDecodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &decodeError{field: typeErr.Field, message: "must be " + jsonTypeName(typeErr.Type)}
+	}
+
+	// encoding/json has no typed error for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &decodeError{field: strings.Trim(field, `"`), message: "unknown field"}
+	}
+	return err
+}
+
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// SendValidationErrorResponse Sends 400 problem response with per field binding errors of obj
+func SendValidationErrorResponse(c *gin.Context, obj any, err error) {
+	fieldErrors := FieldErrors(obj, err)
+	if len(fieldErrors) == 0 {
+		SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeBadRequest, "malformed request")
+		return
+	}
+
+	problem := newProblem(c, http.StatusBadRequest, httpdto.ErrorCodeValidationFailed, "request validation failed")
+	problem.Errors = fieldErrors
+	sendProblem(c, problem)
+}
+
+// FieldErrors Converts binding error to field errors named after obj json or form tags.
+// Nested fields are named as a path, e.g. operations[1].title.
+// Returns nil if err is not a validation error
+func FieldErrors(obj any, err error) []httpdto.FieldError {
+	var decodeErr *decodeError
+	if errors.As(err, &decodeErr) {
+		return []httpdto.FieldError{{Field: decodeErr.field, Message: decodeErr.message}}
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	result := make([]httpdto.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		result = append(result, httpdto.FieldError{
+			Field:   jsonFieldPath(obj, fe.StructNamespace()),
+			Message: fieldErrorMessage(obj, fe),
+		})
+	}
+	return result
+}
+
+func structType(obj any) reflect.Type {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonFieldPath Converts validator namespace like BatchRequest.Operations[1].Title to a json path
+func jsonFieldPath(obj any, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 1 {
+		// The first segment is the root struct name
+		segments = segments[1:]
+	}
+
+	t := structType(obj)
+	path := make([]string, 0, len(segments))
+
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		jsonName := name
+		if t != nil && t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				jsonName = tagName(field, name)
+				t = field.Type
+
+				// Embedded struct fields are promoted to the parent JSON object
+				if field.Anonymous && jsonName == name {
+					jsonName = ""
+				}
+			} else {
+				t = nil
+			}
+		}
+		if jsonName != "" {
+			path = append(path, jsonName+index)
+		}
+
+		for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// jsonFieldName Returns json or form name of obj top level field
+func jsonFieldName(obj any, structField string) string {
+	t := structType(obj)
+	if t == nil || t.Kind() != reflect.Struct {
+		return structField
+	}
+
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	return tagName(field, structField)
+}
+
+func tagName(field reflect.StructField, fallback string) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return fallback
+}
+
+// boundDescription Describes min or max parameter depending on the field kind
+func boundDescription(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fe.Param() + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fe.Param() + " items"
+	default:
+		return fe.Param()
+	}
+}
+
+func fieldErrorMessage(obj any, fe validator.FieldError) string {
+	// Aliases are reported by the rule which failed
+	switch fe.ActualTag() {
+	case "required":
+		return "field is required"
+	case "required_without":
+		return fmt.Sprintf("field is required when %s is not set", jsonFieldName(obj, fe.Param()))
+	case "required_without_all":
+		return "at least one field must be set"
+	case "max":
+		return "must be at most " + boundDescription(fe)
+	case "min":
+		return "must be at least " + boundDescription(fe)
+	case "unique":
+		return "must not contain duplicates"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "nocontrol":
+		return "must not contain control characters"
+	default:
+		return fmt.Sprintf("failed on %q validation", fe.ActualTag())
+	}
+}
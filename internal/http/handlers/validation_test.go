@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaskLimitAliases_Documented Checks swagger limits of aliased fields against the validated ones
+func TestTaskLimitAliases_Documented(t *testing.T) {
+	documented := map[string]struct {
+		tag   string
+		limit int
+	}{
+		"tasktitle":       {tag: "maxLength", limit: coredto.MaxTitleLength},
+		"taskdescription": {tag: "maxLength", limit: coredto.MaxDescriptionLength},
+		"tasktags":        {tag: "maxItems", limit: coredto.MaxTags},
+	}
+
+	checked := 0
+	for _, obj := range []any{
+		httpdto.TaskItemDetails{},
+		httpdto.TaskItemCreate{},
+		httpdto.TaskItemChanges{},
+		httpdto.TaskItemReplace{},
+		httpdto.BatchOperation{},
+		httpdto.ChecklistItem{},
+		httpdto.SubtaskCreate{},
+		httpdto.SubtaskChanges{},
+	} {
+		objType := reflect.TypeOf(obj)
+		for i := 0; i < objType.NumField(); i++ {
+			field := objType.Field(i)
+			for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+				if doc, ok := documented[rule]; ok {
+					require.Equal(t, strconv.Itoa(doc.limit), field.Tag.Get(doc.tag), "%s.%s", objType.Name(), field.Name)
+					checked++
+				}
+			}
+		}
+	}
+	require.NotZero(t, checked)
+}
@@ -3,6 +3,18 @@ package httpdto
 
 type LoginResponse struct {
 	GeneralResponse
-	Token string `json:"token"`
-	// RefreshToken string `json:"refresh_token"`
+	Token            string `json:"token"`
+	TokenType        string `json:"token_type" example:"Bearer"`
+	ExpiresIn        int64  `json:"expires_in" example:"900"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in" example:"2592000"`
 } //@Name LoginResponse
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+} //@Name RefreshTokenRequest
+
+type LoginRequest struct {
+	Email    string `json:"email" form:"email" binding:"required,max=254" example:"user@example.com"`
+	Password string `json:"password" form:"password" binding:"required,max=1024" example:"secret"`
+} //@Name LoginRequest
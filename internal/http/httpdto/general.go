@@ -10,3 +10,8 @@ const (
 type GeneralResponse struct {
 	Status GeneralResponseStatus `json:"status"`
 } //@Name GeneralResponse
+
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Message string `json:"message" example:"field is required"`
+} //@Name FieldError
@@ -0,0 +1,15 @@
+package httpdto
+
+import "time"
+
+type DependencyStatus struct {
+	Status GeneralResponseStatus `json:"status"`
+	Detail string                `json:"detail,omitempty" example:"READY"`
+} //@Name DependencyStatus
+
+type ReadinessResponse struct {
+	GeneralResponse
+	Draining     bool                        `json:"draining,omitempty"`
+	CheckedAt    time.Time                   `json:"checked_at"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+} //@Name ReadinessResponse
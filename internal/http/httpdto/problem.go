@@ -0,0 +1,56 @@
+package httpdto
+
+// ProblemContentType is RFC 7807 error response media type
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix prefixes error code to build problem type URI
+const ProblemTypePrefix = "urn:todoapi:problem:"
+
+// ErrorCode is a stable machine-readable error identifier
+type ErrorCode string //@Name ErrorCode
+
+const (
+	ErrorCodeBadRequest            ErrorCode = "bad_request"                  // Request is malformed
+	ErrorCodeValidationFailed      ErrorCode = "validation_failed"            // Request fields failed validation, see errors
+	ErrorCodeUnauthorized          ErrorCode = "unauthorized"                 // Authentication is required
+	ErrorCodeInvalidAuthHeader     ErrorCode = "invalid_authorization_header" // Authorization header is not a bearer token
+	ErrorCodeInvalidToken          ErrorCode = "invalid_token"                // Bearer token is invalid, expired or revoked
+	ErrorCodeBadCredentials        ErrorCode = "bad_credentials"              // Login or password is incorrect
+	ErrorCodeAccountLocked         ErrorCode = "account_locked"               // Account is locked
+	ErrorCodeInvalidRefreshToken   ErrorCode = "invalid_refresh_token"        // Refresh token is invalid, expired or already used
+	ErrorCodeNotFound              ErrorCode = "not_found"                    // Resource is not found
+	ErrorCodeTaskNotFound          ErrorCode = "task_not_found"               // Task is not found or belongs to another user
+	ErrorCodeSubtaskNotFound       ErrorCode = "subtask_not_found"            // Subtask is not found
+	ErrorCodeTooManySubtasks       ErrorCode = "too_many_subtasks"            // Task has the maximum number of subtasks
+	ErrorCodeVersionMismatch       ErrorCode = "version_mismatch"             // Task was changed since the ETag given in If-Match was issued
+	ErrorCodeInvalidPatch          ErrorCode = "invalid_patch"                // Patch document is malformed
+	ErrorCodeUnsupportedPatch      ErrorCode = "unsupported_patch"            // Patch operation, path or change is not supported
+	ErrorCodePatchTestFailed       ErrorCode = "patch_test_failed"            // JSON Patch test operation did not match the task
+	ErrorCodeUnsupportedMediaType  ErrorCode = "unsupported_media_type"       // Request body content type is not supported
+	ErrorCodeInvalidCursor         ErrorCode = "invalid_cursor"               // Page cursor is malformed or was issued for other list parameters
+	ErrorCodeInvalidSearchQuery    ErrorCode = "invalid_search_query"         // Search query has no words to match
+	ErrorCodeBatchTooLarge         ErrorCode = "batch_too_large"              // Batch has more operations than allowed
+	ErrorCodeInvalidOperation      ErrorCode = "invalid_operation"            // Batch operation fields are inconsistent
+	ErrorCodeBatchAborted          ErrorCode = "batch_aborted"                // Atomic batch operation was not executed because another one failed
+	ErrorCodeRolledBack            ErrorCode = "rolled_back"                  // Atomic batch operation was applied and then reverted
+	ErrorCodeInvalidIdempotencyKey ErrorCode = "invalid_idempotency_key"      // Idempotency-Key header is too long or has non printable characters
+	ErrorCodeIdempotencyKeyReused  ErrorCode = "idempotency_key_reused"       // Idempotency-Key was already used with another request body
+	ErrorCodeIdempotencyKeyInUse   ErrorCode = "idempotency_key_in_use"       // Request with the same Idempotency-Key is still in progress
+	ErrorCodeInvalidRequestTimeout ErrorCode = "invalid_request_timeout"      // Request-Timeout header is not a positive duration
+	ErrorCodeInternal              ErrorCode = "internal_error"               // Unexpected server error
+	ErrorCodeBackendUnavailable    ErrorCode = "backend_unavailable"          // Backend service is unavailable, retry later
+	ErrorCodeRequestTimeout        ErrorCode = "request_timeout"              // Request deadline was exceeded before the response was ready
+	ErrorCodeBackendTimeout        ErrorCode = "backend_timeout"              // Backend service did not answer in time
+)
+
+// Problem is RFC 7807 problem details error response
+type Problem struct {
+	Type      string       `json:"type" example:"urn:todoapi:problem:task_not_found"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"task 42 not found"`
+	Instance  string       `json:"instance,omitempty" example:"/api/v1/tasks/42"`
+	Code      ErrorCode    `json:"code" example:"task_not_found"`
+	RequestID string       `json:"request_id,omitempty" example:"4b0c2e4f9a1d3c7e"`
+	Errors    []FieldError `json:"errors,omitempty"`
+} //@Name Problem
@@ -0,0 +1,59 @@
+package httpdto
+
+type ChecklistItem struct {
+	Title  string `json:"title" binding:"tasktitle" minLength:"1" maxLength:"256" example:"Check expiry date"`
+	IsDone bool   `json:"is_done"`
+} //@name ChecklistItem
+
+type Subtask struct {
+	ID        uint64          `json:"id"`
+	Title     string          `json:"title"`
+	IsDone    bool            `json:"is_done"`
+	Checklist []ChecklistItem `json:"checklist"`
+} //@name Subtask
+
+// TaskProgress counts done subtasks
+type TaskProgress struct {
+	Done  int `json:"done" example:"1"`
+	Total int `json:"total" example:"3"`
+} //@name TaskProgress
+
+// SubtaskCreate Titles are trimmed, then must be 1-256 characters long without control characters
+type SubtaskCreate struct {
+	Title     *string         `json:"title" binding:"required,tasktitle" minLength:"1" maxLength:"256" example:"Buy milk"`
+	IsDone    *bool           `json:"is_done,omitempty"`
+	Checklist []ChecklistItem `json:"checklist,omitempty" binding:"omitempty,max=50,dive" maxItems:"50"`
+} //@name SubtaskCreate
+
+// SubtaskChanges At least one field must be set. Checklist replaces the whole list
+type SubtaskChanges struct {
+	Title     *string         `json:"title,omitempty" binding:"required_without_all=IsDone Checklist,omitempty,tasktitle" minLength:"1" maxLength:"256" example:"Buy milk"`
+	IsDone    *bool           `json:"is_done,omitempty"`
+	Checklist []ChecklistItem `json:"checklist,omitempty" binding:"omitempty,max=50,dive" maxItems:"50"`
+} //@name SubtaskChanges
+
+func (r *SubtaskCreate) Normalize() {
+	r.Title = trimTitle(r.Title)
+	normalizeChecklist(r.Checklist)
+}
+
+func (r *SubtaskChanges) Normalize() {
+	r.Title = trimTitle(r.Title)
+	normalizeChecklist(r.Checklist)
+}
+
+func normalizeChecklist(checklist []ChecklistItem) {
+	for i := range checklist {
+		checklist[i].Title = *trimTitle(&checklist[i].Title)
+	}
+}
+
+type GetSubtasksResponse struct {
+	GeneralResponse
+	Subtasks []Subtask `json:"subtasks"`
+} //@name GetSubtasksResponse
+
+type GetSubtaskResponse struct {
+	GeneralResponse
+	Subtask Subtask `json:"subtask"`
+} //@name GetSubtaskResponse
@@ -1,22 +1,155 @@
 package httpdto
 
+import (
+	"strings"
+	"time"
+)
+
 type TaskItem struct {
-	ID     uint64 `json:"id"`
-	Title  string `json:"title"`
-	IsDone bool   `json:"is_done"`
+	ID          uint64        `json:"id"`
+	Title       string        `json:"title"`
+	IsDone      bool          `json:"is_done"`
+	Description string        `json:"description"`
+	DueDate     *time.Time    `json:"due_date,omitempty" example:"2024-05-01T18:00:00Z"`
+	Priority    string        `json:"priority" enums:"low,normal,high" example:"normal"`
+	Tags        []string      `json:"tags"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+	Subtasks    *TaskProgress `json:"subtasks,omitempty"`
 } //@name TaskItem
 
+// TaskItemDetails are optional task fields. Tags are trimmed and must be unique
+type TaskItemDetails struct {
+	Description *string    `json:"description,omitempty" binding:"omitempty,taskdescription" maxLength:"2000"`
+	DueDate     *time.Time `json:"due_date,omitempty" example:"2024-05-01T18:00:00Z"`
+	Priority    *string    `json:"priority,omitempty" binding:"omitempty,oneof=low normal high" enums:"low,normal,high"`
+	Tags        []string   `json:"tags,omitempty" binding:"omitempty,tasktags,unique,dive,tasktag" maxItems:"20"`
+}
+
+// TaskItemCreate Titles are trimmed, then must be 1-256 characters long without control characters
+type TaskItemCreate struct {
+	Title  *string `json:"title" binding:"required,tasktitle" minLength:"1" maxLength:"256" example:"Buy milk"`
+	IsDone *bool   `json:"is_done,omitempty"`
+	TaskItemDetails
+} //@name TaskItemCreate
+
+// TaskItemChanges At least one field must be set. Empty description and tags clear them
 type TaskItemChanges struct {
-	Title  *string `json:"title,omitempty"`
+	Title  *string `json:"title,omitempty" binding:"required_without_all=IsDone Description DueDate Priority Tags,omitempty,tasktitle" minLength:"1" maxLength:"256" example:"Buy milk"`
 	IsDone *bool   `json:"is_done,omitempty"`
+	TaskItemDetails
 } //@name TaskItemChanges
 
+// TaskItemReplace Omitted optional fields are cleared
+type TaskItemReplace struct {
+	Title  *string `json:"title" binding:"required,tasktitle" minLength:"1" maxLength:"256" example:"Buy milk"`
+	IsDone *bool   `json:"is_done" binding:"required"`
+	TaskItemDetails
+} //@name TaskItemReplace
+
+func (r *TaskItemCreate) Normalize() {
+	r.Title = trimTitle(r.Title)
+	r.TaskItemDetails.normalize()
+}
+
+func (r *TaskItemChanges) Normalize() {
+	r.Title = trimTitle(r.Title)
+	r.TaskItemDetails.normalize()
+}
+
+func (r *TaskItemReplace) Normalize() {
+	r.Title = trimTitle(r.Title)
+	r.TaskItemDetails.normalize()
+}
+
+func (d *TaskItemDetails) normalize() {
+	for i, tag := range d.Tags {
+		d.Tags[i] = strings.TrimSpace(tag)
+	}
+}
+
+func trimTitle(title *string) *string {
+	if title == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*title)
+	return &trimmed
+}
+
+// JSONPatchOperation is RFC 6902 operation. Supported ops are add, replace and test on task fields,
+// remove on optional fields
+type JSONPatchOperation struct {
+	Op    string `json:"op" enums:"add,replace,test" example:"replace"`
+	Path  string `json:"path" example:"/is_done"`
+	Value any    `json:"value" swaggertype:"string" example:"true"`
+} //@name JSONPatchOperation
+
 type GetTaskListResponse struct {
 	GeneralResponse
-	Tasks []TaskItem `json:"tasks"`
+	Tasks      []TaskItem `json:"tasks"`
+	NextCursor string     `json:"next_cursor,omitempty" example:"eyJxIjoiNGYxYyIsImlkIjo0Mn0"`
+	Total      int        `json:"total" example:"1024"`
 } //@name GetTaskListResponse
 
+type TaskListQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Cursor string `form:"cursor" binding:"omitempty,max=1024"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=id -id title -title is_done -is_done"`
+	IsDone *bool  `form:"is_done"`
+	Title  string `form:"title" binding:"omitempty,max=256"`
+}
+
 type GetTaskByIDResponse struct {
 	GeneralResponse
 	Task TaskItem `json:"task"`
 } //@name GetTaskByIDResponse
+
+type TaskSearchQuery struct {
+	Q     string `form:"q" binding:"required,max=256"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type TaskSearchResult struct {
+	Task    TaskItem `json:"task"`
+	Score   float64  `json:"score" example:"1.1"`
+	Snippet string   `json:"snippet" example:"Buy <mark>milk</mark>"`
+} //@name TaskSearchResult
+
+type SearchTasksResponse struct {
+	GeneralResponse
+	Results []TaskSearchResult `json:"results"`
+} //@name SearchTasksResponse
+
+type BatchOperation struct {
+	Op     string  `json:"op" binding:"required,oneof=create update delete" enums:"create,update,delete"`
+	ID     *uint64 `json:"id,omitempty"`
+	Title  *string `json:"title,omitempty" binding:"omitempty,tasktitle" minLength:"1" maxLength:"256"`
+	IsDone *bool   `json:"is_done,omitempty"`
+	TaskItemDetails
+} //@name BatchOperation
+
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,dive"`
+} //@name BatchRequest
+
+func (r *BatchRequest) Normalize() {
+	for i := range r.Operations {
+		r.Operations[i].Title = trimTitle(r.Operations[i].Title)
+		r.Operations[i].TaskItemDetails.normalize()
+	}
+}
+
+type BatchOperationResult struct {
+	Index      int       `json:"index"`
+	Status     int       `json:"status" example:"200"`
+	Task       *TaskItem `json:"task,omitempty"`
+	Error      *Problem  `json:"error,omitempty"`
+	RolledBack bool      `json:"rolled_back,omitempty"`
+} //@name BatchOperationResult
+
+type BatchResponse struct {
+	GeneralResponse
+	Results    []BatchOperationResult `json:"results"`
+	RolledBack bool                   `json:"rolled_back,omitempty"`
+} //@name BatchResponse
@@ -0,0 +1,113 @@
+// Package deadlinemiddleware implements request deadline middleware
+package deadlinemiddleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/http/handlers"
+	"todoapiservice/internal/http/httpdto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Client timeout headers in priority order
+const (
+	HeaderName       = "Request-Timeout"
+	LegacyHeaderName = "X-Request-Timeout"
+)
+
+var errInvalidTimeout = errors.New("timeout must be positive seconds or duration like 1500ms")
+
+type DeadlineMiddleware struct {
+	conf configapplication.ApiTimeoutConfig
+}
+
+func New(conf configapplication.ApiTimeoutConfig) *DeadlineMiddleware {
+	return &DeadlineMiddleware{
+		conf: conf,
+	}
+}
+
+// parseTimeout Parses header value given as seconds (5, 0.5) or as duration (1500ms)
+func parseTimeout(value string) (time.Duration, error) {
+	var timeout time.Duration
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		timeout = time.Duration(seconds * float64(time.Second))
+	} else if timeout, err = time.ParseDuration(value); err != nil {
+		return 0, errInvalidTimeout
+	}
+
+	if timeout <= 0 {
+		return 0, errInvalidTimeout
+	}
+	return timeout, nil
+}
+
+var routeParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// routeTemplate Returns matched route template with path parameters in OpenAPI form:
+// /api/v1/tasks/:id -> /api/v1/tasks/{id}. Config map keys can not contain colons
+func routeTemplate(c *gin.Context) string {
+	return routeParam.ReplaceAllString(c.FullPath(), "{$1}")
+}
+
+// routeTimeout Returns timeout configured for "METHOD /path" or for "/path" with any method
+func (m *DeadlineMiddleware) routeTimeout(c *gin.Context) (time.Duration, bool) {
+	route := routeTemplate(c)
+	if route == "" {
+		return 0, false
+	}
+
+	if timeout, ok := m.conf.Routes[c.Request.Method+" "+route]; ok {
+		return timeout, true
+	}
+	timeout, ok := m.conf.Routes[route]
+	return timeout, ok
+}
+
+// timeout Returns request timeout: client header capped by the maximum,
+// otherwise route timeout or the default one. Zero means no deadline
+func (m *DeadlineMiddleware) timeout(c *gin.Context) (time.Duration, error) {
+	value := c.GetHeader(HeaderName)
+	if value == "" {
+		value = c.GetHeader(LegacyHeaderName)
+	}
+
+	if value != "" && m.conf.Max > 0 {
+		timeout, err := parseTimeout(strings.TrimSpace(value))
+		if err != nil {
+			return 0, err
+		}
+		return min(timeout, m.conf.Max), nil
+	}
+
+	if timeout, ok := m.routeTimeout(c); ok {
+		return timeout, nil
+	}
+	return m.conf.Default, nil
+}
+
+// Middleware Limits request context by the request timeout, so provider calls
+// are cancelled when the deadline is exceeded
+func (m *DeadlineMiddleware) Middleware(c *gin.Context) {
+	timeout, err := m.timeout(c)
+	if err != nil {
+		handlers.SendProblem(c, http.StatusBadRequest, httpdto.ErrorCodeInvalidRequestTimeout, err.Error())
+		return
+	}
+
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+
+	c.Next()
+}
@@ -0,0 +1,218 @@
+package deadlinemiddleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/http/handlers/authhandler"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/authprovider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type probeHandler struct {
+	deadline bool
+	left     time.Duration
+}
+
+func (h *probeHandler) HandlerProbe(c *gin.Context) {
+	deadline, ok := c.Request.Context().Deadline()
+	h.deadline = ok
+	h.left = time.Until(deadline)
+	c.Status(http.StatusOK)
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	conf := configapplication.ApiTimeoutConfig{
+		Default: 10 * time.Second,
+		Max:     20 * time.Second,
+		Routes:  map[string]time.Duration{"GET /tasks/{id}": 5 * time.Second, "/tasks/{id}": 7 * time.Second},
+	}
+
+	testData := []struct {
+		name     string
+		conf     configapplication.ApiTimeoutConfig
+		method   string
+		headers  map[string]string
+		code     int
+		deadline bool
+		left     time.Duration
+	}{
+		{
+			name:     "Route timeout",
+			conf:     conf,
+			code:     http.StatusOK,
+			deadline: true,
+			left:     5 * time.Second,
+		},
+		{
+			name:     "Route timeout for any method",
+			conf:     conf,
+			method:   http.MethodDelete,
+			code:     http.StatusOK,
+			deadline: true,
+			left:     7 * time.Second,
+		},
+		{
+			name:     "Default timeout",
+			conf:     configapplication.ApiTimeoutConfig{Default: 10 * time.Second, Max: 20 * time.Second},
+			code:     http.StatusOK,
+			deadline: true,
+			left:     10 * time.Second,
+		},
+		{
+			name: "No timeout",
+			conf: configapplication.ApiTimeoutConfig{},
+			code: http.StatusOK,
+		},
+		{
+			name:     "Client timeout in seconds",
+			conf:     conf,
+			headers:  map[string]string{HeaderName: "1.5"},
+			code:     http.StatusOK,
+			deadline: true,
+			left:     1500 * time.Millisecond,
+		},
+		{
+			name:     "Client timeout as duration in legacy header",
+			conf:     conf,
+			headers:  map[string]string{LegacyHeaderName: "700ms"},
+			code:     http.StatusOK,
+			deadline: true,
+			left:     700 * time.Millisecond,
+		},
+		{
+			name:     "Standard header takes precedence",
+			conf:     conf,
+			headers:  map[string]string{HeaderName: "2", LegacyHeaderName: "3"},
+			code:     http.StatusOK,
+			deadline: true,
+			left:     2 * time.Second,
+		},
+		{
+			name:     "Client timeout is capped",
+			conf:     conf,
+			headers:  map[string]string{HeaderName: "3600"},
+			code:     http.StatusOK,
+			deadline: true,
+			left:     20 * time.Second,
+		},
+		{
+			name:     "Client timeout is ignored without cap",
+			conf:     configapplication.ApiTimeoutConfig{Default: 10 * time.Second},
+			headers:  map[string]string{HeaderName: "1"},
+			code:     http.StatusOK,
+			deadline: true,
+			left:     10 * time.Second,
+		},
+		{
+			name:    "Invalid client timeout",
+			conf:    conf,
+			headers: map[string]string{HeaderName: "soon"},
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "Negative client timeout",
+			conf:    conf,
+			headers: map[string]string{HeaderName: "-1"},
+			code:    http.StatusBadRequest,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			probe := &probeHandler{}
+			router := gin.New()
+			router.Use(New(data.conf).Middleware)
+			router.GET("/tasks/:id", probe.HandlerProbe)
+			router.DELETE("/tasks/:id", probe.HandlerProbe)
+
+			method := data.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "/tasks/1", nil)
+			for name, value := range data.headers {
+				req.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, data.code, w.Code)
+			if data.code != http.StatusOK {
+				var problem httpdto.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				require.Equal(t, httpdto.ErrorCodeInvalidRequestTimeout, problem.Code)
+				return
+			}
+
+			require.Equal(t, data.deadline, probe.deadline)
+			if data.deadline {
+				require.InDelta(t, data.left, probe.left, float64(100*time.Millisecond))
+			}
+		})
+	}
+}
+
+func TestMiddleware_BackendTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	provider := authprovider.New(slog.Default(), mocks.New(false), configapplication.AuthTokensConfig{})
+	h := authhandler.New(slog.Default(), provider, provider)
+
+	testData := []struct {
+		name    string
+		conf    configapplication.ApiTimeoutConfig
+		headers map[string]string
+	}{
+		{
+			name: "Route timeout",
+			conf: configapplication.ApiTimeoutConfig{
+				Default: time.Minute,
+				Routes:  map[string]time.Duration{"POST /login": 50 * time.Millisecond},
+			},
+		},
+		{
+			name:    "Client timeout",
+			conf:    configapplication.ApiTimeoutConfig{Default: time.Minute, Max: time.Minute},
+			headers: map[string]string{HeaderName: "0.05"},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(New(data.conf).Middleware)
+			router.POST("/login", h.HandlerLogin)
+
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"slow","password":"pass"}`))
+			req.Header.Set("Content-Type", "application/json")
+			for name, value := range data.headers {
+				req.Header.Set(name, value)
+			}
+
+			started := time.Now()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Less(t, time.Since(started), time.Second)
+			require.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+			var problem httpdto.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			require.Equal(t, httpdto.ErrorCodeBackendTimeout, problem.Code)
+		})
+	}
+}
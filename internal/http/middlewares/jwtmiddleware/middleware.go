@@ -3,10 +3,14 @@ package jwtmiddleware
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"todoapiservice/internal/http/handlers"
 	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/lib/authcontext"
+	"todoapiservice/internal/services/authprovider"
 	"todoapiservice/internal/services/coredto"
 
 	"github.com/gin-gonic/gin"
@@ -31,29 +35,24 @@ func New(
 	}
 }
 
-func sendErrorStatus(c *gin.Context, code int) {
+func sendErrorStatus(c *gin.Context, status int, code httpdto.ErrorCode, detail string) {
 	c.Writer.Header().Set("WWW-Authenticate", "Bearer realm=Restricted")
-	c.IndentedJSON(
-		code,
-		httpdto.GeneralResponse{
-			Status: httpdto.StatusError,
-		})
-	c.Abort()
+	handlers.SendProblem(c, status, code, detail)
 }
 
 func (m *JWTMiddleware) Middleware(c *gin.Context) {
-	token := c.Request.Header.Get("Authorization")
+	header := c.Request.Header.Get("Authorization")
 
-	if token == "" {
-		sendErrorStatus(c, http.StatusUnauthorized)
+	if header == "" {
+		sendErrorStatus(c, http.StatusUnauthorized, httpdto.ErrorCodeUnauthorized, "bearer token is required")
 		return
 	}
 
-	parts := strings.Split(token, " ")
-	method, token := parts[0], parts[1]
+	method, token, _ := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
 
-	if strings.ToLower(method) != "bearer" {
-		sendErrorStatus(c, http.StatusBadRequest)
+	if !strings.EqualFold(method, "bearer") || token == "" {
+		sendErrorStatus(c, http.StatusBadRequest, httpdto.ErrorCodeInvalidAuthHeader, "expected 'Bearer <token>'")
 		return
 	}
 
@@ -62,12 +61,25 @@ func (m *JWTMiddleware) Middleware(c *gin.Context) {
 		token,
 	)
 
-	if err != nil || user.JWT == nil || user.UserID == nil {
-		sendErrorStatus(c, http.StatusUnauthorized)
+	if err != nil {
+		if errors.Is(err, authprovider.ErrPermissionDenied) {
+			sendErrorStatus(c, http.StatusUnauthorized, httpdto.ErrorCodeInvalidToken, "token is invalid or expired")
+			return
+		}
+		m.loggger.Error("check secret error", slog.Any("err", err))
+		handlers.SendServiceError(c, err)
+		return
+	}
+
+	if user.JWT == nil || user.UserID == nil {
+		sendErrorStatus(c, http.StatusUnauthorized, httpdto.ErrorCodeInvalidToken, "token is invalid or expired")
 		return
 	}
 
 	c.Set("userID", *user.UserID)
 	c.Set("jwtToken", *user.JWT)
+	c.Request = c.Request.WithContext(
+		authcontext.WithBearerToken(c.Request.Context(), *user.JWT),
+	)
 	c.Next()
 }
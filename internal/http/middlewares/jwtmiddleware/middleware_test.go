@@ -0,0 +1,103 @@
+package jwtmiddleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	// Registers problems of the authentication service errors
+	_ "todoapiservice/internal/http/handlers/authhandler"
+	"todoapiservice/internal/http/httpdto"
+	"todoapiservice/internal/services/authprovider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTMiddleware_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testData := []struct {
+		name    string
+		isDown  bool
+		header  string
+		code    int
+		errCode httpdto.ErrorCode
+	}{
+		{
+			name:   "Valid token",
+			header: "Bearer 1:user1",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "Lowercase scheme",
+			header: "bearer 1:user1",
+			code:   http.StatusOK,
+		},
+		{
+			name:    "No header",
+			header:  "",
+			code:    http.StatusUnauthorized,
+			errCode: httpdto.ErrorCodeUnauthorized,
+		},
+		{
+			name:    "Scheme only",
+			header:  "Bearer",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeInvalidAuthHeader,
+		},
+		{
+			name:    "Basic scheme",
+			header:  "Basic dXNlcjE6cGFzcw==",
+			code:    http.StatusBadRequest,
+			errCode: httpdto.ErrorCodeInvalidAuthHeader,
+		},
+		{
+			name:    "Invalid token",
+			header:  "Bearer 1:wronguser",
+			code:    http.StatusUnauthorized,
+			errCode: httpdto.ErrorCodeInvalidToken,
+		},
+		{
+			name:    "Backend unavailable",
+			isDown:  true,
+			header:  "Bearer 1:user1",
+			code:    http.StatusServiceUnavailable,
+			errCode: httpdto.ErrorCodeBackendUnavailable,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			checker := authprovider.New(slog.Default(), mocks.New(data.isDown), configapplication.AuthTokensConfig{})
+			m := New(slog.Default(), checker)
+
+			router := gin.New()
+			router.GET("/", m.Middleware, func(c *gin.Context) {
+				require.Equal(t, uint64(1), c.GetUint64("userID"))
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if data.header != "" {
+				req.Header.Set("Authorization", data.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, data.code, w.Code)
+			if data.code == http.StatusOK {
+				return
+			}
+
+			require.Equal(t, httpdto.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var resp httpdto.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Equal(t, data.errCode, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package metricsmiddleware implements HTTP traffic metrics middleware
+package metricsmiddleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IRecorder interface {
+	HTTPRequestStarted() func()
+	ObserveHTTPRequest(method string, route string, status int, duration time.Duration)
+}
+
+type MetricsMiddleware struct {
+	recorder IRecorder
+}
+
+func New(recorder IRecorder) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		recorder: recorder,
+	}
+}
+
+// Middleware Records request latency and status by route template, so path parameters do not create new series
+func (m *MetricsMiddleware) Middleware(c *gin.Context) {
+	done := m.recorder.HTTPRequestStarted()
+	defer done()
+
+	started := time.Now()
+	c.Next()
+
+	m.recorder.ObserveHTTPRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(started))
+}
@@ -0,0 +1,95 @@
+package metricsmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	method string
+	route  string
+	status int
+}
+
+type recorderStub struct {
+	mu           sync.Mutex
+	inFlight     int
+	maxInFlight  int
+	observations []observation
+}
+
+func (r *recorderStub) HTTPRequestStarted() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inFlight++
+	r.maxInFlight = max(r.maxInFlight, r.inFlight)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.inFlight--
+	}
+}
+
+func (r *recorderStub) ObserveHTTPRequest(method string, route string, status int, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observations = append(r.observations, observation{method: method, route: route, status: status})
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testData := []struct {
+		name     string
+		method   string
+		path     string
+		expected observation
+	}{
+		{
+			name:     "Route template",
+			method:   http.MethodGet,
+			path:     "/tasks/42",
+			expected: observation{method: http.MethodGet, route: "/tasks/:id", status: http.StatusOK},
+		},
+		{
+			name:     "Handler status",
+			method:   http.MethodDelete,
+			path:     "/tasks/42",
+			expected: observation{method: http.MethodDelete, route: "/tasks/:id", status: http.StatusNoContent},
+		},
+		{
+			name:     "Unmatched route",
+			method:   http.MethodGet,
+			path:     "/unknown/42",
+			expected: observation{method: http.MethodGet, route: "", status: http.StatusNotFound},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			recorder := &recorderStub{}
+
+			router := gin.New()
+			router.Use(New(recorder).Middleware)
+			router.GET("/tasks/:id", func(c *gin.Context) {
+				require.Equal(t, 1, recorder.inFlight)
+				c.Status(http.StatusOK)
+			})
+			router.DELETE("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(data.method, data.path, nil))
+
+			require.Equal(t, []observation{data.expected}, recorder.observations)
+			require.Equal(t, 0, recorder.inFlight)
+			require.Equal(t, 1, recorder.maxInFlight)
+		})
+	}
+}
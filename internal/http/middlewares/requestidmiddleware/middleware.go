@@ -0,0 +1,21 @@
+// Package requestidmiddleware implements request ID middleware
+package requestidmiddleware
+
+import (
+	"todoapiservice/internal/lib/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware Reuses valid client X-Request-ID or generates a new one,
+// stores it in request context and echoes it in response header
+func Middleware(c *gin.Context) {
+	id := c.GetHeader(requestid.HeaderName)
+	if !requestid.IsValid(id) {
+		id = requestid.New()
+	}
+
+	c.Header(requestid.HeaderName, id)
+	c.Request = c.Request.WithContext(requestid.WithRequestID(c.Request.Context(), id))
+	c.Next()
+}
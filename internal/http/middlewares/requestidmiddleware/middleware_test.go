@@ -0,0 +1,70 @@
+package requestidmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todoapiservice/internal/lib/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var seen string
+	router := gin.New()
+	router.Use(Middleware)
+	router.GET("/", func(c *gin.Context) {
+		seen = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	testData := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{
+			name:   "Client ID is reused",
+			header: "abc-123",
+			reused: true,
+		},
+		{
+			name:   "Missing ID is generated",
+			header: "",
+		},
+		{
+			name:   "Unsafe ID is replaced",
+			header: "abc\r\ndef",
+		},
+		{
+			name:   "Too long ID is replaced",
+			header: strings.Repeat("a", 129),
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if data.header != "" {
+				req.Header.Set(requestid.HeaderName, data.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(requestid.HeaderName)
+			require.NotEmpty(t, id)
+			require.Equal(t, id, seen)
+
+			if data.reused {
+				require.Equal(t, data.header, id)
+			} else {
+				require.NotEqual(t, data.header, id)
+				require.True(t, requestid.IsValid(id))
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Package authcontext implements request scoped authentication data passing
+package authcontext
+
+import "context"
+
+type bearerTokenKey struct{}
+
+// WithBearerToken Returns a copy of ctx carrying the caller bearer token
+func WithBearerToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, bearerTokenKey{}, token)
+}
+
+// BearerToken Returns the caller bearer token stored in ctx
+func BearerToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(bearerTokenKey{}).(string)
+	if !ok || token == "" {
+		return "", false
+	}
+	return token, true
+}
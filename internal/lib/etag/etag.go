@@ -0,0 +1,75 @@
+// Package etag implements HTTP entity tag formatting and conditional header parsing
+package etag
+
+import "strings"
+
+// Tag is a parsed entity tag
+type Tag struct {
+	Value string
+	Weak  bool
+}
+
+// List is a parsed If-Match or If-None-Match header value
+type List struct {
+	Any  bool
+	Tags []Tag
+}
+
+// Format Returns a strong entity tag for the value
+func Format(value string) string {
+	return `"` + value + `"`
+}
+
+// Parse Parses a comma separated entity tag list. Malformed tags are skipped
+func Parse(header string) List {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return List{Any: true}
+	}
+
+	var list List
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+
+		var tag Tag
+		if rest, ok := strings.CutPrefix(part, "W/"); ok {
+			tag.Weak = true
+			part = rest
+		}
+
+		if len(part) < 2 || part[0] != '"' || part[len(part)-1] != '"' {
+			continue
+		}
+		tag.Value = part[1 : len(part)-1]
+		if strings.ContainsRune(tag.Value, '"') {
+			continue
+		}
+
+		list.Tags = append(list.Tags, tag)
+	}
+	return list
+}
+
+// StrongValues Returns values of strong tags, the only ones If-Match can match
+func (l List) StrongValues() []string {
+	values := make([]string, 0, len(l.Tags))
+	for _, tag := range l.Tags {
+		if !tag.Weak {
+			values = append(values, tag.Value)
+		}
+	}
+	return values
+}
+
+// MatchWeak Reports whether the list matches the value using weak comparison, as If-None-Match does
+func (l List) MatchWeak(value string) bool {
+	if l.Any {
+		return true
+	}
+	for _, tag := range l.Tags {
+		if tag.Value == value {
+			return true
+		}
+	}
+	return false
+}
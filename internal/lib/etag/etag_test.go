@@ -0,0 +1,59 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testData := []struct {
+		name   string
+		header string
+		list   List
+	}{
+		{
+			name:   "Any",
+			header: " * ",
+			list:   List{Any: true},
+		},
+		{
+			name:   "Single strong",
+			header: `"abc"`,
+			list:   List{Tags: []Tag{{Value: "abc"}}},
+		},
+		{
+			name:   "Mixed list",
+			header: `"abc", W/"def" ,""`,
+			list:   List{Tags: []Tag{{Value: "abc"}, {Value: "def", Weak: true}, {Value: ""}}},
+		},
+		{
+			name:   "Malformed tags skipped",
+			header: `abc, "d"e", W/x, "ok"`,
+			list:   List{Tags: []Tag{{Value: "ok"}}},
+		},
+		{
+			name:   "Empty",
+			header: "",
+			list:   List{},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			require.Equal(t, data.list, Parse(data.header))
+		})
+	}
+}
+
+func TestList_Match(t *testing.T) {
+	list := Parse(`W/"weak", "strong"`)
+
+	require.Equal(t, []string{"strong"}, list.StrongValues())
+	require.True(t, list.MatchWeak("weak"))
+	require.True(t, list.MatchWeak("strong"))
+	require.False(t, list.MatchWeak("other"))
+
+	require.True(t, Parse("*").MatchWeak("other"))
+	require.Equal(t, `"v1"`, Format("v1"))
+}
@@ -0,0 +1,168 @@
+// Package metrics implements Prometheus metrics of HTTP and gRPC client traffic
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "todoapi"
+
+// UnmatchedRoute labels requests matching no route, so unknown paths do not create new series
+const UnmatchedRoute = "unmatched"
+
+// OtherMethod labels requests with non-standard methods, so arbitrary client methods do not create new series
+const OtherMethod = "other"
+
+var standardMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// AuthCacheStats is a token check cache counters snapshot
+type AuthCacheStats struct {
+	Hits         uint64
+	NegativeHits uint64
+	Misses       uint64
+	Evictions    uint64
+	Size         int
+}
+
+// Metrics holds gateway collectors in its own registry together with Go runtime and process collectors
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	grpcCalls    *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests being served.",
+		}),
+		grpcCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_client",
+			Name:      "calls_total",
+			Help:      "Backend gRPC calls by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_client",
+			Name:      "call_duration_seconds",
+			Help:      "Backend gRPC call latency by method and status code, retries included.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.grpcCalls,
+		m.grpcDuration,
+	)
+
+	return m
+}
+
+// RegisterAuthCache Exposes token check cache counters read from stats on every scrape
+func (m *Metrics) RegisterAuthCache(stats func() AuthCacheStats) {
+	counter := func(name string, help string, value func(AuthCacheStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return float64(value(stats()))
+		})
+	}
+
+	m.registry.MustRegister(
+		counter("hits_total", "Token checks answered by a cached valid token.",
+			func(s AuthCacheStats) uint64 { return s.Hits }),
+		counter("negative_hits_total", "Token checks answered by a cached rejected token.",
+			func(s AuthCacheStats) uint64 { return s.NegativeHits }),
+		counter("misses_total", "Token checks passed to the backend.",
+			func(s AuthCacheStats) uint64 { return s.Misses }),
+		counter("evictions_total", "Cache entries evicted to keep the size limit.",
+			func(s AuthCacheStats) uint64 { return s.Evictions }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "auth_cache",
+			Name:      "entries",
+			Help:      "Cached token check results.",
+		}, func() float64 {
+			return float64(stats().Size)
+		}),
+	)
+}
+
+// Handler Returns HTTP handler exposing metrics in Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// HTTPRequestStarted Counts in-flight request. Returned func must be called when the request is done
+func (m *Metrics) HTTPRequestStarted() func() {
+	m.httpInFlight.Inc()
+	return m.httpInFlight.Dec
+}
+
+// ObserveHTTPRequest Records served HTTP request
+func (m *Metrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	if _, ok := standardMethods[method]; !ok {
+		method = OtherMethod
+	}
+
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveGRPCCall Records finished backend call
+func (m *Metrics) ObserveGRPCCall(method string, code string, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "code": code}
+	m.grpcCalls.With(labels).Inc()
+	m.grpcDuration.With(labels).Observe(duration.Seconds())
+}
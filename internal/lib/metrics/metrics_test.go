@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	done := m.HTTPRequestStarted()
+	m.ObserveHTTPRequest(http.MethodGet, "/api/v1/tasks/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+	m.ObserveHTTPRequest("XYZZY", "", http.StatusNotFound, time.Millisecond)
+	m.ObserveGRPCCall("GetTaskByID", "Unavailable", 50*time.Millisecond)
+
+	body := scrape(t, m)
+
+	for _, line := range []string{
+		`todoapi_http_requests_total{method="GET",route="/api/v1/tasks/:id",status="200"} 1`,
+		`todoapi_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`todoapi_http_requests_total{method="other",route="unmatched",status="404"} 1`,
+		`todoapi_http_request_duration_seconds_count{method="GET",route="/api/v1/tasks/:id",status="200"} 1`,
+		`todoapi_http_requests_in_flight 1`,
+		`todoapi_grpc_client_calls_total{code="Unavailable",method="GetTaskByID"} 1`,
+		`todoapi_grpc_client_call_duration_seconds_count{code="Unavailable",method="GetTaskByID"} 1`,
+		`go_goroutines`,
+	} {
+		require.Contains(t, body, line)
+	}
+
+	done()
+	require.Contains(t, scrape(t, m), `todoapi_http_requests_in_flight 0`)
+}
+
+func TestMetrics_AuthCache(t *testing.T) {
+	m := New()
+
+	stats := AuthCacheStats{Hits: 3, NegativeHits: 1, Misses: 2, Size: 4}
+	m.RegisterAuthCache(func() AuthCacheStats {
+		return stats
+	})
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`todoapi_auth_cache_hits_total 3`,
+		`todoapi_auth_cache_negative_hits_total 1`,
+		`todoapi_auth_cache_misses_total 2`,
+		`todoapi_auth_cache_evictions_total 0`,
+		`todoapi_auth_cache_entries 4`,
+	} {
+		require.Contains(t, body, line)
+	}
+
+	// Counters are read on every scrape
+	stats.Hits = 5
+	require.Contains(t, scrape(t, m), `todoapi_auth_cache_hits_total 5`)
+}
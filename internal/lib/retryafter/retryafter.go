@@ -0,0 +1,47 @@
+// Package retryafter implements passing retry delay hints from gRPC errors to HTTP responses
+package retryafter
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// HeaderName is HTTP header carrying retry delay
+const HeaderName = "Retry-After"
+
+// Unavailable Returns codes.Unavailable error advising to retry after delay
+func Unavailable(message string, delay time.Duration) error {
+	st := status.New(codes.Unavailable, message)
+
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// FromError Returns retry delay carried by gRPC status in the err chain
+func FromError(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// HeaderValue Formats delay as Retry-After seconds, rounded up and at least 1
+func HeaderValue(delay time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(delay.Seconds())), 1))
+}
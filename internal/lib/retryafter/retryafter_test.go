@@ -0,0 +1,44 @@
+package retryafter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromError(t *testing.T) {
+	err := Unavailable("circuit breaker is open", 1500*time.Millisecond)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	testData := []struct {
+		name  string
+		err   error
+		delay time.Duration
+		ok    bool
+	}{
+		{name: "Status", err: err, delay: 1500 * time.Millisecond, ok: true},
+		{name: "Wrapped", err: fmt.Errorf("call: %w", err), delay: 1500 * time.Millisecond, ok: true},
+		{name: "Joined", err: errors.Join(errors.New("internal"), err), delay: 1500 * time.Millisecond, ok: true},
+		{name: "Without retry info", err: status.Error(codes.Unavailable, "down")},
+		{name: "Not a status", err: errors.New("down")},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			delay, ok := FromError(data.err)
+			require.Equal(t, data.ok, ok)
+			require.Equal(t, data.delay, delay)
+		})
+	}
+}
+
+func TestHeaderValue(t *testing.T) {
+	require.Equal(t, "2", HeaderValue(1500*time.Millisecond))
+	require.Equal(t, "1", HeaderValue(10*time.Millisecond))
+	require.Equal(t, "30", HeaderValue(30*time.Second))
+}
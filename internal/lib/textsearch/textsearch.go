@@ -0,0 +1,209 @@
+// Package textsearch implements tokenized, case-insensitive and diacritic-folding text matching
+package textsearch
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Term match weights
+const (
+	exactWeight     = 1.0
+	prefixWeight    = 0.75
+	substringWeight = 0.4
+	coverageWeight  = 0.1
+)
+
+// Fold Lowercases s and strips diacritics
+func Fold(s string) string {
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	folded, _, err := transform.String(folder, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// Token is a folded word with byte offsets of the original word
+type Token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// Tokenize Splits s into folded words of letters and digits
+func Tokenize(s string) []Token {
+	var tokens []Token
+
+	start := -1
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, Token{Text: Fold(s[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{Text: Fold(s[start:]), Start: start, End: len(s)})
+	}
+
+	return tokens
+}
+
+// Query is a parsed search query. Every term must match
+type Query struct {
+	terms []string
+}
+
+// ParseQuery Splits q into unique folded terms
+func ParseQuery(q string) Query {
+	var terms []string
+	for _, token := range Tokenize(q) {
+		if !slices.Contains(terms, token.Text) {
+			terms = append(terms, token.Text)
+		}
+	}
+	return Query{terms: terms}
+}
+
+// Empty Reports whether the query has no terms
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// Span is a matched byte range of the original text
+type Span struct {
+	Start int
+	End   int
+}
+
+// Match is a text match result
+type Match struct {
+	Score float64
+	Spans []Span
+}
+
+// Match Matches text against all query terms. Whole words rank above prefixes and substrings
+func (q Query) Match(text string) (Match, bool) {
+	if q.Empty() {
+		return Match{}, false
+	}
+
+	tokens := Tokenize(text)
+	matched := make([]bool, len(tokens))
+
+	var total float64
+	for _, term := range q.terms {
+		best := 0.0
+		for i, token := range tokens {
+			weight := termWeight(term, token.Text)
+			if weight > 0 {
+				matched[i] = true
+				best = max(best, weight)
+			}
+		}
+		if best == 0 {
+			return Match{}, false
+		}
+		total += best
+	}
+
+	var spans []Span
+	for i, token := range tokens {
+		if matched[i] {
+			spans = append(spans, Span{Start: token.Start, End: token.End})
+		}
+	}
+
+	// Short titles mostly made of the query rank above long ones
+	coverage := float64(len(spans)) / float64(len(tokens))
+
+	return Match{
+		Score: total/float64(len(q.terms)) + coverageWeight*coverage,
+		Spans: spans,
+	}, true
+}
+
+func termWeight(term string, word string) float64 {
+	switch {
+	case word == term:
+		return exactWeight
+	case strings.HasPrefix(word, term):
+		return prefixWeight
+	case strings.Contains(word, term):
+		return substringWeight
+	default:
+		return 0
+	}
+}
+
+// Highlight Returns HTML-escaped text cut to maxRunes around the first span,
+// with spans wrapped in <mark> tags. maxRunes <= 0 disables cutting
+func Highlight(text string, spans []Span, maxRunes int) string {
+	from, to := 0, len(text)
+
+	total := utf8.RuneCountInString(text)
+	if maxRunes > 0 && total > maxRunes {
+		first := 0
+		if len(spans) > 0 {
+			first = utf8.RuneCountInString(text[:spans[0].Start])
+		}
+
+		startRune := max(0, first-maxRunes/4)
+		endRune := min(total, startRune+maxRunes)
+		startRune = max(0, endRune-maxRunes)
+
+		from, to = runeOffset(text, startRune), runeOffset(text, endRune)
+	}
+
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+
+	pos := from
+	for _, span := range spans {
+		start, end := max(span.Start, from), min(span.End, to)
+		if start >= end || start < pos {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[pos:start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(text[start:end]))
+		sb.WriteString("</mark>")
+		pos = end
+	}
+	sb.WriteString(html.EscapeString(text[pos:to]))
+
+	if to < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
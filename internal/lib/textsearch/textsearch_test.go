@@ -0,0 +1,106 @@
+package textsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFold(t *testing.T) {
+	testData := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{name: "Case", in: "HeLLo", out: "hello"},
+		{name: "Precomposed diacritics", in: "Crème Brûlée", out: "creme brulee"},
+		{name: "Combining diacritics", in: "Café", out: "cafe"},
+		{name: "Cyrillic", in: "Ёлка", out: "елка"},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			require.Equal(t, data.out, Fold(data.in))
+		})
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	testData := []struct {
+		name  string
+		query string
+		text  string
+		match bool
+	}{
+		{name: "Exact word", query: "milk", text: "Buy milk", match: true},
+		{name: "Diacritic folding", query: "creme", text: "Make crème brûlée", match: true},
+		{name: "Folded query", query: "CRÈME", text: "creme", match: true},
+		{name: "Prefix", query: "rep", text: "Write report", match: true},
+		{name: "All terms required", query: "buy bread", text: "Buy milk", match: false},
+		{name: "Terms in any order", query: "milk buy", text: "Buy milk", match: true},
+		{name: "No match", query: "tea", text: "Buy milk", match: false},
+		{name: "Punctuation only query", query: "!!!", text: "Buy milk", match: false},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			_, ok := ParseQuery(data.query).Match(data.text)
+			require.Equal(t, data.match, ok)
+		})
+	}
+}
+
+func TestQuery_MatchRanking(t *testing.T) {
+	q := ParseQuery("milk")
+
+	exact, ok := q.Match("milk")
+	require.True(t, ok)
+	exactLong, ok := q.Match("buy milk and bread today")
+	require.True(t, ok)
+	prefix, ok := q.Match("milkshake")
+	require.True(t, ok)
+	substring, ok := q.Match("buttermilk")
+	require.True(t, ok)
+
+	require.Greater(t, exact.Score, exactLong.Score)
+	require.Greater(t, exactLong.Score, prefix.Score)
+	require.Greater(t, prefix.Score, substring.Score)
+}
+
+func TestHighlight(t *testing.T) {
+	testData := []struct {
+		name     string
+		query    string
+		text     string
+		maxRunes int
+		out      string
+	}{
+		{
+			name:  "Whole text",
+			query: "milk",
+			text:  "Buy milk",
+			out:   "Buy <mark>milk</mark>",
+		},
+		{
+			name:  "Original spelling is kept",
+			query: "creme",
+			text:  "Crème <b>brûlée</b>",
+			out:   "<mark>Crème</mark> &lt;b&gt;brûlée&lt;/b&gt;",
+		},
+		{
+			name:     "Cut around match",
+			query:    "milk",
+			text:     "one two three four five six seven milk eight nine ten",
+			maxRunes: 16,
+			out:      "…ven <mark>milk</mark> eight n…",
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			match, ok := ParseQuery(data.query).Match(data.text)
+			require.True(t, ok)
+			require.Equal(t, data.out, Highlight(data.text, match.Spans, data.maxRunes))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Package tlsreload implements TLS certificates loading with reload on file change
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLoadKeyPair = errors.New("failed to load TLS key pair")
+	ErrLoadCAFile  = errors.New("failed to load CA bundle")
+	ErrNoCACerts   = errors.New("no certificates found in CA bundle")
+)
+
+// fileWatch detects file changes by modification time and size
+type fileWatch struct {
+	files         []string
+	checkInterval time.Duration
+	lastCheck     time.Time
+	signature     string
+}
+
+func newFileWatch(checkInterval time.Duration, files ...string) *fileWatch {
+	return &fileWatch{
+		files:         files,
+		checkInterval: checkInterval,
+	}
+}
+
+func (w *fileWatch) currentSignature() (string, error) {
+	signature := ""
+	for _, file := range w.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		signature += fmt.Sprintf("%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+	return signature, nil
+}
+
+// changed Reports whether files were changed since the last call. Files are checked at most once per checkInterval
+func (w *fileWatch) changed(now time.Time) bool {
+	if now.Sub(w.lastCheck) < w.checkInterval {
+		return false
+	}
+	w.lastCheck = now
+
+	signature, err := w.currentSignature()
+	if err != nil || signature == w.signature {
+		return false
+	}
+	w.signature = signature
+	return true
+}
+
+// KeyPair is a certificate and private key reloaded when their files change
+type KeyPair struct {
+	logger   *slog.Logger
+	certFile string
+	keyFile  string
+
+	mu    sync.Mutex
+	watch *fileWatch
+	cert  *tls.Certificate
+}
+
+// NewKeyPair Returns loaded key pair. Files are checked for changes at most once per checkInterval
+func NewKeyPair(
+	logger *slog.Logger,
+	certFile string,
+	keyFile string,
+	checkInterval time.Duration,
+) (*KeyPair, error) {
+	kp := &KeyPair{
+		logger:   logger.With("module", "tlsreload", "cert", certFile),
+		certFile: certFile,
+		keyFile:  keyFile,
+		watch:    newFileWatch(checkInterval, certFile, keyFile),
+	}
+
+	kp.watch.changed(time.Now())
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Join(ErrLoadKeyPair, err)
+	}
+	kp.cert = &cert
+
+	return kp, nil
+}
+
+// Certificate Returns current certificate. A failed reload keeps the previous certificate
+func (kp *KeyPair) Certificate() *tls.Certificate {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+
+	if kp.watch.changed(time.Now()) {
+		cert, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+		if err != nil {
+			kp.logger.Error("certificate reload error", slog.Any("err", err))
+		} else {
+			kp.logger.Info("certificate reloaded")
+			kp.cert = &cert
+		}
+	}
+
+	return kp.cert
+}
+
+// GetCertificate is a tls.Config.GetCertificate callback
+func (kp *KeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// GetClientCertificate is a tls.Config.GetClientCertificate callback
+func (kp *KeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// CertPool is a CA bundle reloaded when its file changes
+type CertPool struct {
+	logger *slog.Logger
+	caFile string
+
+	mu    sync.Mutex
+	watch *fileWatch
+	pool  *x509.CertPool
+}
+
+// NewCertPool Returns loaded CA bundle. The file is checked for changes at most once per checkInterval
+func NewCertPool(
+	logger *slog.Logger,
+	caFile string,
+	checkInterval time.Duration,
+) (*CertPool, error) {
+	cp := &CertPool{
+		logger: logger.With("module", "tlsreload", "ca", caFile),
+		caFile: caFile,
+		watch:  newFileWatch(checkInterval, caFile),
+	}
+
+	cp.watch.changed(time.Now())
+	pool, err := loadCertPool(caFile)
+	if err != nil {
+		return nil, err
+	}
+	cp.pool = pool
+
+	return cp, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Join(ErrLoadCAFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, ErrNoCACerts
+	}
+	return pool, nil
+}
+
+// Pool Returns current CA pool. A failed reload keeps the previous pool
+func (cp *CertPool) Pool() *x509.CertPool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.watch.changed(time.Now()) {
+		pool, err := loadCertPool(cp.caFile)
+		if err != nil {
+			cp.logger.Error("CA bundle reload error", slog.Any("err", err))
+		} else {
+			cp.logger.Info("CA bundle reloaded")
+			cp.pool = pool
+		}
+	}
+
+	return cp.pool
+}
+
+// VerifyPeer Verifies peer certificate chain against the current pool.
+// When serverName is empty the host name is not checked (client certificates)
+func (cp *CertPool) VerifyPeer(
+	cs tls.ConnectionState,
+	serverName string,
+	usage x509.ExtKeyUsage,
+) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: peer did not provide a certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         cp.Pool(),
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	}
+
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,114 @@
+package tlsreload
+
+import (
+	"crypto/x509"
+	"log/slog"
+	"testing"
+	"todoapiservice/internal/lib/tlsreload/tlstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPair_ReloadOnChange(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "server")
+
+	kp, err := NewKeyPair(slog.Default(), files.CertFile, files.KeyFile, 0)
+	require.NoError(t, err)
+
+	first := kp.Certificate()
+	require.Same(t, first, kp.Certificate())
+
+	certPEM, keyPEM := ca.Issue(t, "server")
+	tlstest.WriteFile(t, files.CertFile, certPEM)
+	tlstest.WriteFile(t, files.KeyFile, keyPEM)
+
+	second := kp.Certificate()
+	require.NotEqual(t, first.Certificate[0], second.Certificate[0])
+}
+
+func TestKeyPair_KeepsPreviousOnInvalidFile(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "server")
+
+	kp, err := NewKeyPair(slog.Default(), files.CertFile, files.KeyFile, 0)
+	require.NoError(t, err)
+
+	first := kp.Certificate()
+
+	tlstest.WriteFile(t, files.CertFile, []byte("garbage"))
+
+	require.Same(t, first, kp.Certificate())
+}
+
+func TestKeyPair_CheckInterval(t *testing.T) {
+	ca := tlstest.NewCA(t, "test-ca")
+	files := ca.WriteFiles(t, t.TempDir(), "server")
+
+	kp, err := NewKeyPair(slog.Default(), files.CertFile, files.KeyFile, 1<<62)
+	require.NoError(t, err)
+
+	first := kp.Certificate()
+
+	certPEM, keyPEM := ca.Issue(t, "server")
+	tlstest.WriteFile(t, files.CertFile, certPEM)
+	tlstest.WriteFile(t, files.KeyFile, keyPEM)
+
+	require.Same(t, first, kp.Certificate())
+}
+
+func TestNewKeyPair_MissingFiles(t *testing.T) {
+	kp, err := NewKeyPair(slog.Default(), "/nonexistent/cert.pem", "/nonexistent/key.pem", 0)
+
+	require.ErrorIs(t, err, ErrLoadKeyPair)
+	require.Nil(t, kp)
+}
+
+func TestCertPool_ReloadOnChange(t *testing.T) {
+	firstCA := tlstest.NewCA(t, "first-ca")
+	secondCA := tlstest.NewCA(t, "second-ca")
+	files := firstCA.WriteFiles(t, t.TempDir(), "server")
+
+	cp, err := NewCertPool(slog.Default(), files.CAFile, 0)
+	require.NoError(t, err)
+
+	_, err = secondCA.Cert.Verify(x509.VerifyOptions{Roots: cp.Pool()})
+	require.Error(t, err)
+
+	tlstest.WriteFile(t, files.CAFile, secondCA.CertPEM)
+
+	_, err = secondCA.Cert.Verify(x509.VerifyOptions{Roots: cp.Pool()})
+	require.NoError(t, err)
+}
+
+func TestNewCertPool_Errors(t *testing.T) {
+	dir := t.TempDir()
+	emptyFile := dir + "/empty.pem"
+	tlstest.WriteFile(t, emptyFile, []byte("no certificates here"))
+
+	testData := []struct {
+		name string
+		file string
+		err  error
+	}{
+		{
+			name: "Missing file",
+			file: dir + "/missing.pem",
+			err:  ErrLoadCAFile,
+		},
+		{
+			name: "No certificates",
+			file: emptyFile,
+			err:  ErrNoCACerts,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			cp, err := NewCertPool(slog.Default(), data.file, 0)
+
+			require.ErrorIs(t, err, data.err)
+			require.Nil(t, cp)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package tlstest generates self-signed certificates for tests
+package tlstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// CA is a test certificate authority
+type CA struct {
+	Cert    *x509.Certificate
+	Key     *ecdsa.PrivateKey
+	CertPEM []byte
+}
+
+// Files is a set of PEM files written to disk
+type Files struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+var serial atomic.Int64
+
+func nextSerial() *big.Int {
+	return big.NewInt(time.Now().UnixNano() + serial.Add(1))
+}
+
+// NewCA Returns a new self-signed CA
+func NewCA(t testing.TB, name string) *CA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          nextSerial(),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &CA{
+		Cert:    cert,
+		Key:     key,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// Issue Returns PEM encoded certificate and key signed by the CA.
+// Certificate is valid for localhost and 127.0.0.1 for both server and client auth
+func (ca *CA) Issue(t testing.TB, commonName string) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+
+	return ca.IssueFor(t, commonName, "localhost", commonName, "127.0.0.1")
+}
+
+// IssueFor Returns PEM encoded certificate and key signed by the CA valid only for hosts: DNS names and IPs
+func (ca *CA) IssueFor(t testing.TB, commonName string, hosts ...string) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: nextSerial(),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, &key.PublicKey, ca.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// TLSCertificate Returns tls.Certificate signed by the CA
+func (ca *CA) TLSCertificate(t testing.TB, commonName string) tls.Certificate {
+	t.Helper()
+
+	certPEM, keyPEM := ca.Issue(t, commonName)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// Pool Returns cert pool containing the CA
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// WriteFiles Writes CA bundle and a certificate issued by the CA into dir
+func (ca *CA) WriteFiles(t testing.TB, dir string, commonName string) Files {
+	t.Helper()
+
+	certPEM, keyPEM := ca.Issue(t, commonName)
+	files := Files{
+		CAFile:   filepath.Join(dir, commonName+"-ca.pem"),
+		CertFile: filepath.Join(dir, commonName+".pem"),
+		KeyFile:  filepath.Join(dir, commonName+"-key.pem"),
+	}
+
+	WriteFile(t, files.CAFile, ca.CertPEM)
+	WriteFile(t, files.CertFile, certPEM)
+	WriteFile(t, files.KeyFile, keyPEM)
+
+	return files
+}
+
+// WriteFile Writes data into path and bumps its modification time so reload is detected
+func WriteFile(t testing.TB, path string, data []byte) {
+	t.Helper()
+
+	var prevModTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		prevModTime = info.ModTime()
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if !prevModTime.IsZero() {
+		modTime := prevModTime.Add(time.Second)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package authprovider
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/services/coredto"
+)
+
+// CacheStats is a CheckSecret cache counters snapshot
+type CacheStats struct {
+	Hits         uint64
+	NegativeHits uint64
+	Misses       uint64
+	Evictions    uint64
+	Size         int
+}
+
+type secretKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key     secretKey
+	userID  uint64
+	email   string
+	valid   bool
+	expires time.Time
+}
+
+// secretCache is a bounded LRU cache of CheckSecret results keyed by secret hash
+type secretCache struct {
+	size        int
+	ttl         time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[secretKey]*list.Element
+
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+	evictions    atomic.Uint64
+}
+
+func newSecretCache(size int, ttl time.Duration, negativeTTL time.Duration) *secretCache {
+	return &secretCache{
+		size:        size,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+		order:       list.New(),
+		entries:     make(map[secretKey]*list.Element, size),
+	}
+}
+
+func hashSecret(secret string) secretKey {
+	return sha256.Sum256([]byte(secret))
+}
+
+func (c *secretCache) get(key secretKey) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return cacheEntry{}, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.removeLocked(elem)
+		c.misses.Add(1)
+		return cacheEntry{}, false
+	}
+
+	c.order.MoveToFront(elem)
+	if entry.valid {
+		c.hits.Add(1)
+	} else {
+		c.negativeHits.Add(1)
+	}
+	return *entry, true
+}
+
+func (c *secretCache) put(entry cacheEntry) {
+	ttl := c.ttl
+	if !entry.valid {
+		ttl = c.negativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	entry.expires = c.now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = &entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(&entry)
+
+	for c.order.Len() > c.size {
+		c.removeLocked(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *secretCache) remove(key secretKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+}
+
+func (c *secretCache) removeLocked(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+func (c *secretCache) stats() CacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Evictions:    c.evictions.Load(),
+		Size:         size,
+	}
+}
+
+// CachedAuthProvider is AuthProvider caching CheckSecret results.
+// Only a hash of the secret is stored
+type CachedAuthProvider struct {
+	*AuthProvider
+	cache *secretCache
+}
+
+func NewCached(
+	provider *AuthProvider,
+	conf configapplication.AuthCacheConfig,
+) *CachedAuthProvider {
+	return &CachedAuthProvider{
+		AuthProvider: provider,
+		cache:        newSecretCache(conf.Size, conf.TTL, conf.NegativeTTL),
+	}
+}
+
+func (p *CachedAuthProvider) CheckSecret(ctx context.Context, secret string) (*coredto.User, error) {
+	key := hashSecret(secret)
+
+	if entry, ok := p.cache.get(key); ok {
+		if !entry.valid {
+			return nil, ErrPermissionDenied
+		}
+
+		return &coredto.User{
+			UserID: &entry.userID,
+			EMail:  &entry.email,
+			JWT:    &secret,
+		}, nil
+	}
+
+	user, err := p.AuthProvider.CheckSecret(ctx, secret)
+	if err != nil {
+		// Backend failures are not cached, only rejected secrets
+		if errors.Is(err, ErrPermissionDenied) && !errors.Is(err, ErrAuthInternal) {
+			p.cache.put(cacheEntry{key: key, valid: false})
+		}
+		return nil, err
+	}
+
+	entry := cacheEntry{key: key, valid: true}
+	if user.UserID != nil {
+		entry.userID = *user.UserID
+	}
+	if user.EMail != nil {
+		entry.email = *user.EMail
+	}
+	p.cache.put(entry)
+
+	return user, nil
+}
+
+// Logout Logs user out and evicts the token from cache immediately
+func (p *CachedAuthProvider) Logout(ctx context.Context, user coredto.User) error {
+	if user.JWT == nil {
+		return p.AuthProvider.Logout(ctx, user)
+	}
+
+	key := hashSecret(*user.JWT)
+	p.cache.remove(key)
+
+	err := p.AuthProvider.Logout(ctx, user)
+
+	// A concurrent CheckSecret may have cached the token while the logout was in progress
+	p.cache.remove(key)
+
+	return err
+}
+
+// Stats Returns cache counters
+func (p *CachedAuthProvider) Stats() CacheStats {
+	return p.cache.stats()
+}
@@ -0,0 +1,146 @@
+package authprovider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newCachedInstance(isDown bool, size int) (*CachedAuthProvider, *mocks.ToDoGrpcMock) {
+	pr := mocks.New(isDown)
+	instance := NewCached(
+		New(slog.Default(), pr, testTokensConfig),
+		configapplication.AuthCacheConfig{
+			Size:        size,
+			TTL:         time.Minute,
+			NegativeTTL: time.Second,
+		},
+	)
+	return instance, pr
+}
+
+func TestCachedAuthProvider_CheckSecret_Hit(t *testing.T) {
+	instance, pr := newCachedInstance(false, 10)
+	ctx := context.Background()
+
+	user, err := instance.CheckSecret(ctx, "1:user1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), *user.UserID)
+
+	// Backend result must not be requested again
+	pr.ResetCalls()
+
+	user, err = instance.CheckSecret(ctx, "1:user1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), *user.UserID)
+	require.Equal(t, "user1", *user.EMail)
+	require.Equal(t, "1:user1", *user.JWT)
+	require.Zero(t, pr.Calls("CheckSecret"))
+
+	stats := instance.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, 1, stats.Size)
+}
+
+func TestCachedAuthProvider_CheckSecret_Negative(t *testing.T) {
+	instance, pr := newCachedInstance(false, 10)
+	ctx := context.Background()
+
+	_, err := instance.CheckSecret(ctx, "1:wronguser")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	pr.ResetCalls()
+
+	user, err := instance.CheckSecret(ctx, "1:wronguser")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	require.Nil(t, user)
+	require.Zero(t, pr.Calls("CheckSecret"))
+	require.Equal(t, uint64(1), instance.Stats().NegativeHits)
+}
+
+func TestCachedAuthProvider_CheckSecret_NegativeTTL(t *testing.T) {
+	instance, pr := newCachedInstance(false, 10)
+	ctx := context.Background()
+
+	_, err := instance.CheckSecret(ctx, "1:wronguser")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	_, err = instance.CheckSecret(ctx, "1:user1")
+	require.NoError(t, err)
+
+	// Negative entry expires, valid entry is still alive
+	instance.cache.now = func() time.Time { return time.Now().Add(2 * time.Second) }
+	pr.ResetCalls()
+
+	_, err = instance.CheckSecret(ctx, "1:wronguser")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	require.Equal(t, 1, pr.Calls("CheckSecret"))
+
+	_, err = instance.CheckSecret(ctx, "1:user1")
+	require.NoError(t, err)
+	require.Equal(t, 1, pr.Calls("CheckSecret"))
+}
+
+func TestCachedAuthProvider_CheckSecret_ServerUnavailableNotCached(t *testing.T) {
+	instance, pr := newCachedInstance(true, 10)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := instance.CheckSecret(ctx, "1:user1")
+		require.ErrorIs(t, err, ErrAuthInternal)
+	}
+
+	require.Equal(t, 2, pr.Calls("CheckSecret"))
+	require.Zero(t, instance.Stats().Size)
+}
+
+func TestCachedAuthProvider_Logout_Evicts(t *testing.T) {
+	instance, _ := newCachedInstance(false, 10)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	_, err = instance.CheckSecret(ctx, *user.JWT)
+	require.NoError(t, err)
+
+	err = instance.Logout(ctx, *user)
+	require.NoError(t, err)
+
+	_, err = instance.CheckSecret(ctx, *user.JWT)
+	require.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestCachedAuthProvider_LRUBound(t *testing.T) {
+	cache := newSecretCache(2, time.Minute, time.Second)
+
+	for i := 0; i < 3; i++ {
+		cache.put(cacheEntry{key: hashSecret(fmt.Sprintf("token-%d", i)), valid: true, userID: uint64(i)})
+	}
+
+	_, ok := cache.get(hashSecret("token-0"))
+	require.False(t, ok)
+
+	entry, ok := cache.get(hashSecret("token-2"))
+	require.True(t, ok)
+	require.Equal(t, uint64(2), entry.userID)
+
+	// Reading token-1 makes token-2 the least recently used
+	_, ok = cache.get(hashSecret("token-1"))
+	require.True(t, ok)
+	cache.put(cacheEntry{key: hashSecret("token-3"), valid: true})
+
+	_, ok = cache.get(hashSecret("token-2"))
+	require.False(t, ok)
+
+	stats := cache.stats()
+	require.Equal(t, uint64(2), stats.Evictions)
+	require.Equal(t, 2, stats.Size)
+}
@@ -4,31 +4,78 @@ package authprovider
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"time"
+	"todoapiservice/internal/app/configapplication"
 	"todoapiservice/internal/services/coredto"
 
 	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 var (
 	ErrAuthInternal     = errors.New("authentication internal error")
-	ErrPermissionDenied = errors.New("authentication internal error")
+	ErrPermissionDenied = errors.New("permission denied")
+
+	ErrBadCredentials = fmt.Errorf("bad credentials: %w", ErrPermissionDenied)
+	ErrAccountLocked  = fmt.Errorf("account locked: %w", ErrPermissionDenied)
+
+	ErrBackendUnavailable = fmt.Errorf("authentication backend unavailable: %w", ErrAuthInternal)
+	ErrBackendTimeout     = fmt.Errorf("authentication backend timeout: %w", ErrAuthInternal)
 )
 
+// accountLockedReason is the ErrorInfo reason the backend attaches to login failures of locked accounts
+const accountLockedReason = "ACCOUNT_LOCKED"
+
+// isAccountLocked Reports whether err carries the locked account reason.
+// The status code alone is ambiguous: FailedPrecondition also means an invalid token
+func isAccountLocked(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return false
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == accountLockedReason {
+			return true
+		}
+	}
+	return false
+}
+
+// backendError Classifies backend call failure which is not an authentication result
+func backendError(err error) error {
+	switch {
+	case status.Code(err) == codes.Unavailable:
+		return errors.Join(ErrBackendUnavailable, err)
+	case status.Code(err) == codes.DeadlineExceeded, errors.Is(err, context.DeadlineExceeded):
+		return errors.Join(ErrBackendTimeout, err)
+	}
+	return errors.Join(ErrAuthInternal, err)
+}
+
 type AuthProvider struct {
-	logger *slog.Logger
-	client todoprotobufv1.ToDoServiceClient
+	logger        *slog.Logger
+	client        todoprotobufv1.ToDoServiceClient
+	tokensConf    configapplication.AuthTokensConfig
+	refreshCipher *refreshCipher
+	now           func() time.Time
 }
 
 func New(
 	logger *slog.Logger,
 	client todoprotobufv1.ToDoServiceClient,
+	tokensConf configapplication.AuthTokensConfig,
 ) *AuthProvider {
 	return &AuthProvider{
-		logger: logger.With("module", "authprovider"),
-		client: client,
+		logger:        logger.With("module", "authprovider"),
+		client:        client,
+		tokensConf:    tokensConf,
+		refreshCipher: newRefreshCipher(tokensConf.RefreshSecret),
+		now:           time.Now,
 	}
 }
 
@@ -40,12 +87,17 @@ func (p *AuthProvider) Login(ctx context.Context, email string, password string)
 	})
 
 	if err != nil {
-		if status.Code(err) == codes.PermissionDenied {
-			return nil, ErrPermissionDenied
+		if isAccountLocked(err) {
+			return nil, ErrAccountLocked
+		}
+
+		switch status.Code(err) {
+		case codes.PermissionDenied, codes.Unauthenticated:
+			return nil, ErrBadCredentials
 		}
 
 		log.Error("login error", slog.Any("err", err))
-		return nil, errors.Join(ErrAuthInternal, err)
+		return nil, backendError(err)
 	}
 
 	return &coredto.User{
@@ -54,15 +106,17 @@ func (p *AuthProvider) Login(ctx context.Context, email string, password string)
 
 }
 
+// Logout Ends the backend session. Refresh tokens of the session are rejected after it
 func (p *AuthProvider) Logout(ctx context.Context, user coredto.User) error {
 	log := p.logger.With("method", "Logout")
+
 	_, err := p.client.Logout(ctx, &todoprotobufv1.LogoutRequest{
 		Token: *user.JWT,
 	})
 
 	if err != nil {
 		log.Error("logout error", slog.Any("err", err))
-		return ErrAuthInternal
+		return backendError(err)
 	}
 
 	return nil
@@ -82,7 +136,7 @@ func (p *AuthProvider) CheckSecret(ctx context.Context, secret string) (*coredto
 			return nil, ErrPermissionDenied
 		}
 
-		return nil, errors.Join(ErrAuthInternal, err)
+		return nil, backendError(err)
 	}
 
 	userID := resp.GetUserId()
@@ -5,14 +5,22 @@ import (
 	"github.com/stretchr/testify/require"
 	"log/slog"
 	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
 	"todoapiservice/internal/app/grpcapplication/mocks"
 	"todoapiservice/internal/services/coredto"
 )
 
+var testTokensConfig = configapplication.AuthTokensConfig{
+	AccessTokenTTL:  time.Minute,
+	RefreshTokenTTL: time.Hour,
+	RefreshSecret:   "refresh-secret",
+}
+
 func TestAuthProvider_CheckSecret_ValidToken(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	user, err := instance.CheckSecret(ctx, "1:user1")
@@ -25,7 +33,7 @@ func TestAuthProvider_CheckSecret_ValidToken(t *testing.T) {
 func TestAuthProvider_CheckSecret_InvalidToken(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	testData := []struct {
@@ -59,7 +67,7 @@ func TestAuthProvider_CheckSecret_InvalidToken(t *testing.T) {
 func TestAuthProvider_CheckSecret_ServerUnavailable(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(true)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	user, err := instance.CheckSecret(ctx, "1:user1")
@@ -71,7 +79,7 @@ func TestAuthProvider_CheckSecret_ServerUnavailable(t *testing.T) {
 func TestAuthProvider_Login_Valid(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	user, err := instance.Login(ctx, "user1", "pass")
@@ -83,7 +91,7 @@ func TestAuthProvider_Login_Valid(t *testing.T) {
 func TestAuthProvider_Login_Invalid(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	testData := []struct {
@@ -126,7 +134,7 @@ func TestAuthProvider_Login_Invalid(t *testing.T) {
 func TestAuthProvider_Login_ServerUnavailable(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(true)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	user, err := instance.Login(ctx, "user1", "pass")
@@ -135,10 +143,79 @@ func TestAuthProvider_Login_ServerUnavailable(t *testing.T) {
 	require.Nil(t, user)
 }
 
+func TestAuthProvider_Login_ErrorTaxonomy(t *testing.T) {
+	testData := []struct {
+		name     string
+		isDown   bool
+		eMail    string
+		password string
+		timeout  time.Duration
+		err      error
+		notErr   error
+	}{
+		{
+			name:     "Bad credentials",
+			eMail:    "user1",
+			password: "invalid",
+			err:      ErrBadCredentials,
+			notErr:   ErrAuthInternal,
+		},
+		{
+			name:     "Locked account",
+			eMail:    "locked",
+			password: "pass",
+			err:      ErrAccountLocked,
+			notErr:   ErrBadCredentials,
+		},
+		{
+			name:     "Precondition without locked reason",
+			eMail:    "unverified",
+			password: "pass",
+			err:      ErrAuthInternal,
+			notErr:   ErrAccountLocked,
+		},
+		{
+			name:     "Backend unavailable",
+			isDown:   true,
+			eMail:    "user1",
+			password: "pass",
+			err:      ErrBackendUnavailable,
+			notErr:   ErrPermissionDenied,
+		},
+		{
+			name:     "Backend timeout",
+			eMail:    "slow",
+			password: "pass",
+			timeout:  50 * time.Millisecond,
+			err:      ErrBackendTimeout,
+			notErr:   ErrBackendUnavailable,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			instance := New(slog.Default(), mocks.New(data.isDown), testTokensConfig)
+
+			ctx := context.Background()
+			if data.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, data.timeout)
+				defer cancel()
+			}
+
+			user, err := instance.Login(ctx, data.eMail, data.password)
+
+			require.ErrorIs(t, err, data.err)
+			require.NotErrorIs(t, err, data.notErr)
+			require.Nil(t, user)
+		})
+	}
+}
+
 func TestAuthProvider_Logout_Valid(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	jwt := "1:user1"
@@ -150,7 +227,7 @@ func TestAuthProvider_Logout_Valid(t *testing.T) {
 func TestAuthProvider_Logout_Invalid(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	testData := []struct {
@@ -178,7 +255,7 @@ func TestAuthProvider_Logout_Invalid(t *testing.T) {
 func TestAuthProvider_Logout_ServerUnavailable(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(true)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	jwt := "1:user1"
@@ -190,7 +267,7 @@ func TestAuthProvider_Logout_ServerUnavailable(t *testing.T) {
 func TestAuthProvider_FullSequence(t *testing.T) {
 	logger := slog.Default()
 	pr := mocks.New(false)
-	instance := New(logger, pr)
+	instance := New(logger, pr, testTokensConfig)
 	ctx := context.Background()
 
 	//Login
@@ -0,0 +1,145 @@
+package authprovider
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+)
+
+// refreshClaims are sealed into the refresh token, so any gateway instance sharing the secret accepts it
+type refreshClaims struct {
+	// AccessToken is the backend token of the login
+	AccessToken   string `json:"tok"`
+	AccessExpires int64  `json:"aexp"`
+	Expires       int64  `json:"exp"`
+}
+
+// refreshCipher seals refresh claims with AES-GCM
+type refreshCipher struct {
+	aead cipher.AEAD
+}
+
+// newRefreshCipher Returns cipher keyed by secret. Instances sharing the secret accept each other refresh tokens
+func newRefreshCipher(secret string) *refreshCipher {
+	key := sha256.Sum256([]byte(secret))
+
+	// AES accepts a 32 byte key and GCM the AES block, so neither call fails
+	block, _ := aes.NewCipher(key[:])
+	aead, _ := cipher.NewGCM(block)
+	return &refreshCipher{aead: aead}
+}
+
+func (c *refreshCipher) seal(claims refreshClaims) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+func (c *refreshCipher) open(token string) (refreshClaims, bool) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return refreshClaims{}, false
+	}
+
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	data, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return refreshClaims{}, false
+	}
+
+	claims := refreshClaims{}
+	if err := json.Unmarshal(data, &claims); err != nil || claims.AccessToken == "" {
+		return refreshClaims{}, false
+	}
+	return claims, true
+}
+
+// accessTokenExpiry Returns the exp claim of a JWT access token or now + ttl for opaque tokens
+func accessTokenExpiry(token string, now time.Time, ttl time.Duration) time.Time {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err == nil {
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			return exp.Time
+		}
+	}
+	return now.Add(ttl)
+}
+
+// tokenPair Returns the backend token with a new refresh token of the same claims
+func (p *AuthProvider) tokenPair(claims refreshClaims) (*coredto.TokenPair, error) {
+	refreshToken, err := p.refreshCipher.seal(claims)
+	if err != nil {
+		p.logger.Error("refresh token sealing error", slog.Any("err", err))
+		return nil, errors.Join(ErrAuthInternal, err)
+	}
+
+	accessExpiresAt := time.Unix(claims.AccessExpires, 0)
+	refreshExpiresAt := time.Unix(claims.Expires, 0)
+
+	return &coredto.TokenPair{
+		AccessToken:      &claims.AccessToken,
+		AccessExpiresAt:  &accessExpiresAt,
+		RefreshToken:     &refreshToken,
+		RefreshExpiresAt: &refreshExpiresAt,
+	}, nil
+}
+
+// IssueTokens Returns access and refresh token pair for the logged-in user.
+// The backend has no token renewal call, so the refresh token expires with the backend token
+func (p *AuthProvider) IssueTokens(ctx context.Context, user coredto.User) (*coredto.TokenPair, error) {
+	now := p.now()
+	accessExpires := accessTokenExpiry(*user.JWT, now, p.tokensConf.AccessTokenTTL)
+
+	return p.tokenPair(refreshClaims{
+		AccessToken:   *user.JWT,
+		AccessExpires: accessExpires.Unix(),
+		Expires:       minTime(now.Add(p.tokensConf.RefreshTokenTTL), accessExpires).Unix(),
+	})
+}
+
+// Refresh Exchanges refresh token for a new token pair of the same backend token.
+// The backend must still accept the token, so logout and account lock end the refresh too
+func (p *AuthProvider) Refresh(ctx context.Context, refreshToken string) (*coredto.TokenPair, error) {
+	claims, ok := p.refreshCipher.open(refreshToken)
+	if !ok || !p.now().Before(time.Unix(claims.Expires, 0)) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	_, err := p.CheckSecret(ctx, claims.AccessToken)
+	if err != nil {
+		if errors.Is(err, ErrPermissionDenied) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	return p.tokenPair(claims)
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
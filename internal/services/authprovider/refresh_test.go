@@ -0,0 +1,176 @@
+package authprovider
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthProvider_IssueTokens(t *testing.T) {
+	instance := New(slog.Default(), mocks.New(false), testTokensConfig)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	tokens, err := instance.IssueTokens(ctx, *user)
+	require.NoError(t, err)
+
+	require.Equal(t, "1:user1", *tokens.AccessToken)
+	require.NotEmpty(t, *tokens.RefreshToken)
+	require.WithinDuration(t, time.Now().Add(time.Minute), *tokens.AccessExpiresAt, time.Second)
+	// The backend has no token renewal call: refresh cannot outlive the backend token
+	require.Equal(t, *tokens.AccessExpiresAt, *tokens.RefreshExpiresAt)
+}
+
+func TestAuthProvider_IssueTokens_JWTExpiry(t *testing.T) {
+	instance := New(slog.Default(), mocks.New(false), testTokensConfig)
+
+	exp := time.Now().Add(3 * time.Hour).Truncate(time.Second)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": exp.Unix()}).
+		SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	user, err := instance.Login(context.Background(), "user1", "pass")
+	require.NoError(t, err)
+	user.JWT = &token
+
+	tokens, err := instance.IssueTokens(context.Background(), *user)
+	require.NoError(t, err)
+	require.True(t, exp.Equal(*tokens.AccessExpiresAt))
+}
+
+func TestAuthProvider_Refresh(t *testing.T) {
+	pr := mocks.New(false)
+	instance := New(slog.Default(), pr, testTokensConfig)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	first, err := instance.IssueTokens(ctx, *user)
+	require.NoError(t, err)
+
+	second, err := instance.Refresh(ctx, *first.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, *first.AccessToken, *second.AccessToken)
+	require.NotEqual(t, *first.RefreshToken, *second.RefreshToken)
+	// The backend token expiry is not extended by refresh
+	require.Equal(t, *first.AccessExpiresAt, *second.AccessExpiresAt)
+	require.Equal(t, *first.RefreshExpiresAt, *second.RefreshExpiresAt)
+
+	// Another instance sharing the secret accepts the refresh token, as after a restart
+	other := New(slog.Default(), pr, testTokensConfig)
+	_, err = other.Refresh(ctx, *second.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestAuthProvider_Refresh_Invalid(t *testing.T) {
+	instance := New(slog.Default(), mocks.New(false), testTokensConfig)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	expired, err := instance.IssueTokens(ctx, *user)
+	require.NoError(t, err)
+	instance.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	otherSecret := testTokensConfig
+	otherSecret.RefreshSecret = "other-secret"
+	forged, err := New(slog.Default(), mocks.New(false), otherSecret).IssueTokens(ctx, *user)
+	require.NoError(t, err)
+
+	testData := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "Empty token",
+			token: "",
+		},
+		{
+			name:  "Unknown token",
+			token: "unknown",
+		},
+		{
+			name:  "Expired token",
+			token: *expired.RefreshToken,
+		},
+		{
+			name:  "Token of another secret",
+			token: *forged.RefreshToken,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			tokens, err := instance.Refresh(ctx, data.token)
+
+			require.ErrorIs(t, err, ErrRefreshTokenInvalid)
+			require.Nil(t, tokens)
+		})
+	}
+}
+
+func TestAuthProvider_Refresh_AfterLogout(t *testing.T) {
+	instance := New(slog.Default(), mocks.New(false), testTokensConfig)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	tokens, err := instance.IssueTokens(ctx, *user)
+	require.NoError(t, err)
+
+	err = instance.Logout(ctx, *user)
+	require.NoError(t, err)
+
+	_, err = instance.Refresh(ctx, *tokens.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshTokenInvalid)
+}
+
+func TestAuthProvider_Refresh_BackendSessionEnded(t *testing.T) {
+	pr := mocks.New(false)
+	instance := New(slog.Default(), pr, testTokensConfig)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	tokens, err := instance.IssueTokens(ctx, *user)
+	require.NoError(t, err)
+
+	// Logout through another gateway instance: backend token is revoked
+	other := New(slog.Default(), pr, testTokensConfig)
+	require.NoError(t, other.Logout(ctx, *user))
+
+	_, err = instance.Refresh(ctx, *tokens.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshTokenInvalid)
+}
+
+func TestAuthProvider_Refresh_BackendUnavailable(t *testing.T) {
+	pr := mocks.New(false)
+	instance := New(slog.Default(), pr, testTokensConfig)
+	ctx := context.Background()
+
+	user, err := instance.Login(ctx, "user1", "pass")
+	require.NoError(t, err)
+
+	tokens, err := instance.IssueTokens(ctx, *user)
+	require.NoError(t, err)
+
+	pr.SetDown(true)
+	_, err = instance.Refresh(ctx, *tokens.RefreshToken)
+	require.ErrorIs(t, err, ErrBackendUnavailable)
+
+	// The refresh token stays valid, so the client can retry
+	pr.SetDown(false)
+	_, err = instance.Refresh(ctx, *tokens.RefreshToken)
+	require.NoError(t, err)
+}
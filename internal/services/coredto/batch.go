@@ -0,0 +1,27 @@
+package coredto
+
+type ToDoOperationKind string
+
+const (
+	OperationCreate = ToDoOperationKind("create")
+	OperationUpdate = ToDoOperationKind("update")
+	OperationDelete = ToDoOperationKind("delete")
+)
+
+// ToDoOperation is a single batch operation. Item holds the task ID and changed fields
+type ToDoOperation struct {
+	Kind ToDoOperationKind
+	Item ToDoItem
+}
+
+// ToDoOperationResult is a batch operation outcome. Err is nil on success, Item is nil for delete
+type ToDoOperationResult struct {
+	Item       *ToDoItem
+	Err        error
+	RolledBack bool
+}
+
+type ToDoBatchResult struct {
+	Results    []ToDoOperationResult
+	RolledBack bool
+}
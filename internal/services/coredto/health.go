@@ -0,0 +1,18 @@
+package coredto
+
+import "time"
+
+// DependencyStatus is a readiness check result of one dependency
+type DependencyStatus struct {
+	Name   string
+	Ready  bool
+	Detail string
+}
+
+// Readiness is a service readiness report. Draining service is not ready regardless of dependencies
+type Readiness struct {
+	Ready        bool
+	Draining     bool
+	CheckedAt    time.Time
+	Dependencies []DependencyStatus
+}
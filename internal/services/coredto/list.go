@@ -0,0 +1,25 @@
+package coredto
+
+type ToDoSortField string
+
+const (
+	SortByID     = ToDoSortField("id")
+	SortByTitle  = ToDoSortField("title")
+	SortByIsDone = ToDoSortField("is_done")
+)
+
+// ToDoListQuery describes a page of owner tasks
+type ToDoListQuery struct {
+	Limit         int
+	Cursor        string
+	SortBy        ToDoSortField
+	Descending    bool
+	IsDone        *bool
+	TitleContains string
+}
+
+type ToDoListPage struct {
+	Items      []ToDoItem
+	NextCursor string
+	Total      int
+}
@@ -0,0 +1,16 @@
+package coredto
+
+type ToDoPatchFormat string
+
+const (
+	// PatchMerge is RFC 7396 JSON Merge Patch
+	PatchMerge = ToDoPatchFormat("merge-patch")
+	// PatchJSON is RFC 6902 JSON Patch
+	PatchJSON = ToDoPatchFormat("json-patch")
+)
+
+// ToDoPatch is a patch document applied to the item representation {"id", "title", "is_done"}
+type ToDoPatch struct {
+	Format   ToDoPatchFormat
+	Document []byte
+}
@@ -0,0 +1,19 @@
+package coredto
+
+// ToDoSearchQuery is a full-text search over owner task titles
+type ToDoSearchQuery struct {
+	Text  string
+	Limit int
+}
+
+// TextSpan is a matched byte range of the task title
+type TextSpan struct {
+	Start int
+	End   int
+}
+
+type ToDoSearchResult struct {
+	Item       ToDoItem
+	Score      float64
+	Highlights []TextSpan
+}
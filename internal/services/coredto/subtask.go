@@ -0,0 +1,24 @@
+package coredto
+
+// ToDoChecklistItem is a subtask checklist entry
+type ToDoChecklistItem struct {
+	Title  string
+	IsDone bool
+}
+
+// ToDoSubtask is a part of a task. On update nil fields are left unchanged
+// and non-nil Checklist replaces the whole list
+type ToDoSubtask struct {
+	SubtaskID *uint64
+	ParentID  *uint64
+	Owner     *User
+	Title     *string
+	IsDone    *bool
+	Checklist []ToDoChecklistItem
+}
+
+// ToDoProgress counts done subtasks of a task
+type ToDoProgress struct {
+	Done  int
+	Total int
+}
@@ -1,9 +1,58 @@
 // Package coredto contains Core DTO models
 package coredto
 
+import "time"
+
+type ToDoPriority string
+
+const (
+	PriorityLow    = ToDoPriority("low")
+	PriorityNormal = ToDoPriority("normal")
+	PriorityHigh   = ToDoPriority("high")
+)
+
+// Limits of task fields, checked for request bodies and patched values alike
+const (
+	MaxTitleLength       = 256
+	MaxDescriptionLength = 2000
+	MaxTags              = 20
+	MaxTagLength         = 32
+)
+
 type ToDoItem struct {
 	ItemID *uint64
 	Title  *string
 	IsDone *bool
 	Owner  *User
+	// Version identifies the item state. When set on Update or Delete
+	// the change is applied only if the stored item still has this version
+	Version *string
+
+	// Fields below are stored by the gateway until the backend supports them.
+	// On Update nil leaves the field unchanged, while empty Description,
+	// zero DueDate and empty non-nil Tags clear it
+	Description *string
+	DueDate     *time.Time
+	Priority    *ToDoPriority
+	Tags        []string
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+	// Progress is set for tasks having subtasks. It is read-only
+	Progress *ToDoProgress
+}
+
+// HasDetails Reports whether any gateway stored field is set
+func (i ToDoItem) HasDetails() bool {
+	return i.Description != nil || i.DueDate != nil || i.Priority != nil || i.Tags != nil
+}
+
+// ToDoItemDetails are item fields the backend can not store yet
+type ToDoItemDetails struct {
+	Description string
+	DueDate     *time.Time
+	Priority    ToDoPriority
+	Tags        []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Subtasks    []ToDoSubtask
 }
@@ -0,0 +1,129 @@
+// Package idempotency implements storage of responses to requests carrying Idempotency-Key
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+)
+
+var (
+	ErrKeyReused  = errors.New("idempotency key is reused with another request")
+	ErrInProgress = errors.New("request with the idempotency key is in progress")
+)
+
+// Response is a stored response replayed on retries
+type Response struct {
+	StatusCode int
+	Header     map[string]string
+	Body       []byte
+}
+
+type entry struct {
+	key         string
+	fingerprint string
+	// response is nil while the first request is in progress
+	response *Response
+	expires  time.Time
+}
+
+// MemoryStore is an in-memory bounded store of idempotent responses with TTL
+type MemoryStore struct {
+	ttl     time.Duration
+	maxKeys int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewMemoryStore(conf configapplication.IdempotencyConfig) *MemoryStore {
+	return &MemoryStore{
+		ttl:     conf.TTL,
+		maxKeys: conf.MaxKeys,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Begin Reserves the key for the request with the fingerprint.
+// Returns the stored response if the request was already completed
+func (s *MemoryStore) Begin(_ context.Context, key string, fingerprint string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.removeExpiredLocked(now)
+
+	if elem, ok := s.entries[key]; ok {
+		e := elem.Value.(*entry)
+		switch {
+		case e.fingerprint != fingerprint:
+			return nil, ErrKeyReused
+		case e.response == nil:
+			return nil, ErrInProgress
+		default:
+			return e.response, nil
+		}
+	}
+
+	s.entries[key] = s.order.PushFront(&entry{
+		key:         key,
+		fingerprint: fingerprint,
+		expires:     now.Add(s.ttl),
+	})
+
+	for s.order.Len() > s.maxKeys {
+		s.removeLocked(s.order.Back())
+	}
+	return nil, nil
+}
+
+// Complete Stores the response for the reserved key
+func (s *MemoryStore) Complete(_ context.Context, key string, response Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		// Evicted while in progress: the next retry is executed again
+		return nil
+	}
+
+	e := elem.Value.(*entry)
+	e.response = &response
+	e.expires = s.now().Add(s.ttl)
+	s.order.MoveToFront(elem)
+	return nil
+}
+
+// Release Frees the reserved key so the request can be retried
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.removeLocked(elem)
+	}
+	return nil
+}
+
+// removeExpiredLocked Removes expired entries. Entries are ordered by expiration time
+func (s *MemoryStore) removeExpiredLocked(now time.Time) {
+	for elem := s.order.Back(); elem != nil; elem = s.order.Back() {
+		if now.Before(elem.Value.(*entry).expires) {
+			return
+		}
+		s.removeLocked(elem)
+	}
+}
+
+func (s *MemoryStore) removeLocked(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*entry).key)
+}
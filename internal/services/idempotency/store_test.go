@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(maxKeys int) *MemoryStore {
+	return NewMemoryStore(configapplication.IdempotencyConfig{TTL: time.Minute, MaxKeys: maxKeys})
+}
+
+func TestMemoryStore_Replay(t *testing.T) {
+	store := newTestStore(10)
+	ctx := context.Background()
+
+	resp, err := store.Begin(ctx, "1:key", "fp")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	// Same key while the first request is running
+	_, err = store.Begin(ctx, "1:key", "fp")
+	require.ErrorIs(t, err, ErrInProgress)
+
+	stored := Response{StatusCode: 200, Header: map[string]string{"ETag": `"v1"`}, Body: []byte("{}")}
+	require.NoError(t, store.Complete(ctx, "1:key", stored))
+
+	resp, err = store.Begin(ctx, "1:key", "fp")
+	require.NoError(t, err)
+	require.Equal(t, stored, *resp)
+
+	_, err = store.Begin(ctx, "1:key", "other")
+	require.ErrorIs(t, err, ErrKeyReused)
+
+	// Keys are independent
+	resp, err = store.Begin(ctx, "2:key", "other")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestMemoryStore_Release(t *testing.T) {
+	store := newTestStore(10)
+	ctx := context.Background()
+
+	_, err := store.Begin(ctx, "1:key", "fp")
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, "1:key"))
+
+	// Failed request can be retried even with another body
+	resp, err := store.Begin(ctx, "1:key", "other")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestMemoryStore_TTL(t *testing.T) {
+	store := newTestStore(10)
+	ctx := context.Background()
+
+	_, err := store.Begin(ctx, "1:key", "fp")
+	require.NoError(t, err)
+	require.NoError(t, store.Complete(ctx, "1:key", Response{StatusCode: 200}))
+
+	store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+	resp, err := store.Begin(ctx, "1:key", "other")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestMemoryStore_MaxKeys(t *testing.T) {
+	store := newTestStore(2)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		key := fmt.Sprintf("1:key-%d", i)
+		_, err := store.Begin(ctx, key, "fp")
+		require.NoError(t, err)
+		require.NoError(t, store.Complete(ctx, key, Response{StatusCode: 200}))
+	}
+
+	// The oldest key is evicted
+	resp, err := store.Begin(ctx, "1:key-0", "other")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = store.Begin(ctx, "1:key-2", "fp")
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+}
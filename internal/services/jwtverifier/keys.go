@@ -0,0 +1,148 @@
+package jwtverifier
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrKeyFile       = errors.New("failed to load JWT verification key")
+	ErrJWKSFile      = errors.New("failed to load JWKS")
+	ErrUnsupportedPK = errors.New("unsupported public key type")
+)
+
+// verificationKey is a key with optional key ID from JWKS
+type verificationKey struct {
+	kid string
+	key jwt.VerificationKey
+}
+
+// loadPublicKeyFile Returns RSA or Ed25519 public key from PEM file
+func loadPublicKeyFile(path string) (jwt.VerificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Join(ErrKeyFile, err)
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.Join(ErrKeyFile, ErrUnsupportedPK)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// OKP
+	X string `json:"x"`
+	// oct
+	K string `json:"k"`
+}
+
+// loadJWKSFile Returns signature verification keys from JWKS file. Keys of unsupported types are skipped
+func loadJWKSFile(path string) ([]verificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Join(ErrJWKSFile, err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Join(ErrJWKSFile, err)
+	}
+
+	keys := make([]verificationKey, 0, len(set.Keys))
+	for _, item := range set.Keys {
+		if item.Use != "" && item.Use != "sig" {
+			continue
+		}
+
+		key, err := item.publicKey()
+		if errors.Is(err, ErrUnsupportedPK) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Join(ErrJWKSFile, fmt.Errorf("key %q: %w", item.Kid, err))
+		}
+
+		keys = append(keys, verificationKey{kid: item.Kid, key: key})
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (jwt.VerificationKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, ErrUnsupportedPK
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, err
+		}
+		return secret, nil
+	default:
+		return nil, ErrUnsupportedPK
+	}
+}
+
+// matchesMethod Reports whether key can verify signatures of the method
+func matchesMethod(key jwt.VerificationKey, method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		_, ok := key.([]byte)
+		return ok
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodEd25519:
+		_, ok := key.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,273 @@
+// Package jwtverifier implements local JWT verification without a backend round-trip
+package jwtverifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/services/authprovider"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	VerifyModeRemote        = "remote"
+	VerifyModeLocal         = "local"
+	VerifyModeLocalFallback = "local-fallback"
+)
+
+var (
+	ErrVerifierConfig    = errors.New("invalid JWT verifier configuration")
+	ErrUnknownVerifyMode = errors.New("unknown token verify mode")
+	ErrNoKeys            = errors.New("no JWT verification keys configured")
+	ErrNoMatchingKey     = errors.New("no verification key matches token")
+	ErrBadUserIDClaim    = errors.New("token user ID claim is missing or invalid")
+	ErrTokenRevoked      = errors.New("token is revoked")
+)
+
+type ISecretChecker interface {
+	CheckSecret(ctx context.Context, secret string) (*coredto.User, error)
+}
+
+type IAuthenticator interface {
+	Login(ctx context.Context, email string, password string) (*coredto.User, error)
+	Logout(ctx context.Context, user coredto.User) error
+}
+
+type Verifier struct {
+	logger      *slog.Logger
+	keys        []verificationKey
+	parser      *jwt.Parser
+	userIDClaim string
+	emailClaim  string
+	fallback    ISecretChecker
+	leeway      time.Duration
+	now         func() time.Time
+
+	// revoked maps logged out tokens to their expiry. The backend blacklist is not consulted
+	// for locally verified tokens, so logout is enforced here
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewSecretChecker Returns secret checker for the configured verify mode.
+// remote is the backend checker used in remote mode and as fallback
+func NewSecretChecker(
+	logger *slog.Logger,
+	conf configapplication.AuthConfig,
+	remote ISecretChecker,
+) (ISecretChecker, error) {
+	switch conf.VerifyMode {
+	case "", VerifyModeRemote:
+		return remote, nil
+	case VerifyModeLocal:
+		return New(logger, conf, nil)
+	case VerifyModeLocalFallback:
+		return New(logger, conf, remote)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownVerifyMode, conf.VerifyMode)
+	}
+}
+
+// New Returns local JWT verifier. If fallback is not nil it is called for tokens failed local verification
+func New(
+	logger *slog.Logger,
+	conf configapplication.AuthConfig,
+	fallback ISecretChecker,
+) (*Verifier, error) {
+	var keys []verificationKey
+
+	if conf.HMACSecret != "" {
+		keys = append(keys, verificationKey{key: []byte(conf.HMACSecret)})
+	}
+
+	if conf.PublicKeyFile != "" {
+		key, err := loadPublicKeyFile(conf.PublicKeyFile)
+		if err != nil {
+			return nil, errors.Join(ErrVerifierConfig, err)
+		}
+		keys = append(keys, verificationKey{key: key})
+	}
+
+	if conf.JWKSFile != "" {
+		jwks, err := loadJWKSFile(conf.JWKSFile)
+		if err != nil {
+			return nil, errors.Join(ErrVerifierConfig, err)
+		}
+		keys = append(keys, jwks...)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.Join(ErrVerifierConfig, ErrNoKeys)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(conf.Algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(conf.Leeway),
+		// Numeric claims are kept exact: float64 loses user IDs above 2^53
+		jwt.WithJSONNumber(),
+	}
+	if conf.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.Issuer))
+	}
+	if conf.Audience != "" {
+		opts = append(opts, jwt.WithAudience(conf.Audience))
+	}
+
+	return &Verifier{
+		logger:      logger.With("module", "jwtverifier"),
+		keys:        keys,
+		parser:      jwt.NewParser(opts...),
+		userIDClaim: conf.UserIDClaim,
+		emailClaim:  conf.EmailClaim,
+		fallback:    fallback,
+		leeway:      conf.Leeway,
+		now:         time.Now,
+		revoked:     make(map[string]time.Time),
+	}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var keySet jwt.VerificationKeySet
+	for _, item := range v.keys {
+		if kid != "" && item.kid != "" && item.kid != kid {
+			continue
+		}
+		if matchesMethod(item.key, token.Method) {
+			keySet.Keys = append(keySet.Keys, item.key)
+		}
+	}
+
+	if len(keySet.Keys) == 0 {
+		return nil, ErrNoMatchingKey
+	}
+	return keySet, nil
+}
+
+// verify Returns user from verified token claims
+func (v *Verifier) verify(secret string) (*coredto.User, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(secret, claims, v.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.isRevoked(secret) {
+		return nil, ErrTokenRevoked
+	}
+
+	userID, err := uint64Claim(claims[v.userIDClaim])
+	if err != nil {
+		return nil, err
+	}
+
+	user := &coredto.User{
+		UserID: &userID,
+		JWT:    &secret,
+	}
+
+	if email, ok := claims[v.emailClaim].(string); ok {
+		user.EMail = &email
+	}
+
+	return user, nil
+}
+
+func uint64Claim(value any) (uint64, error) {
+	switch v := value.(type) {
+	case string:
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, ErrBadUserIDClaim
+		}
+		return id, nil
+	case json.Number:
+		id, err := strconv.ParseUint(v.String(), 10, 64)
+		if err != nil {
+			return 0, ErrBadUserIDClaim
+		}
+		return id, nil
+	default:
+		return 0, ErrBadUserIDClaim
+	}
+}
+
+// CheckSecret Returns user of the locally verified token
+func (v *Verifier) CheckSecret(ctx context.Context, secret string) (*coredto.User, error) {
+	user, err := v.verify(secret)
+	if err == nil {
+		return user, nil
+	}
+
+	if v.fallback != nil && !errors.Is(err, ErrTokenRevoked) {
+		v.logger.Debug("local verification failed, fallback to backend", slog.Any("err", err))
+		return v.fallback.CheckSecret(ctx, secret)
+	}
+
+	return nil, errors.Join(authprovider.ErrPermissionDenied, err)
+}
+
+// Revoke Rejects the verified token until it expires
+func (v *Verifier) Revoke(secret string) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(secret, claims, v.keyFunc); err != nil {
+		// Tokens failing verification are rejected anyway
+		return
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	for token, expiry := range v.revoked {
+		if now.After(expiry) {
+			delete(v.revoked, token)
+		}
+	}
+	// Expired tokens are still accepted within the leeway
+	v.revoked[secret] = exp.Add(v.leeway)
+}
+
+func (v *Verifier) isRevoked(secret string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	_, ok := v.revoked[secret]
+	return ok
+}
+
+// RevokingAuthenticator revokes locally verified tokens on logout
+type RevokingAuthenticator struct {
+	IAuthenticator
+	verifier *Verifier
+}
+
+// NewRevokingAuthenticator Returns authenticator revoking the token in verifier before the backend logout
+func NewRevokingAuthenticator(authenticator IAuthenticator, verifier *Verifier) *RevokingAuthenticator {
+	return &RevokingAuthenticator{
+		IAuthenticator: authenticator,
+		verifier:       verifier,
+	}
+}
+
+func (a *RevokingAuthenticator) Logout(ctx context.Context, user coredto.User) error {
+	if user.JWT != nil {
+		a.verifier.Revoke(*user.JWT)
+	}
+	return a.IAuthenticator.Logout(ctx, user)
+}
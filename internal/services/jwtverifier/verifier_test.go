@@ -0,0 +1,362 @@
+package jwtverifier
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/services/authprovider"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-hmac-secret"
+
+func baseConfig() configapplication.AuthConfig {
+	return configapplication.AuthConfig{
+		Algorithms:  []string{"HS256", "RS256", "EdDSA"},
+		HMACSecret:  testSecret,
+		Issuer:      "todo-backend",
+		Audience:    "todo-gateway",
+		UserIDClaim: "sub",
+		EmailClaim:  "email",
+	}
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "42",
+		"email": "user42@example.com",
+		"iss":   "todo-backend",
+		"aud":   "todo-gateway",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func signHS256(t *testing.T, claims jwt.MapClaims, secret string) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifier_HS256Valid(t *testing.T) {
+	v, err := New(slog.Default(), baseConfig(), nil)
+	require.NoError(t, err)
+
+	user, err := v.CheckSecret(context.Background(), signHS256(t, validClaims(), testSecret))
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), *user.UserID)
+	require.Equal(t, "user42@example.com", *user.EMail)
+	require.NotNil(t, user.JWT)
+}
+
+func TestVerifier_NumericUserIDAbove2Pow53(t *testing.T) {
+	v, err := New(slog.Default(), baseConfig(), nil)
+	require.NoError(t, err)
+
+	claims := validClaims()
+	claims["sub"] = uint64(1<<53 + 1)
+
+	user, err := v.CheckSecret(context.Background(), signHS256(t, claims, testSecret))
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<53+1), *user.UserID)
+}
+
+func TestVerifier_RevokedOnLogout(t *testing.T) {
+	remote := authprovider.New(slog.Default(), mocks.New(false), configapplication.AuthTokensConfig{})
+	ctx := context.Background()
+
+	testData := []struct {
+		name     string
+		fallback ISecretChecker
+	}{
+		{name: "Local"},
+		{name: "Local with fallback", fallback: remote},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			v, err := New(slog.Default(), baseConfig(), data.fallback)
+			require.NoError(t, err)
+
+			token := signHS256(t, validClaims(), testSecret)
+			other := signHS256(t, jwt.MapClaims{"sub": "43", "exp": time.Now().Add(time.Hour).Unix(),
+				"iss": "todo-backend", "aud": "todo-gateway"}, testSecret)
+
+			// The backend does not know the token, the gateway revokes it anyway
+			err = NewRevokingAuthenticator(remote, v).Logout(ctx, coredto.User{JWT: &token})
+			require.Error(t, err)
+
+			_, err = v.CheckSecret(ctx, token)
+			require.ErrorIs(t, err, authprovider.ErrPermissionDenied)
+			require.ErrorIs(t, err, ErrTokenRevoked)
+
+			user, err := v.CheckSecret(ctx, other)
+			require.NoError(t, err)
+			require.Equal(t, uint64(43), *user.UserID)
+		})
+	}
+}
+
+func TestVerifier_InvalidClaims(t *testing.T) {
+	v, err := New(slog.Default(), baseConfig(), nil)
+	require.NoError(t, err)
+
+	testData := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+		secret string
+	}{
+		{
+			name:   "Expired",
+			modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		},
+		{
+			name:   "No expiration",
+			modify: func(claims jwt.MapClaims) { delete(claims, "exp") },
+		},
+		{
+			name:   "Not valid yet",
+			modify: func(claims jwt.MapClaims) { claims["nbf"] = time.Now().Add(time.Hour).Unix() },
+		},
+		{
+			name:   "Wrong issuer",
+			modify: func(claims jwt.MapClaims) { claims["iss"] = "someone-else" },
+		},
+		{
+			name:   "Wrong audience",
+			modify: func(claims jwt.MapClaims) { claims["aud"] = "other-service" },
+		},
+		{
+			name:   "Missing user ID",
+			modify: func(claims jwt.MapClaims) { delete(claims, "sub") },
+		},
+		{
+			name:   "Non numeric user ID",
+			modify: func(claims jwt.MapClaims) { claims["sub"] = "user42" },
+		},
+		{
+			name:   "Wrong signature",
+			modify: func(claims jwt.MapClaims) {},
+			secret: "other-secret",
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			claims := validClaims()
+			data.modify(claims)
+
+			secret := testSecret
+			if data.secret != "" {
+				secret = data.secret
+			}
+
+			user, err := v.CheckSecret(context.Background(), signHS256(t, claims, secret))
+
+			require.ErrorIs(t, err, authprovider.ErrPermissionDenied)
+			require.Nil(t, user)
+		})
+	}
+}
+
+func TestVerifier_RejectsDisallowedAlgorithm(t *testing.T) {
+	conf := baseConfig()
+	conf.Algorithms = []string{"RS256"}
+
+	v, err := New(slog.Default(), conf, nil)
+	require.NoError(t, err)
+
+	_, err = v.CheckSecret(context.Background(), signHS256(t, validClaims(), testSecret))
+	require.ErrorIs(t, err, authprovider.ErrPermissionDenied)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	_, err = v.CheckSecret(context.Background(), unsigned)
+	require.ErrorIs(t, err, authprovider.ErrPermissionDenied)
+}
+
+func TestVerifier_RS256PublicKeyFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	conf := baseConfig()
+	conf.HMACSecret = ""
+	conf.PublicKeyFile = path
+
+	v, err := New(slog.Default(), conf, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims()).SignedString(key)
+	require.NoError(t, err)
+
+	user, err := v.CheckSecret(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), *user.UserID)
+
+	// HS256 token signed with the public key bytes must not be accepted
+	confused, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString(der)
+	require.NoError(t, err)
+
+	_, err = v.CheckSecret(context.Background(), confused)
+	require.ErrorIs(t, err, authprovider.ErrPermissionDenied)
+}
+
+func TestVerifier_EdDSAJWKS(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks := map[string]any{
+		"keys": []map[string]string{
+			{"kty": "OKP", "crv": "Ed25519", "kid": "old", "x": base64.RawURLEncoding.EncodeToString(otherPub)},
+			{"kty": "OKP", "crv": "Ed25519", "kid": "current", "x": base64.RawURLEncoding.EncodeToString(pub)},
+			{"kty": "EC", "crv": "P-256", "kid": "unsupported"},
+		},
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	conf := baseConfig()
+	conf.HMACSecret = ""
+	conf.JWKSFile = path
+
+	v, err := New(slog.Default(), conf, nil)
+	require.NoError(t, err)
+
+	testData := []struct {
+		name    string
+		kid     string
+		success bool
+	}{
+		{
+			name:    "Matching kid",
+			kid:     "current",
+			success: true,
+		},
+		{
+			name:    "Without kid",
+			kid:     "",
+			success: true,
+		},
+		{
+			name:    "Other key kid",
+			kid:     "old",
+			success: false,
+		},
+		{
+			name:    "Unknown kid",
+			kid:     "missing",
+			success: false,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, validClaims())
+			if data.kid != "" {
+				token.Header["kid"] = data.kid
+			}
+			signed, err := token.SignedString(priv)
+			require.NoError(t, err)
+
+			user, err := v.CheckSecret(context.Background(), signed)
+			if data.success {
+				require.NoError(t, err)
+				require.Equal(t, uint64(42), *user.UserID)
+			} else {
+				require.ErrorIs(t, err, authprovider.ErrPermissionDenied)
+			}
+		})
+	}
+}
+
+func TestVerifier_Fallback(t *testing.T) {
+	remote := authprovider.New(slog.Default(), mocks.New(false), configapplication.AuthTokensConfig{})
+
+	v, err := New(slog.Default(), baseConfig(), remote)
+	require.NoError(t, err)
+
+	// Not a JWT: verified by backend
+	user, err := v.CheckSecret(context.Background(), "1:user1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), *user.UserID)
+
+	// Valid JWT: backend is not called
+	user, err = v.CheckSecret(context.Background(), signHS256(t, validClaims(), testSecret))
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), *user.UserID)
+}
+
+func TestNewSecretChecker(t *testing.T) {
+	remote := authprovider.New(slog.Default(), mocks.New(false), configapplication.AuthTokensConfig{})
+
+	testData := []struct {
+		name  string
+		mode  string
+		local bool
+		err   error
+	}{
+		{name: "Default", mode: "", local: false},
+		{name: "Remote", mode: VerifyModeRemote, local: false},
+		{name: "Local", mode: VerifyModeLocal, local: true},
+		{name: "Local with fallback", mode: VerifyModeLocalFallback, local: true},
+		{name: "Unknown", mode: "magic", err: ErrUnknownVerifyMode},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			conf := baseConfig()
+			conf.VerifyMode = data.mode
+
+			checker, err := NewSecretChecker(slog.Default(), conf, remote)
+			if data.err != nil {
+				require.ErrorIs(t, err, data.err)
+				return
+			}
+
+			require.NoError(t, err)
+			_, isLocal := checker.(*Verifier)
+			require.Equal(t, data.local, isLocal)
+		})
+	}
+}
+
+func TestNew_NoKeys(t *testing.T) {
+	conf := baseConfig()
+	conf.HMACSecret = ""
+
+	v, err := New(slog.Default(), conf, nil)
+
+	require.ErrorIs(t, err, ErrNoKeys)
+	require.Nil(t, v)
+}
@@ -0,0 +1,100 @@
+// Package readiness implements service readiness checks of backend dependencies
+package readiness
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/services/coredto"
+)
+
+// Dependency is a named readiness check. Check returns a short state description
+type Dependency struct {
+	Name  string
+	Check func(ctx context.Context) (string, error)
+}
+
+// Checker runs dependency checks and caches the report for CacheTTL
+type Checker struct {
+	logger       *slog.Logger
+	dependencies []Dependency
+	cacheTTL     time.Duration
+	probeTimeout time.Duration
+	now          func() time.Time
+
+	draining atomic.Bool
+
+	mu     sync.Mutex
+	cached *coredto.Readiness
+}
+
+func New(
+	logger *slog.Logger,
+	conf configapplication.HealthConfig,
+	dependencies ...Dependency,
+) *Checker {
+	return &Checker{
+		logger:       logger.With("module", "readiness"),
+		dependencies: dependencies,
+		cacheTTL:     conf.CacheTTL,
+		probeTimeout: conf.ProbeTimeout,
+		now:          time.Now,
+	}
+}
+
+// SetDraining Makes the service not ready for good, so load balancers stop sending requests before shutdown
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Check Returns the cached report or checks all dependencies.
+// Concurrent callers wait for a single run of checks
+func (c *Checker) Check(_ context.Context) coredto.Readiness {
+	if c.draining.Load() {
+		return coredto.Readiness{Draining: true, CheckedAt: c.now().UTC()}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && c.now().Before(c.cached.CheckedAt.Add(c.cacheTTL)) {
+		return *c.cached
+	}
+
+	// Checks are not bound to the caller, the result is shared with other callers
+	ctx := context.Background()
+	if c.probeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.probeTimeout)
+		defer cancel()
+	}
+
+	report := coredto.Readiness{
+		Ready:        true,
+		Dependencies: make([]coredto.DependencyStatus, 0, len(c.dependencies)),
+	}
+
+	for _, dependency := range c.dependencies {
+		detail, err := dependency.Check(ctx)
+		if err != nil {
+			c.logger.Warn("dependency is not ready", slog.String("dependency", dependency.Name), slog.Any("err", err))
+			report.Ready = false
+			if detail == "" {
+				detail = err.Error()
+			}
+		}
+
+		report.Dependencies = append(report.Dependencies, coredto.DependencyStatus{
+			Name:   dependency.Name,
+			Ready:  err == nil,
+			Detail: detail,
+		})
+	}
+
+	report.CheckedAt = c.now().UTC()
+	c.cached = &report
+	return report
+}
@@ -0,0 +1,81 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDependency struct {
+	calls  int
+	detail string
+	err    error
+}
+
+func (d *fakeDependency) Check(context.Context) (string, error) {
+	d.calls++
+	return d.detail, d.err
+}
+
+func TestChecker(t *testing.T) {
+	conn := &fakeDependency{detail: "READY"}
+	backend := &fakeDependency{detail: "SERVING"}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	checker := New(
+		slog.Default(),
+		configapplication.HealthConfig{CacheTTL: 2 * time.Second, ProbeTimeout: time.Second},
+		Dependency{Name: "grpc_connection", Check: conn.Check},
+		Dependency{Name: "todo_backend", Check: backend.Check},
+	)
+	checker.now = func() time.Time { return now }
+
+	report := checker.Check(context.Background())
+	require.Equal(t, coredto.Readiness{
+		Ready:     true,
+		CheckedAt: now,
+		Dependencies: []coredto.DependencyStatus{
+			{Name: "grpc_connection", Ready: true, Detail: "READY"},
+			{Name: "todo_backend", Ready: true, Detail: "SERVING"},
+		},
+	}, report)
+
+	// Cached report is returned until TTL passes
+	backend.detail, backend.err = "", errors.New("connection refused")
+	now = now.Add(time.Second)
+	require.True(t, checker.Check(context.Background()).Ready)
+	require.Equal(t, 1, backend.calls)
+
+	now = now.Add(time.Second)
+	report = checker.Check(context.Background())
+	require.False(t, report.Ready)
+	require.Equal(t, 2, backend.calls)
+	require.Equal(t, coredto.DependencyStatus{Name: "todo_backend", Detail: "connection refused"}, report.Dependencies[1])
+	require.True(t, report.Dependencies[0].Ready)
+
+	// Draining is reported at once without checks
+	backend.err = nil
+	checker.SetDraining()
+	now = now.Add(time.Minute)
+
+	report = checker.Check(context.Background())
+	require.False(t, report.Ready)
+	require.True(t, report.Draining)
+	require.Equal(t, 2, backend.calls)
+}
+
+func TestChecker_NoCache(t *testing.T) {
+	dependency := &fakeDependency{detail: "READY"}
+	checker := New(slog.Default(), configapplication.HealthConfig{}, Dependency{Name: "dep", Check: dependency.Check})
+
+	for range 3 {
+		require.True(t, checker.Check(context.Background()).Ready)
+	}
+	require.Equal(t, 3, dependency.calls)
+}
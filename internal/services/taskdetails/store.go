@@ -0,0 +1,92 @@
+// Package taskdetails implements the gateway side store of task fields the backend can not carry yet
+package taskdetails
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"todoapiservice/internal/services/coredto"
+)
+
+type detailsKey struct {
+	userID uint64
+	itemID uint64
+}
+
+// MemoryStore keeps task details in memory. Details are lost on restart
+// and are not shared between gateway instances
+type MemoryStore struct {
+	mu      sync.RWMutex
+	details map[detailsKey]coredto.ToDoItemDetails
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		details: make(map[detailsKey]coredto.ToDoItemDetails),
+	}
+}
+
+// Get Returns stored details of user items. Items without details are absent in the result
+func (s *MemoryStore) Get(_ context.Context, userID uint64, itemIDs []uint64) (map[uint64]coredto.ToDoItemDetails, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[uint64]coredto.ToDoItemDetails, len(itemIDs))
+	for _, itemID := range itemIDs {
+		if details, ok := s.details[detailsKey{userID: userID, itemID: itemID}]; ok {
+			result[itemID] = cloneDetails(details)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, userID uint64, itemID uint64, details coredto.ToDoItemDetails) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.details[detailsKey{userID: userID, itemID: itemID}] = cloneDetails(details)
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, userID uint64, itemID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.details, detailsKey{userID: userID, itemID: itemID})
+	return nil
+}
+
+func cloneDetails(details coredto.ToDoItemDetails) coredto.ToDoItemDetails {
+	details.Tags = slices.Clone(details.Tags)
+	if details.DueDate != nil {
+		dueDate := *details.DueDate
+		details.DueDate = &dueDate
+	}
+
+	if details.Subtasks != nil {
+		subtasks := make([]coredto.ToDoSubtask, len(details.Subtasks))
+		for i, subtask := range details.Subtasks {
+			subtasks[i] = cloneSubtask(subtask)
+		}
+		details.Subtasks = subtasks
+	}
+	return details
+}
+
+func cloneSubtask(subtask coredto.ToDoSubtask) coredto.ToDoSubtask {
+	return coredto.ToDoSubtask{
+		SubtaskID: clonePtr(subtask.SubtaskID),
+		ParentID:  clonePtr(subtask.ParentID),
+		Title:     clonePtr(subtask.Title),
+		IsDone:    clonePtr(subtask.IsDone),
+		Checklist: slices.Clone(subtask.Checklist),
+	}
+}
+
+func clonePtr[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
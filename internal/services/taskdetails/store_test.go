@@ -0,0 +1,51 @@
+package taskdetails
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	dueDate := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	details := coredto.ToDoItemDetails{
+		Description: "Oat milk",
+		DueDate:     &dueDate,
+		Priority:    coredto.PriorityHigh,
+		Tags:        []string{"shop"},
+	}
+
+	require.NoError(t, store.Put(ctx, 1, 10, details))
+	require.NoError(t, store.Put(ctx, 2, 10, coredto.ToDoItemDetails{Description: "Other user"}))
+
+	// Stored value is not shared with the caller
+	details.Tags[0] = "changed"
+	*details.DueDate = time.Time{}
+
+	got, err := store.Get(ctx, 1, []uint64{10, 11})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "Oat milk", got[10].Description)
+	require.Equal(t, []string{"shop"}, got[10].Tags)
+	require.Equal(t, time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC), *got[10].DueDate)
+
+	got[10].Tags[0] = "changed"
+	again, err := store.Get(ctx, 1, []uint64{10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"shop"}, again[10].Tags)
+
+	require.NoError(t, store.Delete(ctx, 1, 10))
+	got, err = store.Get(ctx, 1, []uint64{10})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	got, err = store.Get(ctx, 2, []uint64{10})
+	require.NoError(t, err)
+	require.Equal(t, "Other user", got[10].Description)
+}
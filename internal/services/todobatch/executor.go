@@ -0,0 +1,361 @@
+// Package todobatch implements bulk ToDo items operations
+package todobatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/services/coredto"
+)
+
+var (
+	ErrBatchTooLarge       = errors.New("too many batch operations")
+	ErrInvalidOperation    = errors.New("invalid batch operation")
+	ErrBatchAborted        = errors.New("batch aborted by failed operation")
+	ErrOperationRolledBack = errors.New("operation rolled back")
+)
+
+type IToDoCreator interface {
+	Create(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error)
+}
+
+type IToDoGetter interface {
+	GetByID(ctx context.Context, owner coredto.User, itemID uint64) (*coredto.ToDoItem, error)
+}
+
+type IToDoUpdater interface {
+	Update(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error)
+}
+
+type IToDoDeleter interface {
+	Delete(ctx context.Context, item coredto.ToDoItem) error
+}
+
+// IToDoDetailsKeeper snapshots gateway stored details, so a deleted task is restored with its subtasks
+type IToDoDetailsKeeper interface {
+	GetDetails(ctx context.Context, owner coredto.User, itemID uint64) (*coredto.ToDoItemDetails, error)
+	RestoreDetails(ctx context.Context, owner coredto.User, itemID uint64, details coredto.ToDoItemDetails) error
+}
+
+// undoFunc Reverts an applied operation
+type undoFunc func(ctx context.Context) error
+
+type Executor struct {
+	logger        *slog.Logger
+	creator       IToDoCreator
+	getter        IToDoGetter
+	updater       IToDoUpdater
+	deleter       IToDoDeleter
+	details       IToDoDetailsKeeper
+	maxOperations int
+	concurrency   int
+}
+
+func New(
+	logger *slog.Logger,
+	creator IToDoCreator,
+	getter IToDoGetter,
+	updater IToDoUpdater,
+	deleter IToDoDeleter,
+	details IToDoDetailsKeeper,
+	conf configapplication.TasksConfig,
+) *Executor {
+	return &Executor{
+		logger:        logger.With("module", "todobatch"),
+		creator:       creator,
+		getter:        getter,
+		updater:       updater,
+		deleter:       deleter,
+		details:       details,
+		maxOperations: conf.BatchMaxOperations,
+		concurrency:   max(conf.BatchConcurrency, 1),
+	}
+}
+
+func invalidOperation(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOperation, fmt.Sprintf(format, args...))
+}
+
+func validateOperation(op coredto.ToDoOperation) error {
+	switch op.Kind {
+	case coredto.OperationCreate:
+		if op.Item.ItemID != nil {
+			return invalidOperation("create does not accept id")
+		}
+		if op.Item.Title == nil {
+			return invalidOperation("create requires title")
+		}
+	case coredto.OperationUpdate:
+		if op.Item.ItemID == nil {
+			return invalidOperation("update requires id")
+		}
+		if op.Item.Title == nil && op.Item.IsDone == nil && !op.Item.HasDetails() {
+			return invalidOperation("update requires at least one field")
+		}
+	case coredto.OperationDelete:
+		if op.Item.ItemID == nil {
+			return invalidOperation("delete requires id")
+		}
+	default:
+		return invalidOperation("unknown operation %q", op.Kind)
+	}
+	return nil
+}
+
+// validate Checks every operation. Operations on the same task are rejected
+// because parallel execution order is undefined
+func validate(ops []coredto.ToDoOperation, results []coredto.ToDoOperationResult) bool {
+	valid := true
+	seen := make(map[uint64]int, len(ops))
+
+	for i, op := range ops {
+		err := validateOperation(op)
+		if err == nil && op.Item.ItemID != nil {
+			if first, ok := seen[*op.Item.ItemID]; ok {
+				err = invalidOperation("task %d is already changed by operation %d", *op.Item.ItemID, first)
+			} else {
+				seen[*op.Item.ItemID] = i
+			}
+		}
+
+		if err != nil {
+			results[i].Err = err
+			valid = false
+		}
+	}
+	return valid
+}
+
+// Execute Runs operations in parallel. In atomic mode a failure stops launching new operations
+// and reverts the applied ones: updates are restored, created tasks are deleted and
+// deleted tasks are created again under new IDs with their details and subtasks
+func (e *Executor) Execute(
+	ctx context.Context,
+	owner coredto.User,
+	ops []coredto.ToDoOperation,
+	atomicMode bool,
+) (*coredto.ToDoBatchResult, error) {
+	if len(ops) > e.maxOperations {
+		return nil, fmt.Errorf("%w: %d > %d", ErrBatchTooLarge, len(ops), e.maxOperations)
+	}
+
+	results := make([]coredto.ToDoOperationResult, len(ops))
+
+	if !validate(ops, results) && atomicMode {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrBatchAborted
+			}
+		}
+		return &coredto.ToDoBatchResult{Results: results}, nil
+	}
+
+	undo := make([]undoFunc, len(ops))
+
+	var (
+		failed atomic.Bool
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, e.concurrency)
+	)
+
+	for i, op := range ops {
+		if results[i].Err != nil {
+			continue
+		}
+
+		sem <- struct{}{}
+		if atomicMode && failed.Load() {
+			<-sem
+			results[i].Err = ErrBatchAborted
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			item, revert, err := e.apply(ctx, owner, op, atomicMode)
+			results[i] = coredto.ToDoOperationResult{Item: item, Err: err}
+			undo[i] = revert
+
+			if err != nil {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !atomicMode || !failed.Load() {
+		return &coredto.ToDoBatchResult{Results: results}, nil
+	}
+
+	return &coredto.ToDoBatchResult{
+		Results:    results,
+		RolledBack: e.compensate(ctx, results, undo),
+	}, nil
+}
+
+// compensate Reverts applied operations in reverse order. Returns false if any revert failed
+func (e *Executor) compensate(ctx context.Context, results []coredto.ToDoOperationResult, undo []undoFunc) bool {
+	log := e.logger.With("method", "compensate")
+
+	// The client may be gone, but applied changes must still be reverted
+	ctx = context.WithoutCancel(ctx)
+
+	complete := true
+	for i := len(results) - 1; i >= 0; i-- {
+		// Failed operations keep their error, but are reverted too if they left an undo
+		if undo[i] == nil {
+			continue
+		}
+
+		if err := undo[i](ctx); err != nil {
+			log.Error("operation revert error", slog.Int("operation", i), slog.Any("err", err))
+			complete = false
+			continue
+		}
+
+		if results[i].Err == nil {
+			results[i] = coredto.ToDoOperationResult{Err: ErrOperationRolledBack, RolledBack: true}
+		}
+	}
+	return complete
+}
+
+func (e *Executor) apply(
+	ctx context.Context,
+	owner coredto.User,
+	op coredto.ToDoOperation,
+	needUndo bool,
+) (*coredto.ToDoItem, undoFunc, error) {
+	switch op.Kind {
+	case coredto.OperationCreate:
+		return e.create(ctx, owner, op.Item)
+	case coredto.OperationUpdate:
+		return e.update(ctx, owner, op.Item, needUndo)
+	default:
+		return e.delete(ctx, owner, *op.Item.ItemID, needUndo)
+	}
+}
+
+// create Creates the task and applies the fields the backend does not accept on creation
+func (e *Executor) create(
+	ctx context.Context,
+	owner coredto.User,
+	fields coredto.ToDoItem,
+) (*coredto.ToDoItem, undoFunc, error) {
+	fields.Owner = &owner
+
+	item, err := e.creator.Create(ctx, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	undo := func(ctx context.Context) error {
+		return e.deleter.Delete(ctx, coredto.ToDoItem{ItemID: item.ItemID, Owner: &owner})
+	}
+
+	return item, undo, nil
+}
+
+func (e *Executor) update(
+	ctx context.Context,
+	owner coredto.User,
+	changes coredto.ToDoItem,
+	needUndo bool,
+) (*coredto.ToDoItem, undoFunc, error) {
+	changes.Owner = &owner
+
+	if !needUndo {
+		updated, err := e.updater.Update(ctx, changes)
+		return updated, nil, err
+	}
+
+	prev, err := e.getter.GetByID(ctx, owner, *changes.ItemID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	undo := func(ctx context.Context) error {
+		_, err := e.updater.Update(ctx, restored(*prev, owner))
+		return err
+	}
+
+	// The change may be applied even if Update fails afterwards, e.g. reading the task back.
+	// Restoring the previous state is harmless when it was not applied, so the undo is kept
+	updated, err := e.updater.Update(ctx, changes)
+	if err != nil {
+		return nil, undo, err
+	}
+
+	return updated, undo, nil
+}
+
+func (e *Executor) delete(
+	ctx context.Context,
+	owner coredto.User,
+	itemID uint64,
+	needUndo bool,
+) (*coredto.ToDoItem, undoFunc, error) {
+	item := coredto.ToDoItem{ItemID: &itemID, Owner: &owner}
+
+	if !needUndo {
+		return nil, nil, e.deleter.Delete(ctx, item)
+	}
+
+	prev, err := e.getter.GetByID(ctx, owner, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Delete drops the stored details together with subtasks
+	details, err := e.details.GetDetails(ctx, owner, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = e.deleter.Delete(ctx, item); err != nil {
+		return nil, nil, err
+	}
+
+	undo := func(ctx context.Context) error {
+		created, _, err := e.create(ctx, owner, restored(*prev, owner))
+		if err != nil {
+			return err
+		}
+		return e.details.RestoreDetails(ctx, owner, *created.ItemID, *details)
+	}
+
+	return nil, undo, nil
+}
+
+// restored Returns changes bringing the task back to prev state
+func restored(prev coredto.ToDoItem, owner coredto.User) coredto.ToDoItem {
+	item := coredto.ToDoItem{
+		ItemID:      prev.ItemID,
+		Owner:       &owner,
+		Title:       prev.Title,
+		IsDone:      prev.IsDone,
+		Description: prev.Description,
+		DueDate:     prev.DueDate,
+		Priority:    prev.Priority,
+		Tags:        prev.Tags,
+	}
+
+	// Zero due date and empty tags clear the values set by the reverted operation
+	if item.DueDate == nil {
+		item.DueDate = &time.Time{}
+	}
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+	return item
+}
@@ -0,0 +1,330 @@
+package todobatch
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/taskdetails"
+	"todoapiservice/internal/services/todoprovider"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testOwnerID = uint64(1)
+
+func testOwner() coredto.User {
+	return coredto.User{UserID: &testOwnerID}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type taskState struct {
+	title  string
+	isDone bool
+}
+
+func newTestExecutor(t *testing.T) (*Executor, *todoprovider.ToDoProvider) {
+	t.Helper()
+
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "First", false)
+	pr.AddTask(testOwnerID, "Second", true)
+	pr.AddTask(testOwnerID, "Third", false)
+
+	provider := todoprovider.New(slog.Default(), pr, taskdetails.NewMemoryStore(), configapplication.TasksConfig{})
+	executor := New(
+		slog.Default(),
+		provider, provider, provider, provider, provider,
+		configapplication.TasksConfig{BatchMaxOperations: 10, BatchConcurrency: 4},
+	)
+	return executor, provider
+}
+
+func snapshot(t *testing.T, provider *todoprovider.ToDoProvider) map[uint64]taskState {
+	t.Helper()
+
+	page, err := provider.GetList(context.Background(), testOwner(), coredto.ToDoListQuery{})
+	require.NoError(t, err)
+
+	state := make(map[uint64]taskState, len(page.Items))
+	for _, item := range page.Items {
+		state[*item.ItemID] = taskState{title: *item.Title, isDone: *item.IsDone}
+	}
+	return state
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	executor, provider := newTestExecutor(t)
+
+	result, err := executor.Execute(context.Background(), testOwner(), []coredto.ToDoOperation{
+		{Kind: coredto.OperationCreate, Item: coredto.ToDoItem{Title: ptr("Fourth"), IsDone: ptr(true)}},
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{ItemID: ptr(uint64(1)), Title: ptr("First changed")}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(3))}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(42))}},
+	}, false)
+
+	require.NoError(t, err)
+	require.False(t, result.RolledBack)
+	require.Len(t, result.Results, 4)
+
+	require.NoError(t, result.Results[0].Err)
+	require.Equal(t, "Fourth", *result.Results[0].Item.Title)
+
+	require.NoError(t, result.Results[1].Err)
+	require.Equal(t, "First changed", *result.Results[1].Item.Title)
+	require.False(t, *result.Results[1].Item.IsDone)
+
+	require.NoError(t, result.Results[2].Err)
+	require.ErrorIs(t, result.Results[3].Err, todoprovider.ErrToDoNotFound)
+
+	require.Equal(t, map[uint64]taskState{
+		1: {title: "First changed"},
+		2: {title: "Second", isDone: true},
+		4: {title: "Fourth", isDone: true},
+	}, snapshot(t, provider))
+}
+
+func TestExecutor_Execute_AtomicRollback(t *testing.T) {
+	executor, provider := newTestExecutor(t)
+
+	result, err := executor.Execute(context.Background(), testOwner(), []coredto.ToDoOperation{
+		{Kind: coredto.OperationCreate, Item: coredto.ToDoItem{Title: ptr("Fourth")}},
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{ItemID: ptr(uint64(2)), Title: ptr("Changed"), IsDone: ptr(false)}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(3))}},
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{ItemID: ptr(uint64(42)), IsDone: ptr(true)}},
+	}, true)
+
+	require.NoError(t, err)
+	require.True(t, result.RolledBack)
+
+	for i := 0; i < 3; i++ {
+		require.ErrorIs(t, result.Results[i].Err, ErrOperationRolledBack)
+		require.True(t, result.Results[i].RolledBack)
+	}
+	require.ErrorIs(t, result.Results[3].Err, todoprovider.ErrToDoNotFound)
+
+	// Deleted task is restored under a new ID, created task is removed
+	state := snapshot(t, provider)
+	require.Len(t, state, 3)
+	require.Equal(t, taskState{title: "First"}, state[1])
+	require.Equal(t, taskState{title: "Second", isDone: true}, state[2])
+	require.NotContains(t, state, uint64(3))
+
+	var restored []taskState
+	for id, task := range state {
+		if id > 3 {
+			restored = append(restored, task)
+		}
+	}
+	require.Equal(t, []taskState{{title: "Third"}}, restored)
+}
+
+func TestExecutor_Execute_AtomicRollbackDetails(t *testing.T) {
+	executor, provider := newTestExecutor(t)
+	ctx := context.Background()
+
+	high := coredto.PriorityHigh
+	_, err := provider.Update(ctx, coredto.ToDoItem{
+		ItemID:      ptr(uint64(1)),
+		Owner:       ptr(testOwner()),
+		Description: ptr("Oat milk"),
+		Priority:    &high,
+		Tags:        []string{"shop"},
+	})
+	require.NoError(t, err)
+	before, err := provider.GetByID(ctx, testOwner(), 1)
+	require.NoError(t, err)
+
+	low := coredto.PriorityLow
+	result, err := executor.Execute(ctx, testOwner(), []coredto.ToDoOperation{
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{
+			ItemID:   ptr(uint64(1)),
+			DueDate:  ptr(time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)),
+			Priority: &low,
+			Tags:     []string{},
+		}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(42))}},
+	}, true)
+
+	require.NoError(t, err)
+	require.True(t, result.RolledBack)
+
+	after, err := provider.GetByID(ctx, testOwner(), 1)
+	require.NoError(t, err)
+	require.Nil(t, after.DueDate)
+	require.Equal(t, before.Description, after.Description)
+	require.Equal(t, before.Priority, after.Priority)
+	require.Equal(t, before.Tags, after.Tags)
+}
+
+func TestExecutor_Execute_AtomicRollbackSubtasks(t *testing.T) {
+	executor, provider := newTestExecutor(t)
+	ctx := context.Background()
+	owner := testOwner()
+
+	_, err := provider.CreateSubtask(ctx, coredto.ToDoSubtask{
+		ParentID:  ptr(uint64(3)),
+		Owner:     &owner,
+		Title:     ptr("Check fridge"),
+		IsDone:    ptr(true),
+		Checklist: []coredto.ToDoChecklistItem{{Title: "Milk"}},
+	})
+	require.NoError(t, err)
+
+	result, err := executor.Execute(ctx, owner, []coredto.ToDoOperation{
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(3))}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(42))}},
+	}, true)
+
+	require.NoError(t, err)
+	require.True(t, result.RolledBack)
+
+	var restoredID uint64
+	for id := range snapshot(t, provider) {
+		restoredID = max(restoredID, id)
+	}
+	require.Greater(t, restoredID, uint64(3))
+
+	subtasks, err := provider.GetSubtasks(ctx, owner, restoredID)
+	require.NoError(t, err)
+	require.Len(t, subtasks, 1)
+	require.Equal(t, "Check fridge", *subtasks[0].Title)
+	require.True(t, *subtasks[0].IsDone)
+	require.Equal(t, restoredID, *subtasks[0].ParentID)
+	require.Len(t, subtasks[0].Checklist, 1)
+}
+
+// readBackFailingUpdater Applies the first update, then fails like a lost read-back
+type readBackFailingUpdater struct {
+	*todoprovider.ToDoProvider
+	failed bool
+}
+
+func (u *readBackFailingUpdater) Update(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error) {
+	updated, err := u.ToDoProvider.Update(ctx, item)
+	if err == nil && !u.failed {
+		u.failed = true
+		return nil, todoprovider.ErrBackendUnavailable
+	}
+	return updated, err
+}
+
+func TestExecutor_Execute_AtomicRollbackFailedReadBack(t *testing.T) {
+	_, provider := newTestExecutor(t)
+	updater := &readBackFailingUpdater{ToDoProvider: provider}
+	executor := New(
+		slog.Default(),
+		provider, provider, updater, provider, provider,
+		configapplication.TasksConfig{BatchMaxOperations: 10, BatchConcurrency: 1},
+	)
+	before := snapshot(t, provider)
+
+	result, err := executor.Execute(context.Background(), testOwner(), []coredto.ToDoOperation{
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{ItemID: ptr(uint64(1)), Title: ptr("First changed")}},
+	}, true)
+	require.NoError(t, err)
+	require.ErrorIs(t, result.Results[0].Err, todoprovider.ErrBackendUnavailable)
+
+	// The applied change is reverted although Update reported a failure
+	require.True(t, result.RolledBack)
+	require.Equal(t, before, snapshot(t, provider))
+}
+
+func TestExecutor_Execute_Validation(t *testing.T) {
+	ops := []coredto.ToDoOperation{
+		{Kind: coredto.OperationCreate, Item: coredto.ToDoItem{Title: ptr("Fourth")}},
+		{Kind: coredto.OperationCreate, Item: coredto.ToDoItem{}},
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{ItemID: ptr(uint64(1))}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{}},
+		{Kind: coredto.OperationDelete, Item: coredto.ToDoItem{ItemID: ptr(uint64(2))}},
+		{Kind: coredto.OperationUpdate, Item: coredto.ToDoItem{ItemID: ptr(uint64(2)), IsDone: ptr(true)}},
+		{Kind: "rename", Item: coredto.ToDoItem{ItemID: ptr(uint64(3))}},
+	}
+
+	t.Run("Not atomic", func(t *testing.T) {
+		executor, provider := newTestExecutor(t)
+
+		result, err := executor.Execute(context.Background(), testOwner(), ops, false)
+		require.NoError(t, err)
+
+		require.NoError(t, result.Results[0].Err)
+		require.NoError(t, result.Results[4].Err)
+		for _, i := range []int{1, 2, 3, 5, 6} {
+			require.ErrorIs(t, result.Results[i].Err, ErrInvalidOperation, "operation %d", i)
+		}
+		require.Len(t, snapshot(t, provider), 3)
+	})
+
+	t.Run("Atomic", func(t *testing.T) {
+		executor, provider := newTestExecutor(t)
+		before := snapshot(t, provider)
+
+		result, err := executor.Execute(context.Background(), testOwner(), ops, true)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, result.Results[0].Err, ErrBatchAborted)
+		require.ErrorIs(t, result.Results[4].Err, ErrBatchAborted)
+		require.Equal(t, before, snapshot(t, provider))
+	})
+}
+
+func TestExecutor_Execute_TooLarge(t *testing.T) {
+	executor, _ := newTestExecutor(t)
+
+	ops := make([]coredto.ToDoOperation, 11)
+	result, err := executor.Execute(context.Background(), testOwner(), ops, false)
+
+	require.ErrorIs(t, err, ErrBatchTooLarge)
+	require.Nil(t, result)
+}
+
+// slowCreator Counts concurrent Create calls
+type slowCreator struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (c *slowCreator) Create(_ context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error) {
+	c.mu.Lock()
+	c.current++
+	c.peak = max(c.peak, c.current)
+	c.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+
+	return &coredto.ToDoItem{ItemID: ptr(uint64(1)), Title: item.Title, IsDone: ptr(false), Owner: item.Owner}, nil
+}
+
+func TestExecutor_Execute_BoundedConcurrency(t *testing.T) {
+	creator := &slowCreator{}
+	executor := New(
+		slog.Default(),
+		creator, nil, nil, nil, nil,
+		configapplication.TasksConfig{BatchMaxOperations: 20, BatchConcurrency: 3},
+	)
+
+	ops := make([]coredto.ToDoOperation, 12)
+	for i := range ops {
+		ops[i] = coredto.ToDoOperation{Kind: coredto.OperationCreate, Item: coredto.ToDoItem{Title: ptr("Task")}}
+	}
+
+	result, err := executor.Execute(context.Background(), testOwner(), ops, false)
+	require.NoError(t, err)
+
+	for _, opResult := range result.Results {
+		require.NoError(t, opResult.Err)
+	}
+	require.Equal(t, 3, creator.peak)
+}
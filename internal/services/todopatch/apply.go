@@ -0,0 +1,336 @@
+package todopatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"todoapiservice/internal/services/coredto"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidPatch     = errors.New("invalid patch document")
+	ErrUnsupportedPatch = errors.New("unsupported patch")
+	ErrPatchTestFailed  = errors.New("patch test operation failed")
+)
+
+// Item representation members patch documents refer to
+const (
+	fieldID          = "id"
+	fieldTitle       = "title"
+	fieldIsDone      = "is_done"
+	fieldDescription = "description"
+	fieldDueDate     = "due_date"
+	fieldPriority    = "priority"
+	fieldTags        = "tags"
+)
+
+var patchFields = []string{
+	fieldID, fieldTitle, fieldIsDone, fieldDescription, fieldDueDate, fieldPriority, fieldTags,
+}
+
+func invalidPatch(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPatch, fmt.Sprintf(format, args...))
+}
+
+func unsupportedPatch(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedPatch, fmt.Sprintf(format, args...))
+}
+
+// itemState is the patched item representation
+type itemState struct {
+	id          uint64
+	title       string
+	isDone      bool
+	description string
+	dueDate     *time.Time
+	priority    coredto.ToDoPriority
+	tags        []string
+}
+
+func newItemState(item coredto.ToDoItem) itemState {
+	state := itemState{
+		id:       *item.ItemID,
+		title:    *item.Title,
+		isDone:   *item.IsDone,
+		dueDate:  item.DueDate,
+		priority: coredto.PriorityNormal,
+		tags:     slices.Clone(item.Tags),
+	}
+
+	if item.Description != nil {
+		state.description = *item.Description
+	}
+	if item.Priority != nil {
+		state.priority = *item.Priority
+	}
+	if state.tags == nil {
+		state.tags = []string{}
+	}
+	return state
+}
+
+// remove Resets optional member to its default. Required members can not be removed
+func (s *itemState) remove(field string) error {
+	switch field {
+	case fieldDescription:
+		s.description = ""
+	case fieldDueDate:
+		s.dueDate = nil
+	case fieldPriority:
+		s.priority = coredto.PriorityNormal
+	case fieldTags:
+		s.tags = []string{}
+	case fieldID, fieldTitle, fieldIsDone:
+		return unsupportedPatch("%s can not be removed", field)
+	default:
+		return unsupportedPatch("unknown field %q", field)
+	}
+	return nil
+}
+
+// set Assigns the member value, null removes the member. id is read-only
+func (s *itemState) set(field string, value json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		return s.remove(field)
+	}
+
+	switch field {
+	case fieldTitle:
+		if err := json.Unmarshal(value, &s.title); err != nil {
+			return invalidPatch("title must be a string")
+		}
+	case fieldIsDone:
+		if err := json.Unmarshal(value, &s.isDone); err != nil {
+			return invalidPatch("is_done must be a boolean")
+		}
+	case fieldDescription:
+		if err := json.Unmarshal(value, &s.description); err != nil {
+			return invalidPatch("description must be a string")
+		}
+	case fieldDueDate:
+		var dueDate time.Time
+		if err := json.Unmarshal(value, &dueDate); err != nil {
+			return invalidPatch("due_date must be an RFC 3339 date-time")
+		}
+		s.dueDate = &dueDate
+	case fieldPriority:
+		if err := json.Unmarshal(value, &s.priority); err != nil {
+			return invalidPatch("priority must be a string")
+		}
+	case fieldTags:
+		var tags []string
+		if err := json.Unmarshal(value, &tags); err != nil {
+			return invalidPatch("tags must be an array of strings")
+		}
+		s.tags = tags
+		if s.tags == nil {
+			s.tags = []string{}
+		}
+	case fieldID:
+		var id uint64
+		if err := json.Unmarshal(value, &id); err != nil || id != s.id {
+			return unsupportedPatch("id is read-only")
+		}
+	default:
+		return unsupportedPatch("unknown field %q", field)
+	}
+	return nil
+}
+
+// equal Reports whether the member value equals value as JSON
+func (s *itemState) equal(field string, value json.RawMessage) (bool, error) {
+	var current any
+	switch field {
+	case fieldTitle:
+		current = s.title
+	case fieldIsDone:
+		current = s.isDone
+	case fieldDescription:
+		current = s.description
+	case fieldDueDate:
+		current = s.dueDate
+	case fieldPriority:
+		current = s.priority
+	case fieldTags:
+		current = s.tags
+	case fieldID:
+		current = s.id
+	default:
+		return false, unsupportedPatch("unknown field %q", field)
+	}
+
+	expected, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+
+	var got any
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&got); err != nil {
+		return false, invalidPatch("test value is not valid JSON")
+	}
+	if number, ok := got.(json.Number); ok {
+		return number.String() == string(expected), nil
+	}
+
+	normalized, err := json.Marshal(got)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(normalized, expected), nil
+}
+
+// normalize Trims the title and tags and checks the patched item against request body rules
+func (s *itemState) normalize() error {
+	s.title = strings.TrimSpace(s.title)
+	if err := checkText(fieldTitle, s.title, 1, coredto.MaxTitleLength); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(s.description) > coredto.MaxDescriptionLength {
+		return invalidPatch("description must be at most %d characters long", coredto.MaxDescriptionLength)
+	}
+
+	switch s.priority {
+	case coredto.PriorityLow, coredto.PriorityNormal, coredto.PriorityHigh:
+	default:
+		return invalidPatch("priority must be one of: low normal high")
+	}
+
+	if len(s.tags) > coredto.MaxTags {
+		return invalidPatch("tags must contain at most %d items", coredto.MaxTags)
+	}
+	for i, tag := range s.tags {
+		s.tags[i] = strings.TrimSpace(tag)
+		if err := checkText(fieldTags, s.tags[i], 1, coredto.MaxTagLength); err != nil {
+			return err
+		}
+		if slices.Contains(s.tags[:i], s.tags[i]) {
+			return invalidPatch("tags must not contain duplicates")
+		}
+	}
+	return nil
+}
+
+func checkText(field string, value string, minLength int, maxLength int) error {
+	length := utf8.RuneCountInString(value)
+	if length < minLength || length > maxLength {
+		return invalidPatch("%s must be %d-%d characters long", field, minLength, maxLength)
+	}
+	if strings.ContainsFunc(value, unicode.IsControl) {
+		return invalidPatch("%s must not contain control characters", field)
+	}
+	return nil
+}
+
+// applyMergePatch Applies RFC 7396 JSON Merge Patch
+func applyMergePatch(state itemState, document []byte) (itemState, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(document, &patch); err != nil {
+		return state, invalidPatch("merge patch must be a JSON object")
+	}
+
+	for _, field := range patchFields {
+		value, ok := patch[field]
+		if !ok {
+			continue
+		}
+		delete(patch, field)
+
+		if err := state.set(field, value); err != nil {
+			return state, err
+		}
+	}
+
+	for field, value := range patch {
+		// Removing a member that does not exist is a no-op
+		if !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			return state, unsupportedPatch("unknown field %q", field)
+		}
+	}
+	return state, nil
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// pointerField Returns item member addressed by RFC 6901 JSON Pointer
+func pointerField(pointer string) (string, error) {
+	if pointer == "" {
+		return "", unsupportedPatch("whole document can not be patched")
+	}
+	if pointer[0] != '/' {
+		return "", invalidPatch("path %q is not a JSON pointer", pointer)
+	}
+
+	field := pointer[1:]
+	if strings.Contains(field, "/") {
+		return "", unsupportedPatch("path %q does not exist", pointer)
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(field), nil
+}
+
+// applyJSONPatch Applies RFC 6902 JSON Patch. Operations are applied all or none
+func applyJSONPatch(state itemState, document []byte) (itemState, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(document, &ops); err != nil {
+		return state, invalidPatch("json patch must be an array of operations")
+	}
+
+	for i, op := range ops {
+		if err := applyJSONPatchOperation(&state, op); err != nil {
+			return state, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return state, nil
+}
+
+func applyJSONPatchOperation(state *itemState, op jsonPatchOperation) error {
+	if op.Path == nil {
+		return invalidPatch("path is required")
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return invalidPatch("value is required for %s", op.Op)
+		}
+	case "remove":
+	case "move", "copy":
+		return unsupportedPatch("%s is not supported", op.Op)
+	default:
+		return invalidPatch("unknown operation %q", op.Op)
+	}
+
+	field, err := pointerField(*op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "remove":
+		return state.remove(field)
+	case "add", "replace":
+		// Members always exist, so add replaces the value
+		return state.set(field, op.Value)
+	}
+
+	equal, err := state.equal(field, op.Value)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		return fmt.Errorf("%w: %s does not match", ErrPatchTestFailed, *op.Path)
+	}
+	return nil
+}
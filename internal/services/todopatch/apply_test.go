@@ -0,0 +1,245 @@
+package todopatch
+
+import (
+	"testing"
+	"time"
+	"todoapiservice/internal/services/coredto"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testState   = itemState{id: 7, title: "Buy milk", isDone: false}
+	testDueDate = time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+)
+
+var testDetailedState = itemState{
+	id:          7,
+	title:       "Buy milk",
+	description: "Oat milk",
+	dueDate:     &testDueDate,
+	priority:    coredto.PriorityHigh,
+	tags:        []string{"shop"},
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	testData := []struct {
+		name  string
+		patch string
+		state itemState
+		err   error
+	}{
+		{
+			name:  "Change title",
+			patch: `{"title":"Buy bread"}`,
+			state: itemState{id: 7, title: "Buy bread"},
+		},
+		{
+			name:  "Change both and keep id",
+			patch: `{"id":7,"title":"Buy bread","is_done":true}`,
+			state: itemState{id: 7, title: "Buy bread", isDone: true},
+		},
+		{
+			name:  "Remove unknown member",
+			patch: `{"due":null}`,
+			state: testState,
+		},
+		{
+			name:  "Empty patch",
+			patch: `{}`,
+			state: testState,
+		},
+		{
+			name:  "Set details",
+			patch: `{"description":"Oat milk","due_date":"2024-05-01T18:00:00Z","priority":"high","tags":["shop"]}`,
+			state: testDetailedState,
+		},
+		{
+			name:  "Remove title",
+			patch: `{"title":null}`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Change id",
+			patch: `{"id":8}`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Unknown member",
+			patch: `{"due":"2024-01-01"}`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Wrong type",
+			patch: `{"is_done":"yes"}`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "Not an object",
+			patch: `["title"]`,
+			err:   ErrInvalidPatch,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			state, err := applyMergePatch(testState, []byte(data.patch))
+
+			if data.err != nil {
+				require.ErrorIs(t, err, data.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, data.state, state)
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	testData := []struct {
+		name  string
+		patch string
+		state itemState
+		err   error
+	}{
+		{
+			name:  "Replace",
+			patch: `[{"op":"replace","path":"/is_done","value":true}]`,
+			state: itemState{id: 7, title: "Buy milk", isDone: true},
+		},
+		{
+			name: "Test and replace",
+			patch: `[
+				{"op":"test","path":"/id","value":7},
+				{"op":"test","path":"/title","value":"Buy milk"},
+				{"op":"add","path":"/title","value":"Buy bread"},
+				{"op":"test","path":"/title","value":"Buy bread"}
+			]`,
+			state: itemState{id: 7, title: "Buy bread"},
+		},
+		{
+			name:  "Test failed",
+			patch: `[{"op":"test","path":"/is_done","value":true},{"op":"replace","path":"/title","value":"x"}]`,
+			err:   ErrPatchTestFailed,
+		},
+		{
+			name:  "Remove required field",
+			patch: `[{"op":"remove","path":"/title"}]`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Add details",
+			patch: `[{"op":"add","path":"/tags","value":["shop"]},{"op":"test","path":"/due_date","value":null}]`,
+			state: itemState{id: 7, title: "Buy milk", tags: []string{"shop"}},
+		},
+		{
+			name:  "Move",
+			patch: `[{"op":"move","from":"/title","path":"/is_done"}]`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Replace id",
+			patch: `[{"op":"replace","path":"/id","value":8}]`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Nested path",
+			patch: `[{"op":"replace","path":"/title/0","value":"x"}]`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Whole document",
+			patch: `[{"op":"replace","path":"","value":{}}]`,
+			err:   ErrUnsupportedPatch,
+		},
+		{
+			name:  "Unknown operation",
+			patch: `[{"op":"increment","path":"/id"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "Missing value",
+			patch: `[{"op":"replace","path":"/title"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "Missing path",
+			patch: `[{"op":"replace","value":"x"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "Not an array",
+			patch: `{"op":"replace","path":"/title","value":"x"}`,
+			err:   ErrInvalidPatch,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			state, err := applyJSONPatch(testState, []byte(data.patch))
+
+			if data.err != nil {
+				require.ErrorIs(t, err, data.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, data.state, state)
+		})
+	}
+}
+
+func TestApplyMergePatch_ClearDetails(t *testing.T) {
+	state, err := applyMergePatch(testDetailedState, []byte(`{"description":null,"due_date":null,"priority":null,"tags":null}`))
+
+	require.NoError(t, err)
+	require.Equal(t, itemState{id: 7, title: "Buy milk", priority: coredto.PriorityNormal, tags: []string{}}, state)
+}
+
+func TestApplyJSONPatch_RemoveDetails(t *testing.T) {
+	state, err := applyJSONPatch(testDetailedState, []byte(`[
+		{"op":"test","path":"/priority","value":"high"},
+		{"op":"test","path":"/due_date","value":"2024-05-01T18:00:00Z"},
+		{"op":"remove","path":"/description"},
+		{"op":"remove","path":"/tags"}
+	]`))
+
+	require.NoError(t, err)
+	require.Equal(t, itemState{
+		id:       7,
+		title:    "Buy milk",
+		dueDate:  &testDueDate,
+		priority: coredto.PriorityHigh,
+		tags:     []string{},
+	}, state)
+}
+
+func TestItemState_Normalize(t *testing.T) {
+	testData := []struct {
+		name   string
+		modify func(s *itemState)
+		err    bool
+	}{
+		{name: "Valid", modify: func(s *itemState) { s.title = "  Buy bread " }},
+		{name: "Blank title", modify: func(s *itemState) { s.title = " " }, err: true},
+		{name: "Control characters", modify: func(s *itemState) { s.title = "Buy\x00milk" }, err: true},
+		{name: "Unknown priority", modify: func(s *itemState) { s.priority = "urgent" }, err: true},
+		{name: "Empty tag", modify: func(s *itemState) { s.tags = []string{"shop", " "} }, err: true},
+		{name: "Duplicate tags", modify: func(s *itemState) { s.tags = []string{"shop", " shop"} }, err: true},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			state := testDetailedState
+			state.tags = []string{"shop"}
+			data.modify(&state)
+
+			err := state.normalize()
+			if data.err {
+				require.ErrorIs(t, err, ErrInvalidPatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "Buy bread", state.title)
+		})
+	}
+}
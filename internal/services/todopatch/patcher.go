@@ -0,0 +1,105 @@
+// Package todopatch implements applying JSON Merge Patch and JSON Patch documents to ToDo items
+package todopatch
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/todoprovider"
+)
+
+// maxAttempts limits re-reading the item when it is changed concurrently
+const maxAttempts = 3
+
+type IToDoGetter interface {
+	GetByID(ctx context.Context, owner coredto.User, itemID uint64) (*coredto.ToDoItem, error)
+}
+
+type IToDoUpdater interface {
+	Update(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error)
+}
+
+type Patcher struct {
+	getter  IToDoGetter
+	updater IToDoUpdater
+}
+
+func New(
+	getter IToDoGetter,
+	updater IToDoUpdater,
+) *Patcher {
+	return &Patcher{
+		getter:  getter,
+		updater: updater,
+	}
+}
+
+// Patch Applies the patch document to the current item state and stores the result.
+// With version set the item must still have it, otherwise the patch is reapplied
+// if the item is changed between reading and writing
+func (p *Patcher) Patch(
+	ctx context.Context,
+	owner coredto.User,
+	itemID uint64,
+	version *string,
+	patch coredto.ToDoPatch,
+) (*coredto.ToDoItem, error) {
+	apply, err := patchFunc(patch.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, err := p.getter.GetByID(ctx, owner, itemID)
+		if err != nil {
+			return nil, err
+		}
+		if version != nil && *version != *current.Version {
+			return nil, todoprovider.ErrVersionMismatch
+		}
+
+		state, err := apply(newItemState(*current), patch.Document)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.normalize(); err != nil {
+			return nil, err
+		}
+
+		// Zero due date clears it on Update
+		dueDate := state.dueDate
+		if dueDate == nil {
+			dueDate = &time.Time{}
+		}
+
+		// Patch is computed against the read state, so it is written only if the state is unchanged
+		updated, err := p.updater.Update(ctx, coredto.ToDoItem{
+			ItemID:  &itemID,
+			Owner:   &owner,
+			Title:   &state.title,
+			IsDone:  &state.isDone,
+			Version: current.Version,
+
+			Description: &state.description,
+			DueDate:     dueDate,
+			Priority:    &state.priority,
+			Tags:        state.tags,
+		})
+		if errors.Is(err, todoprovider.ErrVersionMismatch) && version == nil && attempt < maxAttempts {
+			continue
+		}
+		return updated, err
+	}
+}
+
+func patchFunc(format coredto.ToDoPatchFormat) (func(itemState, []byte) (itemState, error), error) {
+	switch format {
+	case coredto.PatchMerge:
+		return applyMergePatch, nil
+	case coredto.PatchJSON:
+		return applyJSONPatch, nil
+	default:
+		return nil, unsupportedPatch("unknown patch format %q", format)
+	}
+}
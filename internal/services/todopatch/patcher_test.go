@@ -0,0 +1,69 @@
+package todopatch
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/taskdetails"
+	"todoapiservice/internal/services/todoprovider"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testOwnerID = uint64(1)
+
+// racingUpdater Changes the item once before the first update, as a concurrent client would
+type racingUpdater struct {
+	*todoprovider.ToDoProvider
+	raced bool
+}
+
+func (u *racingUpdater) Update(ctx context.Context, item coredto.ToDoItem) (*coredto.ToDoItem, error) {
+	if !u.raced {
+		u.raced = true
+		title := "Changed concurrently"
+		_, err := u.ToDoProvider.Update(ctx, coredto.ToDoItem{ItemID: item.ItemID, Owner: item.Owner, Title: &title})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return u.ToDoProvider.Update(ctx, item)
+}
+
+func TestPatcher_Patch(t *testing.T) {
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "Buy milk", false)
+	provider := todoprovider.New(slog.Default(), pr, taskdetails.NewMemoryStore(), configapplication.TasksConfig{})
+	owner := coredto.User{UserID: &testOwnerID}
+	ctx := context.Background()
+
+	current, err := provider.GetByID(ctx, owner, 1)
+	require.NoError(t, err)
+
+	patcher := New(provider, &racingUpdater{ToDoProvider: provider})
+
+	// Concurrent change is detected and the patch is applied again to the new state
+	item, err := patcher.Patch(ctx, owner, 1, nil, coredto.ToDoPatch{
+		Format:   coredto.PatchMerge,
+		Document: []byte(`{"is_done":true}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Changed concurrently", *item.Title)
+	require.True(t, *item.IsDone)
+
+	// The client version is stale now
+	_, err = patcher.Patch(ctx, owner, 1, current.Version, coredto.ToDoPatch{
+		Format:   coredto.PatchJSON,
+		Document: []byte(`[{"op":"replace","path":"/is_done","value":false}]`),
+	})
+	require.ErrorIs(t, err, todoprovider.ErrVersionMismatch)
+
+	_, err = patcher.Patch(ctx, owner, 42, nil, coredto.ToDoPatch{
+		Format:   coredto.PatchMerge,
+		Document: []byte(`{}`),
+	})
+	require.ErrorIs(t, err, todoprovider.ErrToDoNotFound)
+}
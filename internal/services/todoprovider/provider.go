@@ -4,7 +4,11 @@ package todoprovider
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"time"
+	"todoapiservice/internal/app/configapplication"
 	"todoapiservice/internal/services/coredto"
 
 	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
@@ -15,58 +19,140 @@ import (
 var (
 	ErrToDoInternal = errors.New("todo provider internal error")
 	ErrToDoNotFound = errors.New("todo item not found")
+
+	ErrBackendUnavailable = fmt.Errorf("todo backend unavailable: %w", ErrToDoInternal)
+	ErrBackendTimeout     = fmt.Errorf("todo backend timeout: %w", ErrToDoInternal)
 )
 
+// backendError Classifies backend call failure
+func backendError(err error) error {
+	switch {
+	case status.Code(err) == codes.Unavailable:
+		return errors.Join(ErrBackendUnavailable, err)
+	case status.Code(err) == codes.DeadlineExceeded, errors.Is(err, context.DeadlineExceeded):
+		return errors.Join(ErrBackendTimeout, err)
+	}
+	return errors.Join(ErrToDoInternal, err)
+}
+
+// IDetailsStore stores item fields the backend can not carry yet
+type IDetailsStore interface {
+	Get(ctx context.Context, userID uint64, itemIDs []uint64) (map[uint64]coredto.ToDoItemDetails, error)
+	Put(ctx context.Context, userID uint64, itemID uint64, details coredto.ToDoItemDetails) error
+	Delete(ctx context.Context, userID uint64, itemID uint64) error
+}
+
 type ToDoProvider struct {
-	logger *slog.Logger
-	client todoprotobufv1.ToDoServiceClient
+	logger       *slog.Logger
+	client       todoprotobufv1.ToDoServiceClient
+	details      IDetailsStore
+	locks        versionLocks
+	cursors      *cursorCipher
+	now          func() time.Time
+	autoComplete bool
 }
 
 func New(
 	logger *slog.Logger,
 	client todoprotobufv1.ToDoServiceClient,
+	details IDetailsStore,
+	conf configapplication.TasksConfig,
 ) *ToDoProvider {
 	return &ToDoProvider{
-		logger: logger.With("module", "todoprovider"),
-		client: client,
+		logger:       logger.With("module", "todoprovider"),
+		client:       client,
+		details:      details,
+		cursors:      newCursorCipher(conf.CursorSecret),
+		now:          time.Now,
+		autoComplete: conf.SubtasksAutoComplete,
 	}
 }
 
+// Create Creates item with its title, done flag and details.
+// The backend creates tasks as not done and without details, so the rest is applied after creation.
+// If that fails the created task is deleted, so a client retry does not duplicate it
 func (p *ToDoProvider) Create(
 	ctx context.Context,
-	owner coredto.User,
-	title string,
+	item coredto.ToDoItem,
 ) (*coredto.ToDoItem, error) {
 	log := p.logger.With("method", "Create")
+	owner := *item.Owner
+
 	resp, err := p.client.CreateTask(
 		ctx,
 		&todoprotobufv1.CreateTaskRequest{
-			Title:  title,
+			Title:  *item.Title,
 			UserId: *owner.UserID,
 		},
 	)
 
 	if err != nil {
 		log.Error("create error", slog.Any("err", err))
+		return nil, backendError(err)
+	}
+
+	itemID := resp.GetTaskId()
+	isDone := item.IsDone != nil && *item.IsDone
+
+	if isDone {
+		_, err = p.client.UpdateTaskByID(
+			ctx,
+			&todoprotobufv1.UpdateTaskByIdRequest{
+				TaskId: itemID,
+				UserId: *owner.UserID,
+				IsDone: &isDone,
+			})
+
+		if err != nil {
+			log.Error("create done error", slog.Any("err", err))
+			p.discardCreated(ctx, owner, itemID)
+			return nil, backendError(err)
+		}
+	}
+
+	now := p.now().UTC()
+	details := mergeDetails(coredto.ToDoItemDetails{
+		Priority:  coredto.PriorityNormal,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, item)
+
+	err = p.details.Put(ctx, *owner.UserID, itemID, details)
+	if err != nil {
+		log.Error("create details error", slog.Any("err", err))
+		p.discardCreated(ctx, owner, itemID)
 		return nil, errors.Join(ErrToDoInternal, err)
 	}
 
-	newItemID := resp.GetTaskId()
-	isDone := false
+	return newItem(owner, itemID, *item.Title, isDone, details, true), nil
+}
 
-	return &coredto.ToDoItem{
-		ItemID: &newItemID,
-		Owner:  &owner,
-		Title:  &title,
-		IsDone: &isDone,
-	}, nil
+// discardCreated Deletes the backend task whose creation failed half way
+func (p *ToDoProvider) discardCreated(ctx context.Context, owner coredto.User, itemID uint64) {
+	_, err := p.client.DeleteTaskByID(context.WithoutCancel(ctx), &todoprotobufv1.TaskByIdRequest{
+		TaskId: itemID,
+		UserId: *owner.UserID,
+	})
+	if err != nil {
+		p.logger.Error("created task cleanup error", slog.Any("err", err))
+	}
 }
 
+// Delete Deletes item. When item version is set the stored item must match it
 func (p *ToDoProvider) Delete(
 	ctx context.Context,
 	item coredto.ToDoItem,
 ) error {
 	log := p.logger.With("method", "Delete")
+
+	defer p.locks.lock(*item.ItemID)()
+
+	if item.Version != nil {
+		if err := p.checkVersion(ctx, item); err != nil {
+			return err
+		}
+	}
+
 	_, err := p.client.DeleteTaskByID(ctx, &todoprotobufv1.TaskByIdRequest{
 		TaskId: *item.ItemID,
 		UserId: *item.Owner.UserID,
@@ -77,7 +163,13 @@ func (p *ToDoProvider) Delete(
 			return ErrToDoNotFound
 		}
 		log.Error("delete error", slog.Any("err", err))
-		return errors.Join(ErrToDoInternal, err)
+		return backendError(err)
+	}
+
+	err = p.details.Delete(ctx, *item.Owner.UserID, *item.ItemID)
+	if err != nil {
+		// The task is gone, stale details are only a leak
+		log.Error("delete details error", slog.Any("err", err))
 	}
 	return nil
 }
@@ -98,24 +190,49 @@ func (p *ToDoProvider) GetByID(
 			return nil, ErrToDoNotFound
 		}
 		log.Error("get by id error", slog.Any("err", err))
+		return nil, backendError(err)
+	}
+
+	details, err := p.details.Get(ctx, *owner.UserID, []uint64{itemID})
+	if err != nil {
+		log.Error("get details error", slog.Any("err", err))
 		return nil, errors.Join(ErrToDoInternal, err)
 	}
 
-	title := resp.GetTitle()
-	isDone := resp.GetIsDone()
+	itemDetails, hasDetails := details[itemID]
+	return newItem(owner, itemID, resp.GetTitle(), resp.GetIsDone(), itemDetails, hasDetails), nil
+}
 
-	return &coredto.ToDoItem{
-		ItemID: &itemID,
-		Title:  &title,
-		IsDone: &isDone,
-		Owner:  &owner,
-	}, nil
+// checkVersion Returns ErrVersionMismatch if the stored item has other version
+func (p *ToDoProvider) checkVersion(ctx context.Context, item coredto.ToDoItem) error {
+	current, err := p.GetByID(ctx, *item.Owner, *item.ItemID)
+	if err != nil {
+		return err
+	}
 
+	if *current.Version != *item.Version {
+		return ErrVersionMismatch
+	}
+	return nil
 }
 
+// GetList Returns a page of owner tasks. Filtering, sorting and paging are applied by the gateway
 func (p *ToDoProvider) GetList(
 	ctx context.Context,
 	owner coredto.User,
+	query coredto.ToDoListQuery,
+) (*coredto.ToDoListPage, error) {
+	items, err := p.listAll(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.cursors.applyListQuery(*owner.UserID, items, query)
+}
+
+func (p *ToDoProvider) listAll(
+	ctx context.Context,
+	owner coredto.User,
 ) ([]coredto.ToDoItem, error) {
 	log := p.logger.With("method", "GetList")
 	resp, err := p.client.ListTasks(ctx,
@@ -125,29 +242,49 @@ func (p *ToDoProvider) GetList(
 
 	if err != nil {
 		log.Error("get list error", slog.Any("err", err))
-		return nil, errors.Join(ErrToDoInternal, err)
+		return nil, backendError(err)
 	}
 
 	tasksR := resp.GetTasks()
-	result := make([]coredto.ToDoItem, 0, len(tasksR))
 
+	itemIDs := make([]uint64, 0, len(tasksR))
 	for _, item := range tasksR {
-		result = append(result, coredto.ToDoItem{
-			ItemID: &item.TaskId,
-			Title:  &item.Title,
-			IsDone: &item.IsDone,
-			Owner:  &owner,
-		})
+		itemIDs = append(itemIDs, item.GetTaskId())
+	}
+
+	details, err := p.details.Get(ctx, *owner.UserID, itemIDs)
+	if err != nil {
+		log.Error("get list details error", slog.Any("err", err))
+		return nil, errors.Join(ErrToDoInternal, err)
+	}
+
+	result := make([]coredto.ToDoItem, 0, len(tasksR))
+	for _, item := range tasksR {
+		itemDetails, hasDetails := details[item.GetTaskId()]
+		result = append(result,
+			*newItem(owner, item.GetTaskId(), item.GetTitle(), item.GetIsDone(), itemDetails, hasDetails))
 	}
 
 	return result, nil
 }
 
+// Update Applies item changes and returns the item state read back from the backend after the change.
+// When item version is set the stored item must match it
 func (p *ToDoProvider) Update(
 	ctx context.Context,
 	item coredto.ToDoItem,
 ) (*coredto.ToDoItem, error) {
 	log := p.logger.With("method", "Update")
+
+	// Details are changed with read-modify-write
+	defer p.locks.lock(*item.ItemID)()
+
+	if item.Version != nil {
+		if err := p.checkVersion(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+
 	_, err := p.client.UpdateTaskByID(
 		ctx,
 		&todoprotobufv1.UpdateTaskByIdRequest{
@@ -162,10 +299,98 @@ func (p *ToDoProvider) Update(
 			return nil, ErrToDoNotFound
 		}
 		log.Error("update error", slog.Any("err", err))
+		return nil, backendError(err)
+	}
+
+	details, err := p.updateDetails(ctx, item)
+	if err != nil {
+		log.Error("update details error", slog.Any("err", err))
+		return nil, errors.Join(ErrToDoInternal, err)
+	}
+
+	// The backend may normalize the change, so the stored task is returned. Details are already known
+	resp, err := p.client.GetTaskByID(ctx, &todoprotobufv1.TaskByIdRequest{
+		TaskId: *item.ItemID,
+		UserId: *item.Owner.UserID,
+	})
+
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrToDoNotFound
+		}
+		log.Error("update read back error", slog.Any("err", err))
+		return nil, backendError(err)
+	}
+
+	return newItem(*item.Owner, *item.ItemID, resp.GetTitle(), resp.GetIsDone(), details, true), nil
+}
+
+// updateDetails Merges item changes into the stored details, bumps update time and returns the stored details.
+// Tasks created bypassing the gateway get details on their first update
+func (p *ToDoProvider) updateDetails(ctx context.Context, item coredto.ToDoItem) (coredto.ToDoItemDetails, error) {
+	userID := *item.Owner.UserID
+
+	details, err := p.loadDetails(ctx, userID, *item.ItemID)
+	if err != nil {
+		return coredto.ToDoItemDetails{}, err
+	}
+
+	details = mergeDetails(details, item)
+	details.UpdatedAt = p.now().UTC()
+
+	return details, p.details.Put(ctx, userID, *item.ItemID, details)
+}
+
+// GetDetails Returns gateway stored details of the task, subtasks included
+func (p *ToDoProvider) GetDetails(
+	ctx context.Context,
+	owner coredto.User,
+	itemID uint64,
+) (*coredto.ToDoItemDetails, error) {
+	log := p.logger.With("method", "GetDetails")
+
+	details, err := p.loadDetails(ctx, *owner.UserID, itemID)
+	if err != nil {
+		log.Error("get details error", slog.Any("err", err))
 		return nil, errors.Join(ErrToDoInternal, err)
 	}
+	return &details, nil
+}
+
+// RestoreDetails Replaces gateway stored details of the task with a snapshot taken by GetDetails.
+// Subtasks are moved to the task, so details of a deleted task can be restored under a new ID
+func (p *ToDoProvider) RestoreDetails(
+	ctx context.Context,
+	owner coredto.User,
+	itemID uint64,
+	details coredto.ToDoItemDetails,
+) error {
+	log := p.logger.With("method", "RestoreDetails")
+
+	defer p.locks.lock(itemID)()
+
+	details.Subtasks = slices.Clone(details.Subtasks)
+	for i := range details.Subtasks {
+		details.Subtasks[i].ParentID = &itemID
+	}
+
+	if err := p.details.Put(ctx, *owner.UserID, itemID, details); err != nil {
+		log.Error("put details error", slog.Any("err", err))
+		return errors.Join(ErrToDoInternal, err)
+	}
+	return nil
+}
 
-	//TODO:update gRPC for get updated item
+// loadDetails Returns stored item details or defaults if the item has none
+func (p *ToDoProvider) loadDetails(ctx context.Context, userID uint64, itemID uint64) (coredto.ToDoItemDetails, error) {
+	stored, err := p.details.Get(ctx, userID, []uint64{itemID})
+	if err != nil {
+		return coredto.ToDoItemDetails{}, err
+	}
 
-	return &item, nil
+	details, ok := stored[itemID]
+	if !ok {
+		details = coredto.ToDoItemDetails{Priority: coredto.PriorityNormal, CreatedAt: p.now().UTC()}
+	}
+	return details, nil
 }
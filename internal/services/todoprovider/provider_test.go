@@ -0,0 +1,417 @@
+package todoprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"testing"
+	"time"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/taskdetails"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testOwnerID = uint64(1)
+
+func testOwner() coredto.User {
+	return coredto.User{UserID: &testOwnerID}
+}
+
+func newSeededInstance(t *testing.T) (*ToDoProvider, *mocks.ToDoGrpcMock) {
+	t.Helper()
+
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "Buy milk", false)
+	pr.AddTask(testOwnerID, "write report", true)
+	pr.AddTask(testOwnerID, "Call mom", false)
+	pr.AddTask(testOwnerID, "buy bread", true)
+	pr.AddTask(testOwnerID, "Archive mail", false)
+	pr.AddTask(2, "Other user task", false)
+
+	return New(slog.Default(), pr, taskdetails.NewMemoryStore(), configapplication.TasksConfig{}), pr
+}
+
+func itemIDs(items []coredto.ToDoItem) []uint64 {
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, *item.ItemID)
+	}
+	return ids
+}
+
+func TestToDoProvider_GetList_Query(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	isDone := true
+	notDone := false
+
+	testData := []struct {
+		name  string
+		query coredto.ToDoListQuery
+		ids   []uint64
+	}{
+		{
+			name:  "Default order",
+			query: coredto.ToDoListQuery{},
+			ids:   []uint64{1, 2, 3, 4, 5},
+		},
+		{
+			name:  "Descending ID",
+			query: coredto.ToDoListQuery{SortBy: coredto.SortByID, Descending: true},
+			ids:   []uint64{5, 4, 3, 2, 1},
+		},
+		{
+			name:  "Title case-insensitive",
+			query: coredto.ToDoListQuery{SortBy: coredto.SortByTitle},
+			ids:   []uint64{5, 4, 1, 3, 2},
+		},
+		{
+			name:  "Is done then ID",
+			query: coredto.ToDoListQuery{SortBy: coredto.SortByIsDone},
+			ids:   []uint64{1, 3, 5, 2, 4},
+		},
+		{
+			name:  "Done filter",
+			query: coredto.ToDoListQuery{IsDone: &isDone},
+			ids:   []uint64{2, 4},
+		},
+		{
+			name:  "Title filter",
+			query: coredto.ToDoListQuery{TitleContains: "BUY"},
+			ids:   []uint64{1, 4},
+		},
+		{
+			name:  "Combined filters",
+			query: coredto.ToDoListQuery{IsDone: &notDone, TitleContains: "m"},
+			ids:   []uint64{1, 3, 5},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			page, err := instance.GetList(context.Background(), testOwner(), data.query)
+
+			require.NoError(t, err)
+			require.Equal(t, data.ids, itemIDs(page.Items))
+			require.Equal(t, len(data.ids), page.Total)
+			require.Empty(t, page.NextCursor)
+		})
+	}
+}
+
+func TestToDoProvider_GetList_Pagination(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+
+	query := coredto.ToDoListQuery{Limit: 2, SortBy: coredto.SortByTitle, Descending: true}
+
+	var ids []uint64
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+
+		page, err := instance.GetList(ctx, testOwner(), query)
+		require.NoError(t, err)
+		require.Equal(t, 5, page.Total)
+
+		ids = append(ids, itemIDs(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	require.Equal(t, []uint64{2, 3, 1, 4, 5}, ids)
+}
+
+func TestToDoProvider_GetList_CursorSurvivesDeletion(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+
+	page, err := instance.GetList(ctx, testOwner(), coredto.ToDoListQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, itemIDs(page.Items))
+
+	// Last returned item is deleted before the next page is requested
+	require.NoError(t, instance.Delete(ctx, coredto.ToDoItem{ItemID: page.Items[1].ItemID, Owner: page.Items[1].Owner}))
+
+	page, err = instance.GetList(ctx, testOwner(), coredto.ToDoListQuery{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 4}, itemIDs(page.Items))
+}
+
+func TestToDoProvider_GetList_InvalidCursor(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+
+	page, err := instance.GetList(ctx, testOwner(), coredto.ToDoListQuery{Limit: 2})
+	require.NoError(t, err)
+
+	testData := []struct {
+		name  string
+		query coredto.ToDoListQuery
+	}{
+		{
+			name:  "Garbage",
+			query: coredto.ToDoListQuery{Cursor: "not a cursor"},
+		},
+		{
+			name:  "Other sort",
+			query: coredto.ToDoListQuery{Cursor: page.NextCursor, SortBy: coredto.SortByTitle},
+		},
+		{
+			name:  "Other filter",
+			query: coredto.ToDoListQuery{Cursor: page.NextCursor, TitleContains: "buy"},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			_, err := instance.GetList(ctx, testOwner(), data.query)
+			require.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+
+	otherOwnerID := uint64(2)
+	_, err = instance.GetList(ctx, coredto.User{UserID: &otherOwnerID}, coredto.ToDoListQuery{Cursor: page.NextCursor})
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestToDoProvider_GetList_OpaqueCursor(t *testing.T) {
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "Secret plan", false)
+	pr.AddTask(testOwnerID, "Other plan", false)
+	conf := configapplication.TasksConfig{CursorSecret: "shared"}
+	instance := New(slog.Default(), pr, taskdetails.NewMemoryStore(), conf)
+	ctx := context.Background()
+
+	query := coredto.ToDoListQuery{Limit: 1, SortBy: coredto.SortByTitle}
+	page, err := instance.GetList(ctx, testOwner(), query)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, itemIDs(page.Items))
+
+	decoded, err := base64.RawURLEncoding.DecodeString(page.NextCursor)
+	require.NoError(t, err)
+	require.NotContains(t, string(decoded), "plan")
+
+	// Replicas sharing the secret accept each other cursors
+	replica := New(slog.Default(), pr, taskdetails.NewMemoryStore(), conf)
+	query.Cursor = page.NextCursor
+	page, err = replica.GetList(ctx, testOwner(), query)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, itemIDs(page.Items))
+}
+
+func TestToDoProvider_GetList_ServerUnavailable(t *testing.T) {
+	instance := New(slog.Default(), mocks.New(true), taskdetails.NewMemoryStore(), configapplication.TasksConfig{})
+
+	page, err := instance.GetList(context.Background(), testOwner(), coredto.ToDoListQuery{})
+
+	require.ErrorIs(t, err, ErrToDoInternal)
+	require.Nil(t, page)
+}
+
+func TestToDoProvider_Update_ReturnsStoredItem(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+
+	itemID := uint64(2)
+	isDone := false
+	item, err := instance.Update(ctx, coredto.ToDoItem{
+		ItemID: &itemID,
+		Owner:  &coredto.User{UserID: &testOwnerID},
+		IsDone: &isDone,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, itemID, *item.ItemID)
+	require.Equal(t, "write report", *item.Title)
+	require.False(t, *item.IsDone)
+
+	stored, err := instance.GetByID(ctx, testOwner(), itemID)
+	require.NoError(t, err)
+	require.Equal(t, stored, item)
+}
+
+func TestToDoProvider_Update_ReadsBack(t *testing.T) {
+	instance, pr := newSeededInstance(t)
+	ctx := context.Background()
+
+	// Without version the task is read only after the change
+	pr.ResetCalls()
+	itemID := uint64(2)
+	title := "Changed"
+	item, err := instance.Update(ctx, coredto.ToDoItem{
+		ItemID: &itemID,
+		Owner:  &coredto.User{UserID: &testOwnerID},
+		Title:  &title,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "Changed", *item.Title)
+	require.Equal(t, 1, pr.Calls("UpdateTaskByID"))
+	require.Equal(t, 1, pr.Calls("GetTaskByID"))
+}
+
+func TestToDoProvider_Update_NotFound(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+
+	// Task of another user
+	itemID := uint64(6)
+	title := "Changed"
+	item, err := instance.Update(context.Background(), coredto.ToDoItem{
+		ItemID: &itemID,
+		Owner:  &coredto.User{UserID: &testOwnerID},
+		Title:  &title,
+	})
+
+	require.ErrorIs(t, err, ErrToDoNotFound)
+	require.Nil(t, item)
+}
+
+func TestToDoProvider_CreateWithFields(t *testing.T) {
+	pr := mocks.New(false)
+	store := &failingPutStore{MemoryStore: taskdetails.NewMemoryStore()}
+	instance := New(slog.Default(), pr, store, configapplication.TasksConfig{})
+	ctx := context.Background()
+	owner := testOwner()
+
+	title, done, high := "Plan trip", true, coredto.PriorityHigh
+	item, err := instance.Create(ctx, coredto.ToDoItem{
+		Owner:    &owner,
+		Title:    &title,
+		IsDone:   &done,
+		Priority: &high,
+		Tags:     []string{"travel"},
+	})
+	require.NoError(t, err)
+
+	stored, err := instance.GetByID(ctx, owner, *item.ItemID)
+	require.NoError(t, err)
+	require.Equal(t, item, stored)
+	require.True(t, *stored.IsDone)
+	require.Equal(t, coredto.PriorityHigh, *stored.Priority)
+	require.Equal(t, []string{"travel"}, stored.Tags)
+
+	// A half created task is deleted, so a retry does not duplicate it
+	store.fail = true
+	_, err = instance.Create(ctx, coredto.ToDoItem{Owner: &owner, Title: &title})
+	require.ErrorIs(t, err, ErrToDoInternal)
+
+	list, err := instance.GetList(ctx, owner, coredto.ToDoListQuery{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, 1, pr.Calls("DeleteTaskByID"))
+}
+
+func TestToDoProvider_Details(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+	owner := testOwner()
+
+	created := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	instance.now = func() time.Time { return created }
+
+	title := "Plan trip"
+	item, err := instance.Create(ctx, coredto.ToDoItem{Owner: &owner, Title: &title})
+	require.NoError(t, err)
+	require.Equal(t, "", *item.Description)
+	require.Nil(t, item.DueDate)
+	require.Equal(t, coredto.PriorityNormal, *item.Priority)
+	require.Equal(t, []string{}, item.Tags)
+	require.Equal(t, created, *item.CreatedAt)
+	require.Equal(t, created, *item.UpdatedAt)
+
+	updated := created.Add(time.Hour)
+	instance.now = func() time.Time { return updated }
+
+	description := "Book hotel"
+	dueDate := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	high := coredto.PriorityHigh
+	item, err = instance.Update(ctx, coredto.ToDoItem{
+		ItemID:      item.ItemID,
+		Owner:       &owner,
+		Description: &description,
+		DueDate:     &dueDate,
+		Priority:    &high,
+		Tags:        []string{"travel"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Plan trip", *item.Title)
+	require.Equal(t, description, *item.Description)
+	require.Equal(t, dueDate, *item.DueDate)
+	require.Equal(t, high, *item.Priority)
+	require.Equal(t, []string{"travel"}, item.Tags)
+	require.Equal(t, created, *item.CreatedAt)
+	require.Equal(t, updated, *item.UpdatedAt)
+
+	// Unset fields are kept, zero and empty values clear
+	empty := ""
+	item, err = instance.Update(ctx, coredto.ToDoItem{
+		ItemID:      item.ItemID,
+		Owner:       &owner,
+		Description: &empty,
+		DueDate:     &time.Time{},
+		Tags:        []string{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "", *item.Description)
+	require.Nil(t, item.DueDate)
+	require.Equal(t, high, *item.Priority)
+	require.Equal(t, []string{}, item.Tags)
+
+	page, err := instance.GetList(ctx, owner, coredto.ToDoListQuery{})
+	require.NoError(t, err)
+	last := page.Items[len(page.Items)-1]
+	require.Equal(t, *item.Version, *last.Version)
+
+	// Tasks created bypassing the gateway have no timestamps
+	seeded, err := instance.GetByID(ctx, owner, 1)
+	require.NoError(t, err)
+	require.Equal(t, coredto.PriorityNormal, *seeded.Priority)
+	require.Nil(t, seeded.CreatedAt)
+
+	require.NoError(t, instance.Delete(ctx, coredto.ToDoItem{ItemID: item.ItemID, Owner: &owner}))
+	stored, err := instance.details.Get(ctx, testOwnerID, []uint64{*item.ItemID})
+	require.NoError(t, err)
+	require.Empty(t, stored)
+}
+
+func TestToDoProvider_Version(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+	owner := testOwner()
+
+	item, err := instance.GetByID(ctx, owner, 1)
+	require.NoError(t, err)
+
+	page, err := instance.GetList(ctx, owner, coredto.ToDoListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, *item.Version, *page.Items[0].Version)
+
+	stale := *item.Version
+	title := "Buy oat milk"
+	updated, err := instance.Update(ctx, coredto.ToDoItem{
+		ItemID:  item.ItemID,
+		Owner:   &owner,
+		Title:   &title,
+		Version: item.Version,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, stale, *updated.Version)
+
+	_, err = instance.Update(ctx, coredto.ToDoItem{
+		ItemID:  item.ItemID,
+		Owner:   &owner,
+		Title:   &title,
+		Version: &stale,
+	})
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = instance.Delete(ctx, coredto.ToDoItem{ItemID: item.ItemID, Owner: &owner, Version: &stale})
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = instance.Delete(ctx, coredto.ToDoItem{ItemID: item.ItemID, Owner: &owner, Version: updated.Version})
+	require.NoError(t, err)
+}
@@ -0,0 +1,211 @@
+package todoprovider
+
+import (
+	"cmp"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"todoapiservice/internal/services/coredto"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// pageCursor is the position after the last returned item: its sort key and ID.
+// Filter and sort fingerprint prevents reuse with other query parameters
+type pageCursor struct {
+	Query  string `json:"q"`
+	ID     uint64 `json:"id"`
+	Title  string `json:"t,omitempty"`
+	IsDone bool   `json:"d,omitempty"`
+}
+
+func queryFingerprint(query coredto.ToDoListQuery) string {
+	isDone := "any"
+	if query.IsDone != nil {
+		isDone = fmt.Sprint(*query.IsDone)
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf(
+		"%s|%t|%s|%s",
+		query.SortBy, query.Descending, isDone, query.TitleContains,
+	)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// cursorCipher encrypts page cursors, so they are opaque to clients and do not leak task titles.
+// The owner is authenticated too: a cursor is rejected for another user
+type cursorCipher struct {
+	aead cipher.AEAD
+}
+
+// newCursorCipher Returns cipher keyed by secret. Instances sharing the secret accept each other cursors
+func newCursorCipher(secret string) *cursorCipher {
+	key := sha256.Sum256([]byte(secret))
+
+	// AES accepts a 32 byte key and GCM the AES block, so neither call fails
+	block, _ := aes.NewCipher(key[:])
+	aead, _ := cipher.NewGCM(block)
+	return &cursorCipher{aead: aead}
+}
+
+func ownerData(ownerID uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, ownerID)
+}
+
+func (c *cursorCipher) encode(ownerID uint64, query coredto.ToDoListQuery, item coredto.ToDoItem) string {
+	cursor := pageCursor{
+		Query: queryFingerprint(query),
+		ID:    *item.ItemID,
+	}
+	switch query.SortBy {
+	case coredto.SortByTitle:
+		cursor.Title = *item.Title
+	case coredto.SortByIsDone:
+		cursor.IsDone = *item.IsDone
+	}
+
+	data, _ := json.Marshal(cursor)
+
+	nonce := make([]byte, c.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, data, ownerData(ownerID)))
+}
+
+func (c *cursorCipher) decode(ownerID uint64, query coredto.ToDoListQuery) (*pageCursor, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, ErrInvalidCursor
+	}
+
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	data, err := c.aead.Open(nil, nonce, sealed, ownerData(ownerID))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor pageCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.Query != queryFingerprint(query) {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+func compareItems(sortBy coredto.ToDoSortField, aID uint64, aTitle string, aDone bool, bID uint64, bTitle string, bDone bool) int {
+	var result int
+	switch sortBy {
+	case coredto.SortByTitle:
+		result = strings.Compare(strings.ToLower(aTitle), strings.ToLower(bTitle))
+	case coredto.SortByIsDone:
+		switch {
+		case aDone == bDone:
+		case !aDone:
+			result = -1
+		default:
+			result = 1
+		}
+	}
+
+	if result == 0 {
+		// ID keeps the order stable for equal sort keys
+		result = cmp.Compare(aID, bID)
+	}
+	return result
+}
+
+func matchesFilter(query coredto.ToDoListQuery, item coredto.ToDoItem) bool {
+	if query.IsDone != nil && *item.IsDone != *query.IsDone {
+		return false
+	}
+	if query.TitleContains != "" &&
+		!strings.Contains(strings.ToLower(*item.Title), strings.ToLower(query.TitleContains)) {
+		return false
+	}
+	return true
+}
+
+// applyListQuery Filters, sorts and cuts a page from the full owner task list.
+// Used because the backend has no native paging
+func (c *cursorCipher) applyListQuery(
+	ownerID uint64,
+	items []coredto.ToDoItem,
+	query coredto.ToDoListQuery,
+) (*coredto.ToDoListPage, error) {
+	if query.SortBy == "" {
+		query.SortBy = coredto.SortByID
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageLimit
+	}
+	query.Limit = min(query.Limit, MaxPageLimit)
+
+	filtered := make([]coredto.ToDoItem, 0, len(items))
+	for _, item := range items {
+		if matchesFilter(query, item) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	direction := 1
+	if query.Descending {
+		direction = -1
+	}
+
+	slices.SortFunc(filtered, func(a, b coredto.ToDoItem) int {
+		return direction * compareItems(
+			query.SortBy,
+			*a.ItemID, *a.Title, *a.IsDone,
+			*b.ItemID, *b.Title, *b.IsDone,
+		)
+	})
+
+	start := 0
+	if query.Cursor != "" {
+		cursor, err := c.decode(ownerID, query)
+		if err != nil {
+			return nil, err
+		}
+
+		// First item strictly after the cursor position
+		start = len(filtered)
+		for i, item := range filtered {
+			if direction*compareItems(
+				query.SortBy,
+				*item.ItemID, *item.Title, *item.IsDone,
+				cursor.ID, cursor.Title, cursor.IsDone,
+			) > 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := min(start+query.Limit, len(filtered))
+	page := &coredto.ToDoListPage{
+		Items: filtered[start:end],
+		Total: len(filtered),
+	}
+
+	if end < len(filtered) {
+		page.NextCursor = c.encode(ownerID, query, filtered[end-1])
+	}
+
+	return page, nil
+}
@@ -0,0 +1,69 @@
+package todoprovider
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"todoapiservice/internal/lib/textsearch"
+	"todoapiservice/internal/services/coredto"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+var ErrInvalidSearchQuery = errors.New("search query has no words")
+
+// Search Returns owner tasks matching all query words ranked by relevance.
+// Matching is done by the gateway over ListTasks results
+func (p *ToDoProvider) Search(
+	ctx context.Context,
+	owner coredto.User,
+	query coredto.ToDoSearchQuery,
+) ([]coredto.ToDoSearchResult, error) {
+	parsed := textsearch.ParseQuery(query.Text)
+	if parsed.Empty() {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	items, err := p.listAll(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]coredto.ToDoSearchResult, 0)
+	for _, item := range items {
+		match, ok := parsed.Match(*item.Title)
+		if !ok {
+			continue
+		}
+
+		highlights := make([]coredto.TextSpan, 0, len(match.Spans))
+		for _, span := range match.Spans {
+			highlights = append(highlights, coredto.TextSpan{Start: span.Start, End: span.End})
+		}
+
+		results = append(results, coredto.ToDoSearchResult{
+			Item:       item,
+			Score:      match.Score,
+			Highlights: highlights,
+		})
+	}
+
+	slices.SortFunc(results, func(a, b coredto.ToDoSearchResult) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(*a.Item.ItemID, *b.Item.ItemID)
+	})
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+
+	return results[:min(limit, len(results))], nil
+}
@@ -0,0 +1,81 @@
+package todoprovider
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/taskdetails"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDoProvider_Search(t *testing.T) {
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "Buy buttermilk", false)
+	pr.AddTask(testOwnerID, "Buy milk and bread", false)
+	pr.AddTask(testOwnerID, "Milk", true)
+	pr.AddTask(testOwnerID, "Make crème brûlée", false)
+	pr.AddTask(testOwnerID, "Milkshake", false)
+	pr.AddTask(2, "Milk for other user", false)
+
+	instance := New(slog.Default(), pr, taskdetails.NewMemoryStore(), configapplication.TasksConfig{})
+	ctx := context.Background()
+
+	testData := []struct {
+		name  string
+		query coredto.ToDoSearchQuery
+		ids   []uint64
+	}{
+		{
+			name:  "Ranked by match quality",
+			query: coredto.ToDoSearchQuery{Text: "MILK"},
+			ids:   []uint64{3, 2, 5, 1},
+		},
+		{
+			name:  "All words required",
+			query: coredto.ToDoSearchQuery{Text: "milk buy"},
+			ids:   []uint64{2, 1},
+		},
+		{
+			name:  "Diacritics ignored",
+			query: coredto.ToDoSearchQuery{Text: "creme brulee"},
+			ids:   []uint64{4},
+		},
+		{
+			name:  "Limit",
+			query: coredto.ToDoSearchQuery{Text: "milk", Limit: 2},
+			ids:   []uint64{3, 2},
+		},
+		{
+			name:  "Nothing found",
+			query: coredto.ToDoSearchQuery{Text: "tea"},
+			ids:   []uint64{},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			results, err := instance.Search(ctx, testOwner(), data.query)
+			require.NoError(t, err)
+
+			ids := make([]uint64, 0, len(results))
+			for _, result := range results {
+				ids = append(ids, *result.Item.ItemID)
+				require.NotEmpty(t, result.Highlights)
+			}
+			require.Equal(t, data.ids, ids)
+		})
+	}
+}
+
+func TestToDoProvider_Search_InvalidQuery(t *testing.T) {
+	instance := New(slog.Default(), mocks.New(false), taskdetails.NewMemoryStore(), configapplication.TasksConfig{})
+
+	results, err := instance.Search(context.Background(), testOwner(), coredto.ToDoSearchQuery{Text: " ?! "})
+
+	require.ErrorIs(t, err, ErrInvalidSearchQuery)
+	require.Nil(t, results)
+}
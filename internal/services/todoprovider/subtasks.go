@@ -0,0 +1,194 @@
+package todoprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"todoapiservice/internal/services/coredto"
+
+	todoprotobufv1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+)
+
+// MaxSubtasks limits subtasks of one task
+const MaxSubtasks = 100
+
+var (
+	ErrSubtaskNotFound = errors.New("subtask not found")
+	ErrTooManySubtasks = errors.New("too many subtasks")
+)
+
+func subtasksProgress(subtasks []coredto.ToDoSubtask) coredto.ToDoProgress {
+	progress := coredto.ToDoProgress{Total: len(subtasks)}
+	for _, subtask := range subtasks {
+		if *subtask.IsDone {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// GetSubtasks Returns subtasks of the owner task in creation order
+func (p *ToDoProvider) GetSubtasks(
+	ctx context.Context,
+	owner coredto.User,
+	parentID uint64,
+) ([]coredto.ToDoSubtask, error) {
+	log := p.logger.With("method", "GetSubtasks")
+
+	// Checks the task exists and belongs to the owner
+	if _, err := p.GetByID(ctx, owner, parentID); err != nil {
+		return nil, err
+	}
+
+	details, err := p.loadDetails(ctx, *owner.UserID, parentID)
+	if err != nil {
+		log.Error("get details error", slog.Any("err", err))
+		return nil, errors.Join(ErrToDoInternal, err)
+	}
+
+	result := make([]coredto.ToDoSubtask, 0, len(details.Subtasks))
+	for _, subtask := range details.Subtasks {
+		subtask.Owner = &owner
+		result = append(result, subtask)
+	}
+	return result, nil
+}
+
+// CreateSubtask Adds subtask to the parent task
+func (p *ToDoProvider) CreateSubtask(
+	ctx context.Context,
+	subtask coredto.ToDoSubtask,
+) (*coredto.ToDoSubtask, error) {
+	defer p.locks.lock(*subtask.ParentID)()
+
+	return p.changeSubtasks(ctx, "CreateSubtask", *subtask.Owner, *subtask.ParentID,
+		func(subtasks []coredto.ToDoSubtask) ([]coredto.ToDoSubtask, int, error) {
+			if len(subtasks) >= MaxSubtasks {
+				return nil, 0, fmt.Errorf("%w: task can have at most %d subtasks", ErrTooManySubtasks, MaxSubtasks)
+			}
+
+			subtaskID := uint64(1)
+			if len(subtasks) > 0 {
+				subtaskID = *subtasks[len(subtasks)-1].SubtaskID + 1
+			}
+
+			isDone := subtask.IsDone != nil && *subtask.IsDone
+			checklist := subtask.Checklist
+			if checklist == nil {
+				checklist = []coredto.ToDoChecklistItem{}
+			}
+
+			subtasks = append(subtasks, coredto.ToDoSubtask{
+				SubtaskID: &subtaskID,
+				ParentID:  subtask.ParentID,
+				Title:     subtask.Title,
+				IsDone:    &isDone,
+				Checklist: checklist,
+			})
+			return subtasks, len(subtasks) - 1, nil
+		})
+}
+
+// UpdateSubtask Applies subtask changes
+func (p *ToDoProvider) UpdateSubtask(
+	ctx context.Context,
+	changes coredto.ToDoSubtask,
+) (*coredto.ToDoSubtask, error) {
+	defer p.locks.lock(*changes.ParentID)()
+
+	return p.changeSubtasks(ctx, "UpdateSubtask", *changes.Owner, *changes.ParentID,
+		func(subtasks []coredto.ToDoSubtask) ([]coredto.ToDoSubtask, int, error) {
+			index := slices.IndexFunc(subtasks, func(subtask coredto.ToDoSubtask) bool {
+				return *subtask.SubtaskID == *changes.SubtaskID
+			})
+			if index < 0 {
+				return nil, 0, ErrSubtaskNotFound
+			}
+
+			if changes.Title != nil {
+				subtasks[index].Title = changes.Title
+			}
+			if changes.IsDone != nil {
+				subtasks[index].IsDone = changes.IsDone
+			}
+			if changes.Checklist != nil {
+				subtasks[index].Checklist = changes.Checklist
+			}
+			return subtasks, index, nil
+		})
+}
+
+// changeSubtasks Applies change to the parent task subtasks and returns the changed subtask.
+// With auto-completion the parent is marked done when all subtasks are done and reopened otherwise.
+// Must be called with the parent lock held
+func (p *ToDoProvider) changeSubtasks(
+	ctx context.Context,
+	method string,
+	owner coredto.User,
+	parentID uint64,
+	change func(subtasks []coredto.ToDoSubtask) ([]coredto.ToDoSubtask, int, error),
+) (*coredto.ToDoSubtask, error) {
+	log := p.logger.With("method", method)
+
+	parent, err := p.GetByID(ctx, owner, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := p.loadDetails(ctx, *owner.UserID, parentID)
+	if err != nil {
+		log.Error("get details error", slog.Any("err", err))
+		return nil, errors.Join(ErrToDoInternal, err)
+	}
+
+	subtasks, index, err := change(details.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+
+	parentChanged := false
+	if p.autoComplete {
+		progress := subtasksProgress(subtasks)
+		isDone := progress.Done == progress.Total
+
+		if *parent.IsDone != isDone {
+			// Backend is changed first: its failure leaves both stores untouched,
+			// a details store failure reverts the backend change below
+			_, err = p.client.UpdateTaskByID(ctx, &todoprotobufv1.UpdateTaskByIdRequest{
+				TaskId: parentID,
+				UserId: *owner.UserID,
+				IsDone: &isDone,
+			})
+			if err != nil {
+				log.Error("auto-complete error", slog.Any("err", err))
+				return nil, backendError(err)
+			}
+			parentChanged = true
+		}
+	}
+
+	details.Subtasks = subtasks
+	details.UpdatedAt = p.now().UTC()
+
+	if err = p.details.Put(ctx, *owner.UserID, parentID, details); err != nil {
+		log.Error("put details error", slog.Any("err", err))
+
+		if parentChanged {
+			_, revertErr := p.client.UpdateTaskByID(context.WithoutCancel(ctx), &todoprotobufv1.UpdateTaskByIdRequest{
+				TaskId: parentID,
+				UserId: *owner.UserID,
+				IsDone: parent.IsDone,
+			})
+			if revertErr != nil {
+				log.Error("auto-complete revert error", slog.Any("err", revertErr))
+			}
+		}
+		return nil, errors.Join(ErrToDoInternal, err)
+	}
+
+	subtask := subtasks[index]
+	subtask.Owner = &owner
+	return &subtask, nil
+}
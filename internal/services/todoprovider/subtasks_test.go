@@ -0,0 +1,166 @@
+package todoprovider
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"todoapiservice/internal/app/configapplication"
+	"todoapiservice/internal/app/grpcapplication/mocks"
+	"todoapiservice/internal/services/coredto"
+	"todoapiservice/internal/services/taskdetails"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSubtask(parentID uint64, title string) coredto.ToDoSubtask {
+	owner := testOwner()
+	return coredto.ToDoSubtask{ParentID: &parentID, Owner: &owner, Title: &title}
+}
+
+func TestToDoProvider_Subtasks(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+
+	first, err := instance.CreateSubtask(ctx, newSubtask(1, "Check fridge"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), *first.SubtaskID)
+	require.False(t, *first.IsDone)
+	require.Equal(t, []coredto.ToDoChecklistItem{}, first.Checklist)
+
+	second := newSubtask(1, "Go to shop")
+	second.Checklist = []coredto.ToDoChecklistItem{{Title: "Take bag"}}
+	created, err := instance.CreateSubtask(ctx, second)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), *created.SubtaskID)
+
+	done := true
+	changes := newSubtask(1, "Check fridge twice")
+	changes.SubtaskID = first.SubtaskID
+	changes.IsDone = &done
+	updated, err := instance.UpdateSubtask(ctx, changes)
+	require.NoError(t, err)
+	require.Equal(t, "Check fridge twice", *updated.Title)
+	require.True(t, *updated.IsDone)
+
+	subtasks, err := instance.GetSubtasks(ctx, testOwner(), 1)
+	require.NoError(t, err)
+	require.Len(t, subtasks, 2)
+	require.Equal(t, "Check fridge twice", *subtasks[0].Title)
+	require.Equal(t, []coredto.ToDoChecklistItem{{Title: "Take bag"}}, subtasks[1].Checklist)
+
+	// Without auto-completion the task state is kept
+	item, err := instance.GetByID(ctx, testOwner(), 1)
+	require.NoError(t, err)
+	require.False(t, *item.IsDone)
+	require.Equal(t, &coredto.ToDoProgress{Done: 1, Total: 2}, item.Progress)
+
+	other, err := instance.GetByID(ctx, testOwner(), 2)
+	require.NoError(t, err)
+	require.Nil(t, other.Progress)
+}
+
+func TestToDoProvider_Subtasks_Errors(t *testing.T) {
+	instance, _ := newSeededInstance(t)
+	ctx := context.Background()
+
+	// Task of another user
+	_, err := instance.CreateSubtask(ctx, newSubtask(6, "Steal"))
+	require.ErrorIs(t, err, ErrToDoNotFound)
+
+	_, err = instance.GetSubtasks(ctx, testOwner(), 6)
+	require.ErrorIs(t, err, ErrToDoNotFound)
+
+	missingID := uint64(42)
+	missing := newSubtask(1, "Missing")
+	missing.SubtaskID = &missingID
+	_, err = instance.UpdateSubtask(ctx, missing)
+	require.ErrorIs(t, err, ErrSubtaskNotFound)
+
+	for i := 0; i < MaxSubtasks; i++ {
+		_, err = instance.CreateSubtask(ctx, newSubtask(3, "Step"))
+		require.NoError(t, err)
+	}
+	_, err = instance.CreateSubtask(ctx, newSubtask(3, "One more"))
+	require.ErrorIs(t, err, ErrTooManySubtasks)
+}
+
+func TestToDoProvider_Subtasks_AutoComplete(t *testing.T) {
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "Buy milk", false)
+	instance := New(slog.Default(), pr, taskdetails.NewMemoryStore(), configapplication.TasksConfig{SubtasksAutoComplete: true})
+	ctx := context.Background()
+
+	isDone := func() bool {
+		item, err := instance.GetByID(ctx, testOwner(), 1)
+		require.NoError(t, err)
+		return *item.IsDone
+	}
+
+	first, err := instance.CreateSubtask(ctx, newSubtask(1, "Check fridge"))
+	require.NoError(t, err)
+	second, err := instance.CreateSubtask(ctx, newSubtask(1, "Go to shop"))
+	require.NoError(t, err)
+
+	setDone := func(subtask *coredto.ToDoSubtask, done bool) {
+		changes := *subtask
+		changes.Title = nil
+		changes.IsDone = &done
+		_, err := instance.UpdateSubtask(ctx, changes)
+		require.NoError(t, err)
+	}
+
+	setDone(first, true)
+	require.False(t, isDone())
+
+	setDone(second, true)
+	require.True(t, isDone())
+
+	// Reopened subtask reopens the task
+	setDone(first, false)
+	require.False(t, isDone())
+
+	setDone(first, true)
+	require.True(t, isDone())
+
+	// New open subtask reopens the task too
+	_, err = instance.CreateSubtask(ctx, newSubtask(1, "Pay"))
+	require.NoError(t, err)
+	require.False(t, isDone())
+}
+
+type failingPutStore struct {
+	*taskdetails.MemoryStore
+	fail bool
+}
+
+func (s *failingPutStore) Put(ctx context.Context, userID uint64, itemID uint64, details coredto.ToDoItemDetails) error {
+	if s.fail {
+		return errors.New("store is down")
+	}
+	return s.MemoryStore.Put(ctx, userID, itemID, details)
+}
+
+func TestToDoProvider_Subtasks_AutoCompleteRevert(t *testing.T) {
+	pr := mocks.New(false)
+	pr.AddTask(testOwnerID, "Buy milk", false)
+	store := &failingPutStore{MemoryStore: taskdetails.NewMemoryStore()}
+	instance := New(slog.Default(), pr, store, configapplication.TasksConfig{SubtasksAutoComplete: true})
+	ctx := context.Background()
+
+	subtask, err := instance.CreateSubtask(ctx, newSubtask(1, "Check fridge"))
+	require.NoError(t, err)
+
+	// Details failure reverts the parent auto-completion in the backend
+	store.fail = true
+	done := true
+	changes := *subtask
+	changes.IsDone = &done
+	_, err = instance.UpdateSubtask(ctx, changes)
+	require.ErrorIs(t, err, ErrToDoInternal)
+
+	item, err := instance.GetByID(ctx, testOwner(), 1)
+	require.NoError(t, err)
+	require.False(t, *item.IsDone)
+	require.Equal(t, &coredto.ToDoProgress{Total: 1}, item.Progress)
+}
@@ -0,0 +1,118 @@
+package todoprovider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+	"todoapiservice/internal/services/coredto"
+)
+
+var ErrVersionMismatch = errors.New("todo item version mismatch")
+
+const versionLockStripes = 64
+
+// versionLocks Serializes conditional changes of the same item within the gateway.
+// The backend has no compare-and-set, so changes made bypassing the gateway can still interleave
+type versionLocks struct {
+	stripes [versionLockStripes]sync.Mutex
+}
+
+func (l *versionLocks) lock(itemID uint64) func() {
+	mu := &l.stripes[itemID%versionLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// itemVersion Derives version from the item state
+func itemVersion(item *coredto.ToDoItem) string {
+	state, _ := json.Marshal(struct {
+		ItemID      uint64
+		Title       string
+		IsDone      bool
+		Description *string
+		DueDate     *time.Time
+		Priority    *coredto.ToDoPriority
+		Tags        []string
+		UpdatedAt   *time.Time
+		Progress    *coredto.ToDoProgress
+	}{
+		ItemID:      *item.ItemID,
+		Title:       *item.Title,
+		IsDone:      *item.IsDone,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+		Priority:    item.Priority,
+		Tags:        item.Tags,
+		UpdatedAt:   item.UpdatedAt,
+		Progress:    item.Progress,
+	})
+
+	sum := sha256.Sum256(state)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// newItem Builds item from the backend fields and the gateway stored details.
+// The version matches the item state
+func newItem(
+	owner coredto.User,
+	itemID uint64,
+	title string,
+	isDone bool,
+	details coredto.ToDoItemDetails,
+	hasDetails bool,
+) *coredto.ToDoItem {
+	item := &coredto.ToDoItem{
+		ItemID:      &itemID,
+		Title:       &title,
+		IsDone:      &isDone,
+		Owner:       &owner,
+		Description: &details.Description,
+		DueDate:     details.DueDate,
+		Priority:    &details.Priority,
+		Tags:        details.Tags,
+	}
+
+	if *item.Priority == "" {
+		*item.Priority = coredto.PriorityNormal
+	}
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+	if hasDetails {
+		item.CreatedAt = &details.CreatedAt
+		item.UpdatedAt = &details.UpdatedAt
+	}
+	if len(details.Subtasks) > 0 {
+		progress := subtasksProgress(details.Subtasks)
+		item.Progress = &progress
+	}
+
+	version := itemVersion(item)
+	item.Version = &version
+	return item
+}
+
+// mergeDetails Applies item field changes to details
+func mergeDetails(details coredto.ToDoItemDetails, changes coredto.ToDoItem) coredto.ToDoItemDetails {
+	if changes.Description != nil {
+		details.Description = *changes.Description
+	}
+	if changes.DueDate != nil {
+		if changes.DueDate.IsZero() {
+			details.DueDate = nil
+		} else {
+			dueDate := changes.DueDate.UTC()
+			details.DueDate = &dueDate
+		}
+	}
+	if changes.Priority != nil {
+		details.Priority = *changes.Priority
+	}
+	if changes.Tags != nil {
+		details.Tags = changes.Tags
+	}
+	return details
+}